@@ -23,26 +23,48 @@ const (
 // supportedDefaultOpts contains flags whose values can be
 // overridden through the `F2_DEFAULT_OPTS` environmental variable.
 var supportedDefaultOpts = []string{
+	flagBackup.Name,
+	flagBackupDir.Name,
 	flagClean.Name,
+	flagCSVOutput.Name,
 	flagExclude.Name,
 	flagExcludeDir.Name,
 	flagExec.Name,
 	flagExiftoolOpts.Name,
 	flagFixConflicts.Name,
 	flagFixConflictsPattern.Name,
+	flagFixEmpty.Name,
+	flagGit.Name,
 	flagHidden.Name,
+	flagHiddenDirs.Name,
+	flagHiddenFiles.Name,
+	flagHistoryLimit.Name,
+	flagHistoryMaxAge.Name,
 	flagIgnoreCase.Name,
 	flagIgnoreExt.Name,
+	flagInteractive.Name,
 	flagIncludeDir.Name,
 	flagJSON.Name,
 	flagNoColor.Name,
+	flagNoLock.Name,
+	flagNoPreserve.Name,
+	flagPCRE.Name,
+	flagPreserveOwner.Name,
 	flagQuiet.Name,
 	flagRecursive.Name,
 	flagSort.Name,
 	flagSortr.Name,
 	flagResetIndexPerDir.Name,
+	flagRetries.Name,
 	flagStringMode.Name,
+	flagSummary.Name,
+	flagThrottle.Name,
+	flagTrash.Name,
+	flagTwoPhase.Name,
 	flagVerbose.Name,
+	flagVerify.Name,
+	flagVerifyChecksum.Name,
+	flagWorkers.Name,
 }
 
 // isInputFromPipe detects if input is being piped to F2.
@@ -59,14 +81,21 @@ func isOutputToPipe() bool {
 }
 
 // handlePipeInput processes input from a pipe and appends it to os.Args.
+// The input may be newline-delimited or NUL-delimited (as produced by
+// `find -print0`).
 func handlePipeInput(reader io.Reader) error {
 	if !isInputFromPipe() {
 		return nil
 	}
 
 	scanner := bufio.NewScanner(bufio.NewReader(reader))
+	scanner.Split(osutil.ScanPaths())
 
 	for scanner.Scan() {
+		if scanner.Text() == "" {
+			continue
+		}
+
 		os.Args = append(os.Args, scanner.Text())
 	}
 
@@ -215,34 +244,105 @@ offers several options for fine-grained control over the renaming process.`,
 			flagFind,
 			flagReplace,
 			flagUndo,
+			flagAllowModified,
 			flagAllowOverwrites,
+			flagBackup,
+			flagBackupDir,
+			flagCheck,
+			flagCIFS,
 			flagClean,
+			flagCopy,
+			flagCSVOutput,
+			flagDebug,
+			flagDepth,
 			flagExclude,
 			flagExcludeDir,
 			flagExec,
+			flagExt,
+			flagFilterTime,
 			flagFixConflicts,
 			flagFixConflictsPattern,
+			flagFixEmpty,
+			flagFixExt,
+			flagFollowDirLinks,
+			flagFromFile,
+			flagFuzzy,
+			flagGit,
+			flagGlob,
+			flagGrep,
+			flagGrepLimit,
 			flagHidden,
+			flagHiddenDirs,
+			flagHiddenFiles,
+			flagHistory,
+			flagHistoryLimit,
+			flagHistoryMaxAge,
 			flagIncludeDir,
 			flagIgnoreCase,
 			flagIgnoreExt,
+			flagInteractive,
+			flagInvert,
 			flagJSON,
+			flagLink,
+			flagLongPaths,
+			flagMatchPath,
 			flagMaxDepth,
+			flagMaxSize,
+			flagMinSize,
+			flagNewerThan,
 			flagNoColor,
+			flagNoLock,
+			flagNoPreserve,
+			flagNormalize,
+			flagNumberSkip,
+			flagOlderThan,
+			flagOnError,
+			flagOneFileSystem,
 			flagOnlyDir,
+			flagOwnedBy,
+			flagPadNum,
 			flagPair,
 			flagPairOrder,
+			flagPCRE,
+			flagPreserveOwner,
+			flagPrint0,
+			flagPruneHistory,
 			flagQuiet,
 			flagRecursive,
+			flagRedo,
 			flagReplaceLimit,
+			flagReplaceNth,
+			flagRenumber,
+			flagReportConflicts,
+			flagReportDuplicates,
 			flagResetIndexPerDir,
+			flagRespectGitignore,
+			flagResumeIndex,
+			flagRetries,
 			flagSort,
 			flagSortr,
 			flagSortPerDir,
 			flagSortVar,
+			flagStep,
 			flagStringMode,
+			flagSummary,
+			flagSymlinks,
 			flagTargetDir,
+			flagTargetOS,
+			flagThrottle,
+			flagTrash,
+			flagTraversal,
+			flagTUI,
+			flagTwoPhase,
+			flagUndoID,
+			flagUndoLast,
 			flagVerbose,
+			flagVerify,
+			flagVerifyChecksum,
+			flagWatch,
+			flagWhere,
+			flagWorkers,
+			flagWritableOnly,
 		},
 		UseShortOptionHandling:    true,
 		DisableSliceFlagSeparator: true,
@@ -60,6 +60,19 @@ func TestPipingInputFromFind(t *testing.T) {
 				"testdata/d.txt",
 			},
 		},
+		{
+			name: "find txt files with NUL-delimited output",
+			findArgs: []string{
+				"testdata",
+				"-name",
+				"[ab].txt",
+				"-print0",
+			},
+			expected: []string{
+				"testdata/a.txt",
+				"testdata/b.txt",
+			},
+		},
 		{
 			name:     "find a.txt file",
 			findArgs: []string{"testdata", "-name", "a.txt"},
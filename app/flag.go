@@ -6,7 +6,10 @@ var (
 	flagCSV = &cli.StringFlag{
 		Name: "csv",
 		Usage: `
-		Load a CSV file, and rename according to its contents.`,
+		Load a CSV file, and rename according to its contents. The first column
+		contains the source paths, and the second column contains the targets.
+		A JSON file containing an array of {"source": "...", "target": "..."}
+		objects is also accepted if its extension is .json.`,
 		DefaultText: "<path/to/csv/file>",
 		TakesFile:   true,
 	}
@@ -19,7 +22,8 @@ var (
     It accepts the syntax defined by the RE2 standard and defaults to .* 
 		if omitted which matches the entire file/directory name.
 
-		When -s/--string-mode is used, this pattern is treated as a literal string.`,
+		When -s/--string-mode is used, this pattern is treated as a literal string.
+		Use --pcre for lookarounds and backreferences.`,
 		DefaultText: "<pattern>",
 	}
 
@@ -37,21 +41,149 @@ var (
 		Name:    "undo",
 		Aliases: []string{"u"},
 		Usage: `
-		Undo the last renaming operation performed in the current working directory.`,
+		Undo the last renaming operation performed in the current working
+		directory. Every renaming operation is recorded in the history
+		directory ($XDG_STATE_HOME/f2/history, which defaults to
+		~/.local/state/f2/history), so repeating this option undoes the
+		earlier operations in turn. Nothing is reverted if a file now exists at
+		an original name unless -F/--fix-conflicts is used to restore the file
+		to a different name.`,
+	}
+
+	flagAllowModified = &cli.BoolFlag{
+		Name: "allow-modified",
+		Usage: `
+		Renames files whose size or modification time changed after they were
+		matched. By default, such files (for example, files that are still being
+		downloaded) are skipped and reported. When undoing, this also restores
+		the files whose contents changed after they were renamed.`,
 	}
 
 	flagAllowOverwrites = &cli.BoolFlag{
 		Name: "allow-overwrites",
 		Usage: `
-		Allows the renaming operation to overwrite existing files.
+		Allows the renaming operation to overwrite existing files. The
+		overwritten files are listed in the backup file, and a warning is
+		printed when the operation is undone since they cannot be restored.
+		Use --trash or --backup to keep the overwritten files instead.
 		Caution: Using this option can lead to unrecoverable data loss.`,
 	}
 
+	flagBackup = &cli.StringFlag{
+		Name: "backup",
+		Usage: `
+		Renames the files that are overwritten with --allow-overwrites to a backup
+		name instead of replacing them, like the --backup option of GNU mv. The
+		value is the suffix that is added to the name of the backup, or one of
+		the following methods: 'simple' adds the default '~' suffix, 'numbered'
+		names the backups <name>.~1~, <name>.~2~, and so on, and 'existing'
+		makes numbered backups of the files that have them already and simple
+		backups of the others. Simple backups replace an earlier backup with the
+		same name. The backups are renamed back when the operation is undone.
+		Use 'none' to turn off backups that are set through F2_DEFAULT_OPTS.
+
+		Example:
+			$ f2 -f 'draft' -r 'final' --allow-overwrites --backup .bak -x
+			$ f2 -f 'draft' -r 'final' --allow-overwrites --backup numbered -x`,
+		DefaultText: "<suffix|simple|numbered|existing|none>",
+	}
+
+	flagBackupDir = &cli.StringFlag{
+		Name: "backup-dir",
+		Usage: `
+		Puts the backups of the files that are overwritten in the specified
+		directory instead of next to the files, creating it if necessary.
+		Implies --backup simple unless another method or suffix is set.
+
+		Example:
+			$ f2 -f 'draft' -r 'final' --allow-overwrites --backup-dir old -x`,
+		DefaultText: "<path/to/dir>",
+	}
+
+	flagCheck = &cli.BoolFlag{
+		Name: "check",
+		Usage: `
+		Runs the search, replacement, and conflict detection without printing
+		the changes or renaming any files, and reports the outcome through the
+		exit code: 0 if no files would be renamed (including when nothing
+		matches), 3 if conflicts are detected, and 5 if any files would be
+		renamed. Combine with --json to list the pending changes. This is
+		intended for enforcing naming conventions in CI.
+
+		Example:
+			$ f2 -f '\s' -r '_' -R --check`,
+	}
+
+	flagCIFS = &cli.BoolFlag{
+		Name: "ci-fs",
+		Usage: `
+		Treats target paths that differ only in case (such as 'Photo.jpg' and
+		'photo.jpg') as the same path when detecting conflicts. This is the
+		default on Windows and macOS whose filesystems are case insensitive, so
+		use it when renaming files on a case insensitive drive from Linux.`,
+	}
+
 	flagClean = &cli.BoolFlag{
 		Name:    "clean",
-		Aliases: []string{"c"},
+		Aliases: []string{"c", "prune-empty"},
+		Usage: `
+		Clean empty directories that were traversed in a renaming operation.
+		The parents of these directories are also removed if they are left
+		empty, up to the searched directories. The directories that will be
+		removed are listed in a dry run, and they are recreated on undo.`,
+	}
+
+	flagCopy = &cli.BoolFlag{
+		Name: "copy",
+		Usage: `
+		Copies the matched files to their new names instead of renaming them so
+		that the originals are left in place. The copies keep the permissions
+		and timestamps of the originals (see --no-preserve and
+		--preserve-owner). Undoing the operation deletes the copies that were
+		not modified since. Directories are not supported.
+
+		Example:
+			$ f2 -f '.*' -r '{mtime.YYYY}/{f}{ext}' --target-dir export --copy`,
+	}
+
+	flagCSVOutput = &cli.BoolFlag{
+		Name: "csv-output",
+		Usage: `
+		Prints the table of changes in CSV format so that it can be reviewed in a
+		spreadsheet before the changes are committed. The columns are the same as
+		in the table. Cannot be used with --json. Can be set through
+		F2_DEFAULT_OPTS.
+
+		Example:
+			$ f2 -f 'IMG' -r 'Photo' --csv-output > changes.csv`,
+	}
+
+	flagDebug = &cli.BoolFlag{
+		Name: "debug",
 		Usage: `
-		Clean empty directories that were traversed in a renaming operation.`,
+		Logs each step of the operation to the standard error with a timestamp,
+		including the directories that are searched, why each file was skipped or
+		matched, how the variables in its new name were resolved, and the outcome
+		of renaming it. Useful to find out why a file was or was not renamed.
+
+		Example:
+			$ f2 -f 'IMG' -r 'Photo' -R --debug`,
+	}
+
+	flagDepth = &cli.UintFlag{
+		Name: "depth",
+		Usage: `
+		Matches only the files and directories at the specified depth below
+		the searched directories, where the entries directly inside them are
+		at depth 1. Directories above this depth are searched but not matched,
+		and those below it are skipped. This implies --recursive. Set to 0
+		(default) to match entries at any depth.
+
+		Example:
+			$ f2 -f '^' -r '{p} - ' --depth 2 -D ~/Music (prefixes album
+			folders with the artist name)`,
+		Value:       0,
+		DefaultText: "<integer>",
 	}
 
 	flagExclude = &cli.StringSliceFlag{
@@ -59,7 +191,8 @@ var (
 		Aliases: []string{"E"},
 		Usage: `
 		Excludes files and directories that match the provided regular expression.
-		This flag can be repeated to specify multiple exclude patterns.
+		This flag can be repeated to specify multiple exclude patterns. The
+		patterns are case insensitive when -i/--ignore-case is set.
 
 		Example: 
 			-E 'json' -E 'yml' (filters out JSON and YAML files)
@@ -67,7 +200,9 @@ var (
 
 		Note: 
 			This does not prevent recursing into matching directories (use
-			--exclude-dir instead).`,
+			--exclude-dir instead). To exclude paths permanently, add
+			gitignore-style patterns to a .f2ignore file in the searched
+			directory or in the f2 directory within your config directory.`,
 
 		DefaultText: "<pattern>",
 	}
@@ -87,6 +222,18 @@ var (
 		Executes the renaming operation and applies the changes to the filesystem.`,
 	}
 
+	flagExt = &cli.StringSliceFlag{
+		Name: "ext",
+		Usage: `
+		Matches only files with one of the provided extensions (case insensitive).
+		Extensions are separated by commas, and this flag can be repeated.
+		Directories are not filtered.
+
+		Example:
+			$ f2 -f 'IMG' -r 'photo' --ext jpg,png,heic`,
+		DefaultText: "<extensions>",
+	}
+
 	flagExiftoolOpts = &cli.StringFlag{
 		Name: "exiftool-opts",
 		Usage: `
@@ -104,6 +251,14 @@ var (
 			$ f2 -r '{xt.GPSDateTime}' --exiftool-opts '--dateFormat %Y-%m-%d'`,
 	}
 
+	flagFilterTime = &cli.StringFlag{
+		Name: "filter-time",
+		Usage: `
+		Specifies the file time attribute used by --newer-than and --older-than.
+		Accepts: mtime (default), btime, atime, or ctime.`,
+		DefaultText: "<time>",
+	}
+
 	flagFixConflicts = &cli.BoolFlag{
 		Name:    "fix-conflicts",
 		Aliases: []string{"F"},
@@ -112,28 +267,204 @@ var (
 	}
 
 	flagFixConflictsPattern = &cli.StringFlag{
-		Name: "fix-conflicts-pattern",
+		Name:    "fix-conflicts-pattern",
+		Aliases: []string{"conflict-suffix"},
 		Usage: `
 		Specifies a custom pattern for renaming files when conflicts occur.
 		The pattern should be a valid Go format string containing a single '%d'
-		placeholder for the conflict index.
+		placeholder for the conflict index. It is added to the end of the file
+		name before the extension.
 
 		Example: '_%02d'  (generates _01, _02, etc.)
+		Example: ' (copy %d)'  (generates ' (copy 1)', ' (copy 2)', etc.)
 
 		If not specified, the default pattern '(%d)' is used.`,
 	}
 
+	flagFixEmpty = &cli.StringFlag{
+		Name: "fix-empty",
+		Usage: `
+		Determines how -F/--fix-conflicts fixes files whose new name is empty.
+		The default 'keep' leaves the original name unchanged, while 'skip'
+		leaves the file out of the renaming operation with a warning. Any other
+		value is a template for the new name containing a single '%d'
+		placeholder for an index that starts at 1. The original extension is
+		kept for files.
+
+		Example:
+			$ f2 -f '.*' -r '' -F --fix-empty 'unnamed_%03d'`,
+		Value:       "keep",
+		DefaultText: "<keep|skip|template>",
+	}
+
+	flagFixExt = &cli.BoolFlag{
+		Name: "fix-ext",
+		Usage: `
+		Corrects the extensions of files whose contents do not match their
+		current extension (such as a JPEG image saved with a .png extension).
+		The file type is detected from the signature at the start of each file,
+		and files whose type cannot be detected are left unchanged. When used
+		without -f or -r, all the matched files are checked.
+
+		Example:
+			$ f2 --fix-ext
+			$ f2 -f 'IMG' -r 'photo' --fix-ext`,
+	}
+
+	flagFollowDirLinks = &cli.BoolFlag{
+		Name: "follow-dir-links",
+		Usage: `
+		Descends into symbolic links to directories when searching for matches
+		recursively. The contents of a linked directory are reported under the
+		path of the link. Each directory is visited at most once so that links
+		which point back to a parent directory do not cause infinite loops.
+
+		Example:
+			$ f2 -f 'jpeg' -r 'jpg' -R --follow-dir-links`,
+	}
+
+	flagFromFile = &cli.StringFlag{
+		Name: "from-file",
+		Usage: `
+		Reads the files and directories to operate on from the specified file.
+		The paths may be separated by newlines or NUL characters, and they are
+		added to any paths provided as arguments.
+
+		Example:
+			$ f2 -f 'jpeg' -r 'jpg' --from-file selection.txt`,
+		DefaultText: "<path>",
+	}
+
+	flagFuzzy = &cli.UintFlag{
+		Name: "fuzzy",
+		Usage: `
+		Matches file names containing a string that is within the specified
+		edit distance of the find string (specified by -f/--find). The edit
+		distance is the number of single character insertions, deletions, or
+		substitutions needed to turn one string into the other. The find string
+		is treated as a literal string and the closest match in each file name
+		is replaced.
+
+		Example:
+			$ f2 -f 'received' -r 'received' --fuzzy 2 (matches 'recieved' and 'receved')`,
+		Value:       0,
+		DefaultText: "<integer>",
+	}
+
+	flagGit = &cli.BoolFlag{
+		Name: "git",
+		Usage: `
+		Renames the files that are tracked in a git repository with 'git mv' so
+		that the renames are staged and the history follows the files. Files that
+		are not tracked, or whose new path is outside their repository, are
+		renamed as usual. Files are renamed one at a time in this mode
+		regardless of --workers. Can be set through F2_DEFAULT_OPTS.
+
+		Example:
+			$ f2 -f 'Test' -r 'Spec' -R --git -x`,
+	}
+
+	flagGlob = &cli.BoolFlag{
+		Name: "glob",
+		Usage: `
+		Treats the search pattern (specified by -f/--find) as a shell-style glob
+		instead of a regular expression. The pattern must match the entire file
+		name. Use * to match any sequence of characters, ? to match a single
+		character, and [...] to match a character class. Each wildcard is
+		captured so that it can be referenced in the replacement.
+
+		Example:
+			$ f2 -f 'IMG_*.jpeg' -r 'photo_$1.jpg' --glob`,
+	}
+
+	flagGrep = &cli.StringFlag{
+		Name: "grep",
+		Usage: `
+		Matches only the files whose contents match the provided regular
+		expression. Only the beginning of each file is searched (see
+		--grep-limit), and binary files and directories are never matched. The
+		--ignore-case flag also applies to this pattern.
+
+		Example:
+			$ f2 -f '^' -r 'acme_' --grep 'ACME Corporation' --ext pdf,txt`,
+		DefaultText: "<pattern>",
+	}
+
+	flagGrepLimit = &cli.StringFlag{
+		Name: "grep-limit",
+		Usage: `
+		Sets how much of each file is searched with --grep. The size may use
+		units such as KB, MB, KiB, or MiB. Use 0 to search entire files.
+
+		Example:
+			$ f2 -f '^' -r 'acme_' --grep 'ACME' --grep-limit 1MiB`,
+		Value:       "64KiB",
+		DefaultText: "<size>",
+	}
+
 	flagHidden = &cli.BoolFlag{
 		Name:    "hidden",
 		Aliases: []string{"H"},
 		Usage: `
 		Includes hidden files and directories in the search and renaming process.
 
-		On Linux and macOS, hidden files are those that start with a dot character.
-		On Windows, only files with the 'hidden' attribute are considered hidden.
+		Hidden files are those that start with a dot character. On Windows, files
+		with the 'hidden' attribute are also considered hidden.
 
 		To match hidden directories as well, combine this with the -d/--include-dir
-		flag.`,
+		flag. Use --hidden-dirs or --hidden-files to include only one of the two.`,
+	}
+
+	flagHiddenDirs = &cli.BoolFlag{
+		Name: "hidden-dirs",
+		Usage: `
+		Includes hidden directories in the search while hidden files remain
+		excluded. This allows recursing into directories such as '.config'
+		without matching dot files.
+
+		Example:
+			$ f2 -f 'jpeg' -r 'jpg' -R --hidden-dirs`,
+	}
+
+	flagHiddenFiles = &cli.BoolFlag{
+		Name: "hidden-files",
+		Usage: `
+		Includes hidden files in the search while hidden directories remain
+		excluded, so they are neither matched nor descended into when recursing.
+
+		Example:
+			$ f2 -f 'jpeg' -r 'jpg' -R --hidden-files`,
+	}
+
+	flagHistory = &cli.BoolFlag{
+		Name: "history",
+		Usage: `
+		Lists the renaming operations in the history of every directory from the
+		most recent one, including the date, directory, number of renamed files,
+		and the find and replacement patterns that were used. Use the number or
+		ID of an operation with --undo-id to undo it. Combine with --json to get
+		the operations in a machine-readable format.`,
+	}
+
+	flagHistoryLimit = &cli.UintFlag{
+		Name: "history-limit",
+		Usage: `
+		Sets the number of renaming operations that are kept in the history. The
+		oldest operations are pruned after each renaming operation once the limit
+		is exceeded. Set to 0 for no limit. Defaults to 100 and can be set through
+		F2_DEFAULT_OPTS.`,
+		Value:       100,
+		DefaultText: "<integer>",
+	}
+
+	flagHistoryMaxAge = &cli.StringFlag{
+		Name: "history-max-age",
+		Usage: `
+		Prunes renaming operations older than the specified duration (such as
+		36h, 30d, or 4w) or date from the history after each renaming operation.
+		Operations are kept regardless of their age by default. This can be set
+		through F2_DEFAULT_OPTS.`,
+		DefaultText: "<date|duration>",
 	}
 
 	flagIncludeDir = &cli.BoolFlag{
@@ -158,11 +489,73 @@ var (
 		Ignores the file extension when searching for matches.`,
 	}
 
+	flagInteractive = &cli.BoolFlag{
+		Name: "interactive",
+		Usage: `
+		Asks for confirmation before each file is renamed. Answer 'y' to rename
+		the file, 'n' to skip it, 'a' to rename it along with all the remaining
+		files without asking again, or 'q' to skip all the remaining files. The
+		file is skipped if no answer is given. The files are renamed one at a
+		time regardless of --workers.
+
+		Example:
+			$ f2 -f 'draft' -r 'final' -x --interactive`,
+	}
+
+	flagInvert = &cli.BoolFlag{
+		Name: "invert",
+		Usage: `
+		Matches the files that do not match the first find pattern. The
+		replacement is applied to the entire file name of each match.
+
+		Example:
+			$ f2 -f '^IMG_' -r 'IMG_{f}{ext}' --invert (prefixes all files that
+			do not start with IMG_)`,
+	}
+
 	flagJSON = &cli.BoolFlag{
 		Name: "json",
 		Usage: `
 		Produces JSON output, except for error messages which are sent to the
-		standard error.`,
+		standard error. With -x/--exec, the outcome of each change is printed
+		after renaming, including the error for each file that could not be
+		renamed.`,
+	}
+
+	flagLink = &cli.StringFlag{
+		Name: "link",
+		Usage: `
+		Creates links to the matched files at their new names instead of renaming
+		them so that the same files can be arranged in another tree. Use 'sym'
+		for symbolic links, which point to the files relative to the links, or
+		'hard' for hard links, which cannot be created for directories. Undoing
+		the operation deletes the links that still refer to the files.
+
+		Example:
+			$ f2 -f '.*' -r '{mtime.YYYY}/{f}{ext}' --target-dir by-year --link sym`,
+		DefaultText: "<sym|hard>",
+	}
+
+	flagLongPaths = &cli.BoolFlag{
+		Name: "long-paths",
+		Usage: `
+		Allows target paths longer than the Windows limit of 260 characters (up
+		to 32,767 characters). Such paths are renamed using the \\?\ prefix, but
+		some programs may be unable to open them. Without this option, paths
+		over the limit are reported as conflicts and -F/--fix-conflicts
+		truncates the file name to fit.`,
+	}
+
+	flagMatchPath = &cli.BoolFlag{
+		Name: "match-path",
+		Usage: `
+		Matches the search pattern against the path of each file relative to the
+		search directory instead of the file name alone. The replacement is also
+		applied to the relative path, so files can be renamed based on their
+		parent directories. Forward slashes are used as path separators.
+
+		Example:
+			$ f2 -f '^2023/(.*)\.jpg$' -r '2023/holiday_$1.jpg' -R --match-path`,
 	}
 
 	flagMaxDepth = &cli.UintFlag{
@@ -174,10 +567,139 @@ var (
 		DefaultText: "<integer>",
 	}
 
+	flagMaxSize = &cli.StringFlag{
+		Name: "max-size",
+		Usage: `
+		Matches only files that are not larger than the specified size. The size
+		may include a unit such as KB, MB, GB, or TB (powers of 1000), or KiB,
+		MiB, GiB, or TiB (powers of 1024). Directories are not filtered.
+
+		Example:
+			$ f2 -f 'IMG' -r 'thumb' --max-size 500KB`,
+		DefaultText: "<size>",
+	}
+
+	flagMinSize = &cli.StringFlag{
+		Name: "min-size",
+		Usage: `
+		Matches only files that are at least the specified size. It accepts the
+		same units as --max-size.
+
+		Example:
+			$ f2 -f '.*' -r '{f}_large{ext}' --min-size 100MB`,
+		DefaultText: "<size>",
+	}
+
+	flagNewerThan = &cli.StringFlag{
+		Name: "newer-than",
+		Usage: `
+		Matches only files and directories modified after the specified date or
+		duration. A duration (such as 36h, 7d, or 2w) is relative to the current
+		time. Use --filter-time to compare against another file time attribute.
+
+		Example:
+			$ f2 -f 'IMG' -r 'import' --newer-than 7d
+			$ f2 -f 'IMG' -r 'import' --newer-than 2024-01-15`,
+		DefaultText: "<date|duration>",
+	}
+
 	flagNoColor = &cli.BoolFlag{
 		Name: "no-color",
 		Usage: `
-		Disables colored output.`,
+		Disables colored output. Colors are also disabled when the NO_COLOR or
+		F2_NO_COLOR environment variable is set, when TERM is 'dumb', or when the
+		output is redirected to a file or another program.`,
+	}
+
+	flagNoLock = &cli.BoolFlag{
+		Name: "no-lock",
+		Usage: `
+		Skips the lock that prevents two renaming operations from running in the
+		same directories at the same time. By default, an operation that is
+		executed while another one is in progress in one of the searched
+		directories fails instead of renaming files that the other one may also
+		be renaming. In --watch mode, the lock is taken for each batch of new
+		files.`,
+	}
+
+	flagNoPreserve = &cli.BoolFlag{
+		Name: "no-preserve",
+		Usage: `
+		Prevents the access and modification times of the files from being kept
+		when they are copied (--copy) or moved to another filesystem, so that the
+		copies get the current time instead. The permissions of the files are
+		always kept. Can be set through F2_DEFAULT_OPTS.`,
+	}
+
+	flagNormalize = &cli.StringFlag{
+		Name: "normalize",
+		Usage: `
+		Converts the target names to the provided Unicode normalization form
+		('nfc', 'nfd', 'nfkc', or 'nfkd') before checking for conflicts. File
+		names created on macOS are often in NFD while typed text is usually in
+		NFC, so names that look the same may be stored differently. Targets
+		that are identical after normalization are always reported as
+		conflicts.
+
+		Example:
+			$ f2 -f '.*' -r '${0}' -R --normalize nfc`,
+		DefaultText: "<form>",
+	}
+
+	flagNumberSkip = &cli.StringFlag{
+		Name: "number-skip",
+		Usage: `
+		Skips the provided numbers and number ranges when indexing with variables
+		such as {%03d}. Values are separated by semicolons. This is ignored for
+		index variables that already specify their own skip list. Use 'existing'
+		to also skip the numbers used by the files that are already in the
+		target directory. The numbers appended to fix conflicts (-F) skip the
+		provided values as well.
+
+		Example:
+			$ f2 -r '{%03d}' --number-skip '13;20-29'
+			$ f2 -r 'IMG_{%03d}' --number-skip existing`,
+		DefaultText: "<numbers>",
+	}
+
+	flagOlderThan = &cli.StringFlag{
+		Name: "older-than",
+		Usage: `
+		Matches only files and directories modified before the specified date or
+		duration. It accepts the same values as --newer-than.`,
+		DefaultText: "<date|duration>",
+	}
+
+	flagOnError = &cli.StringFlag{
+		Name: "on-error",
+		Usage: `
+		Determines what happens when a file cannot be renamed, such as when its
+		source no longer exists at the time of renaming. The default 'continue'
+		renames the remaining files and reports a summary of the ones that
+		failed. Only the files that were renamed are recorded for undoing the
+		operation. The 'abort' option stops at the first failure and leaves
+		the remaining files untouched. The 'rollback' option also stops at the
+		first failure, but restores the files that were already renamed to
+		their original names so that the tree is left as it was. Files that
+		were overwritten cannot be restored.
+
+		Example:
+			$ f2 -f 'jpeg' -r 'jpg' -x --on-error abort
+			$ f2 -f 'jpeg' -r 'jpg' -x --on-error rollback`,
+		Value:       "continue",
+		DefaultText: "<continue|abort|rollback>",
+	}
+
+	flagOneFileSystem = &cli.BoolFlag{
+		Name: "one-file-system",
+		Usage: `
+		Prevents the search from crossing into directories that are on a
+		different filesystem from the searched directory, such as network
+		mounts or external drives. Mount points are skipped along with their
+		contents. This option is not supported on Windows.
+
+		Example:
+			$ f2 -f 'jpeg' -r 'jpg' -R --one-file-system ~`,
 	}
 
 	flagOnlyDir = &cli.BoolFlag{
@@ -187,13 +709,41 @@ var (
 		Renames only directories, not files (implies -d/--include-dir).`,
 	}
 
+	flagOwnedBy = &cli.StringFlag{
+		Name: "owned-by",
+		Usage: `
+		Matches only the files and directories that are owned by the specified
+		user. The user may be provided as a user name or a numeric user ID.
+		This option is not supported on Windows.
+
+		Example:
+			$ f2 -f 'jpeg' -r 'jpg' -R --owned-by "$USER"`,
+		DefaultText: "<user>",
+	}
+
+	flagPadNum = &cli.UintFlag{
+		Name: "pad-num",
+		Usage: `
+		Pads every number already present in the file name with leading zeros
+		to the specified width. Numbers in the file extension are left as is.
+		If no find or replacement pattern is provided, all files containing
+		numbers are matched.
+
+		Example:
+			$ f2 --pad-num 2 (renames track1.mp3 to track01.mp3)`,
+		Value:       0,
+		DefaultText: "<integer>",
+	}
+
 	flagPair = &cli.BoolFlag{
 		Name:    "pair",
 		Aliases: []string{"p"},
 		Usage: `
 		Enable pair renaming to rename files with the same name (but different 
 		extensions) in the same directory to the same new name. In pair mode,
-		file extensions are ignored.
+		file extensions are ignored. Sidecar files with a double extension (such
+		as IMG_1.CR2.xmp or movie.en.srt) are renamed with the file they
+		accompany.
 
 		Example:
 			Before: DSC08533.ARW DSC08533.JPG DSC08534.ARW DSC08534.JPG
@@ -215,13 +765,64 @@ var (
 		  --pair-order 'xmp,arw' # rename xmp files before arw`,
 	}
 
+	flagPCRE = &cli.BoolFlag{
+		Name: "pcre",
+		Usage: `
+		Matches the find pattern with a backtracking engine that is compatible
+		with Perl instead of Go's RE2 engine, so that lookarounds such as (?=...)
+		and (?<!...) and backreferences such as \1 can be used. Matching is slower
+		and a name that takes longer than a second to match is treated as not
+		matching. Can be set through F2_DEFAULT_OPTS.
+
+		Example:
+			$ f2 -f '(?<=IMG_)\d+' -r '{%03d}' --pcre`,
+	}
+
+	flagPreserveOwner = &cli.BoolFlag{
+		Name: "preserve-owner",
+		Usage: `
+		Keeps the owner and group of the files when they are copied (--copy) or
+		moved to another filesystem. Changing the owner usually requires elevated
+		privileges, so the copies keep the current user as their owner when this
+		is not permitted. This option has no effect on Windows. Can be set
+		through F2_DEFAULT_OPTS.
+
+		Example:
+			$ sudo f2 -f '.*' -r 'backup/{f}{ext}' --copy --preserve-owner -x`,
+	}
+
+	flagPrint0 = &cli.BoolFlag{
+		Name: "print0",
+		Usage: `
+		Prints the new path of each renamed file to the standard output followed
+		by a NUL character so that the output can be passed to 'xargs -0' even
+		if the names contain spaces or newlines. In a dry run, the original and
+		the new path of each file that would be renamed are printed instead of
+		the table, each followed by a NUL character.
+
+		Example:
+			$ f2 -f 'jpeg' -r 'jpg' -x --print0 | xargs -0 exiftool -all=`,
+	}
+
+	flagPruneHistory = &cli.BoolFlag{
+		Name: "prune-history",
+		Usage: `
+		Removes the renaming operations that exceed --history-limit or
+		--history-max-age from the history without performing a renaming
+		operation.
+
+		Example:
+			$ f2 --prune-history --history-limit 10`,
+	}
+
 	flagQuiet = &cli.BoolFlag{
 		Name:    "quiet",
 		Aliases: []string{"q"},
 		Usage: `
-		Don't print anything to stdout. If no matches are found, f2 will exit with
-	  an error code instead of the normal success code without this flag.
-		Errors will continue to be written to stderr.`,
+		Don't print anything to stdout, including the table of changes, unless
+		--json or --csv-output is set. Errors will continue to be written to
+		stderr, and the exit code still reports the outcome (such as 2 when no
+		matches are found or 3 when there are conflicts).`,
 	}
 
 	flagRecursive = &cli.BoolFlag{
@@ -231,6 +832,14 @@ var (
 		Recursively traverses directories when searching for matches.`,
 	}
 
+	flagRedo = &cli.BoolFlag{
+		Name: "redo",
+		Usage: `
+		Redo the last renaming operation that was undone in the current working
+		directory. Undone operations can no longer be redone once a new
+		renaming operation is performed in the directory.`,
+	}
+
 	flagReplaceLimit = &cli.IntFlag{
 		Name:    "replace-limit",
 		Aliases: []string{"l"},
@@ -242,6 +851,60 @@ var (
 		DefaultText: "<integer>",
 	}
 
+	flagRenumber = &cli.BoolFlag{
+		Name: "renumber",
+		Usage: `
+		Renumbers the last number in each matched file name so that the sequence
+		in each directory becomes contiguous while preserving the relative order
+		of the files. The sequence starts from the lowest existing number. If no
+		find or replacement pattern is provided, all files containing numbers
+		are matched.
+
+		Example:
+			Before: img_1.jpg img_2.jpg img_4.jpg img_7.jpg
+
+			$ f2 --renumber -x
+
+			After: img_1.jpg img_2.jpg img_3.jpg img_4.jpg`,
+	}
+
+	flagReplaceNth = &cli.IntFlag{
+		Name: "replace-nth",
+		Usage: `
+		Replaces only the nth match in each matched file. Negative values count
+		from the end of the filename (-1 replaces the last match). This cannot
+		be combined with -l/--replace-limit.
+
+		Example:
+			$ f2 -f '_' -r '-' --replace-nth 2 (renames a_b_c.txt to a_b-c.txt)`,
+		Value:       0,
+		DefaultText: "<integer>",
+	}
+
+	flagReportConflicts = &cli.BoolFlag{
+		Name: "report-conflicts",
+		Usage: `
+		Lists only the changes that have conflicts instead of the full report.
+		Combine with --json to get the conflicts in a machine-readable format.
+		The exit code is 3 if any conflicts are found.
+
+		Example:
+			$ f2 -f 'jpeg' -r 'jpg' --report-conflicts --json`,
+	}
+
+	flagReportDuplicates = &cli.BoolFlag{
+		Name: "report-duplicates",
+		Usage: `
+		Lists the files in different directories that would share a name after
+		renaming (or already do) instead of renaming them. This helps to choose
+		a strategy before moving files from several directories into one. When
+		used without -f or -r, the current names are compared.
+
+		Example:
+			$ f2 -R --report-duplicates
+			$ f2 -f '.*' -r '{f}{ext}' -R -t flat --report-duplicates`,
+	}
+
 	flagResetIndexPerDir = &cli.BoolFlag{
 		Name: "reset-index-per-dir",
 		Usage: `
@@ -249,6 +912,47 @@ var (
 		recursive operation.`,
 	}
 
+	flagRespectGitignore = &cli.BoolFlag{
+		Name: "respect-gitignore",
+		Usage: `
+		Skips the files and directories that are ignored by git when searching
+		for matches. Patterns are read from the .gitignore files in the
+		searched directories and their parents up to the root of the
+		repository, and from the .git/info/exclude file.
+
+		Example:
+			$ f2 -f 'test' -r 'spec' -R --respect-gitignore`,
+	}
+
+	flagResumeIndex = &cli.BoolFlag{
+		Name: "resume-index",
+		Usage: `
+		Continues indexing from the highest number already present in the target
+		directory for files that match the renaming pattern. This prevents
+		conflicts when new files are added to an already numbered collection.
+
+		Example:
+			Before: photo_001.jpg photo_002.jpg IMG_4021.jpg IMG_4022.jpg
+
+			$ f2 -f 'IMG_\d+' -r 'photo_{%03d}' --resume-index -x
+
+			After: photo_001.jpg photo_002.jpg photo_003.jpg photo_004.jpg`,
+	}
+
+	flagRetries = &cli.UintFlag{
+		Name: "retries",
+		Usage: `
+		Retries a rename that fails due to a transient error, such as a busy file
+		or a network share that timed out, up to the specified number of times
+		before it is reported as a failure. The delay between the attempts starts
+		at 100ms and doubles each time up to 10s. Other errors are not retried.
+		Defaults to 0 and can be set through F2_DEFAULT_OPTS.
+
+		Example:
+			$ f2 -f 'jpeg' -r 'jpg' -R --retries 5 -x /mnt/share`,
+		DefaultText: "<integer>",
+	}
+
 	flagSort = &cli.StringFlag{
 		Name: "sort",
 		Usage: `
@@ -290,6 +994,19 @@ var (
 		See https://f2.freshman.tech/guide/sorting for more details.`,
 	}
 
+	flagStep = &cli.IntFlag{
+		Name: "step",
+		Usage: `
+		Sets the amount by which indexes (such as {%03d}) are incremented for each
+		match. Negative values count downwards from the start number. This is
+		ignored for index variables that already specify their own step.
+
+		Example:
+			$ f2 -r '{10%d}' --step 10 (produces 10, 20, 30, ...)`,
+		Value:       1,
+		DefaultText: "<integer>",
+	}
+
 	flagStringMode = &cli.BoolFlag{
 		Name:    "string-mode",
 		Aliases: []string{"s"},
@@ -298,12 +1015,156 @@ var (
 		instead of a regular expression.`,
 	}
 
+	flagSummary = &cli.BoolFlag{
+		Name: "summary",
+		Usage: `
+		Prints the number of files that were matched, renamed, skipped, in
+		conflict, or that failed, along with how long the operation took, after
+		the changes are previewed or committed.`,
+	}
+
+	flagSymlinks = &cli.StringFlag{
+		Name: "symlinks",
+		Usage: `
+		Controls how symbolic links are handled. Use 'rename' to rename the
+		links themselves, 'skip' to leave them out of the matches, or 'follow'
+		to rename the files or directories that they point to instead. In
+		follow mode, the find pattern is matched against the names of the
+		targets, broken links are skipped, and the links are not updated after
+		their targets are renamed.
+
+		Example:
+			$ f2 -f 'jpeg' -r 'jpg' --symlinks skip`,
+		Value:       "rename",
+		DefaultText: "<policy>",
+	}
+
 	flagTargetDir = &cli.StringFlag{
 		Name:    "target-dir",
 		Aliases: []string{"t"},
 		Usage: `
-		Specify a target directory to move renamed files and reorganize your 
-		filesystem.`,
+		Moves the renamed files into the specified directory instead of leaving
+		them in their original directories. The replacement controls the layout
+		under the directory, so slashes in it create subdirectories there. The
+		directory and any missing subdirectories are created as needed.
+
+		Example:
+			$ f2 -f '.*' -r '{mtime.YYYY}/{mtime.MM}/{f}{ext}' -R -t archive`,
+	}
+
+	flagTargetOS = &cli.StringFlag{
+		Name: "target-os",
+		Usage: `
+		Validates the renamed paths against the naming rules of the provided
+		operating system instead of the current one. This is useful when
+		renaming files that are destined for another platform such as a
+		Windows share or a USB drive. Accepts 'windows', 'darwin' (or 'macos'),
+		and 'linux'. Forbidden characters and names that are reserved on the
+		target OS (such as CON or NUL on Windows) are reported as conflicts and
+		fixed when -F/--fix-conflicts is set.
+
+		Example:
+			$ f2 -f '.*' -r '${0}' -R --target-os windows -F`,
+		DefaultText: "<os>",
+	}
+
+	flagThrottle = &cli.StringFlag{
+		Name: "throttle",
+		Usage: `
+		Limits how fast the files are renamed so that large operations on network
+		filesystems such as SMB, NFS, or SSHFS do not overwhelm the server or
+		trip its rate limits. The value is either a rate such as '10/s' or
+		'300/m', where a bare number is a rate per second, or the delay between
+		the renames such as '200ms'. The limit is shared by all --workers. Can
+		be set through F2_DEFAULT_OPTS.
+
+		Example:
+			$ f2 -f 'jpeg' -r 'jpg' -R --throttle 20/s -x /mnt/share`,
+		DefaultText: "<rate|delay>",
+	}
+
+	flagTrash = &cli.BoolFlag{
+		Name: "trash",
+		Usage: `
+		Moves the files that are overwritten with --allow-overwrites to the trash
+		(or the Recycle Bin on Windows) instead of replacing them. The trashed
+		files are put back in place when the operation is undone. They can also
+		be restored from the Recycle Bin on Windows and the trash on Linux, but
+		Finder's Put Back is not available for them on macOS. This option has no
+		effect unless overwriting is allowed. Can be set through F2_DEFAULT_OPTS.
+
+		Example:
+			$ f2 -f 'draft' -r 'final' --allow-overwrites --trash -x`,
+	}
+
+	flagTraversal = &cli.StringFlag{
+		Name: "traversal",
+		Usage: `
+		Controls the order in which matches in nested directories are processed
+		and numbered when no sort is specified. Use 'breadth' (the default) to
+		process all the matches in a directory before those in its
+		subdirectories, 'depth' to process the contents of each directory right
+		after the directory itself, or 'depth-post' to process the contents of
+		each directory before the directory itself.
+
+		Example:
+			$ f2 -f '.*' -r '{%03d}_{f}{ext}' -R -d --traversal depth-post`,
+		DefaultText: "<order>",
+	}
+
+	flagTUI = &cli.BoolFlag{
+		Name: "tui",
+		Usage: `
+		Opens the planned changes in a full-screen view instead of printing them.
+		Use the arrow keys to scroll, space to select or deselect a change, 'a'
+		to select or deselect all the changes, and 'e' or enter to edit the new
+		name of a change. Press 'x' to rename the selected files, or 'q' to quit
+		without renaming anything. Conflicts are checked again after each change
+		and must be resolved before renaming.
+
+		Example:
+			$ f2 -f 'IMG' -r 'Photo' --tui`,
+	}
+
+	flagTwoPhase = &cli.BoolFlag{
+		Name: "two-phase",
+		Usage: `
+		Renames every file to a temporary name first, and then renames the
+		temporary files to their targets once all the sources are out of the way.
+		This avoids collisions between the files in the batch, such as when names
+		are swapped or shifted, and a file that cannot be moved to its target is
+		put back under its original name if it is still free. Directories are
+		renamed afterwards in the usual order. Has no effect with --copy or --link. Can be set through
+		F2_DEFAULT_OPTS.
+
+		Example:
+			$ f2 -f '.*' -r '{%03d}{ext}' --two-phase -x`,
+	}
+
+	flagUndoID = &cli.StringFlag{
+		Name: "undo-id",
+		Usage: `
+		Undo a specific renaming operation in the history by its number or ID as
+		listed by --history. The operation may have been performed in another
+		directory.
+
+		Example:
+			$ f2 --undo-id 2 -x`,
+		DefaultText: "<number|id>",
+	}
+
+	flagUndoLast = &cli.UintFlag{
+		Name: "undo-last",
+		Usage: `
+		Undoes the specified number of renaming operations in the current working
+		directory from the most recent one. The operations are combined so that
+		each file is renamed once to the name it had before the oldest one, and
+		nothing is renamed if a file that one of the operations expects is
+		missing.
+
+		Example:
+			$ f2 --undo-last 3 -x`,
+		DefaultText: "<integer>",
 	}
 
 	flagVerbose = &cli.BoolFlag{
@@ -312,4 +1173,79 @@ var (
 		Usage: `
 		Enables verbose output during the renaming operation.`,
 	}
+
+	flagVerify = &cli.BoolFlag{
+		Name: "verify",
+		Usage: `
+		Checks that each file is found at its new name after renaming, and that
+		its old name is gone, to catch the failures that some filesystems do not
+		report. Copies (--copy) must match the size of the original, and links
+		(--link) must refer to it. The files that do not pass are listed and the
+		command exits with a non-zero status. Can be set through F2_DEFAULT_OPTS.`,
+	}
+
+	flagVerifyChecksum = &cli.BoolFlag{
+		Name: "verify-checksum",
+		Usage: `
+		Like --verify, but also compares the SHA-256 checksums of copied files
+		with their originals, which reads both files in full. Can be set through
+		F2_DEFAULT_OPTS.
+
+		Example:
+			$ f2 -f '.*' -r 'backup/{f}{ext}' --copy --verify-checksum -x`,
+	}
+
+	flagWatch = &cli.BoolFlag{
+		Name: "watch",
+		Usage: `
+		Watches the searched directories and renames the files that are created
+		or moved into them as they appear until interrupted with Ctrl+C. The
+		existing files are left alone. Each group of new files is renamed in a
+		separate operation that can be undone, and the changes are only
+		previewed unless -x/--exec is set. Subdirectories are also watched with
+		-R/--recursive. Hidden files are skipped unless --hidden or
+		--hidden-files is set.
+
+		Example:
+			$ f2 -f '.*' -r '{mtime.YYYY}/{mtime.MM}/{f}{ext}' --watch -x ~/Camera`,
+	}
+
+	flagWhere = &cli.StringSliceFlag{
+		Name: "where",
+		Usage: `
+		Only includes the matched files whose metadata satisfies the provided
+		condition. The condition compares a variable (such as exif.model or
+		id3.artist) to a value using one of these operators: = (equals),
+		!= (not equals), ~ (matches the regular expression), or !~ (does not
+		match the regular expression). This flag can be repeated to specify
+		multiple conditions which must all be satisfied.
+
+		Example:
+			$ f2 -f 'DSC' -r 'A7III' --where 'exif.model=ILCE-7M3'
+			$ f2 -f '.*' -r '{x.cdt}{ext}' --where 'x.make~(?i)^sony'`,
+		DefaultText: "<condition>",
+	}
+
+	flagWorkers = &cli.UintFlag{
+		Name: "workers",
+		Usage: `
+		Sets the number of files that are renamed at the same time, which speeds up
+		large renaming operations on network filesystems. Directories, and files
+		that depend on the new name of another file (such as in a chain or swap of
+		names), are still renamed one at a time in the required order. Defaults
+		to 1 and can be set through F2_DEFAULT_OPTS.
+
+		Example:
+			$ f2 -f 'jpeg' -r 'jpg' -R -x --workers 8`,
+		Value:       1,
+		DefaultText: "<integer>",
+	}
+
+	flagWritableOnly = &cli.BoolFlag{
+		Name: "writable-only",
+		Usage: `
+		Matches only the files and directories that the current user has
+		permission to write to. This helps to avoid failures partway through
+		a renaming operation in directories with mixed ownership.`,
+	}
 )
@@ -5,6 +5,8 @@ import (
 
 	"github.com/pterm/pterm"
 	"github.com/urfave/cli/v2"
+
+	"github.com/ayoisaiah/f2/v2/internal/osutil"
 )
 
 const usageText = `f2 FLAGS [OPTIONS] [PATHS TO FILES AND DIRECTORIES...]
@@ -38,19 +40,74 @@ func helpText(app *cli.App) string {
 		flagUndo.GetUsage(),
 	)
 
+	flagAllowModifiedHelp := fmt.Sprintf(
+		`%s %s`,
+		pterm.Green("--", flagAllowModified.Name),
+		flagAllowModified.GetUsage(),
+	)
+
 	flagAllowOverwritesHelp := fmt.Sprintf(
 		`%s %s`,
 		pterm.Green("--", flagAllowOverwrites.Name),
 		flagAllowOverwrites.GetUsage(),
 	)
 
+	flagBackupHelp := fmt.Sprintf(
+		`%s %s`,
+		pterm.Green("--", flagBackup.Name),
+		flagBackup.GetUsage(),
+	)
+
+	flagBackupDirHelp := fmt.Sprintf(
+		`%s %s`,
+		pterm.Green("--", flagBackupDir.Name),
+		flagBackupDir.GetUsage(),
+	)
+
+	flagCheckHelp := fmt.Sprintf(
+		`%s %s`,
+		pterm.Green("--", flagCheck.Name),
+		flagCheck.GetUsage(),
+	)
+
+	flagCIFSHelp := fmt.Sprintf(
+		`%s %s`,
+		pterm.Green("--", flagCIFS.Name),
+		flagCIFS.GetUsage(),
+	)
+
 	flagCleanHelp := fmt.Sprintf(
-		`%s, %s %s`,
+		`%s, %s, %s %s`,
 		pterm.Green("-", flagClean.Aliases[0]),
 		pterm.Green("--", flagClean.Name),
+		pterm.Green("--", flagClean.Aliases[1]),
 		flagClean.GetUsage(),
 	)
 
+	flagCopyHelp := fmt.Sprintf(
+		`%s %s`,
+		pterm.Green("--", flagCopy.Name),
+		flagCopy.GetUsage(),
+	)
+
+	flagCSVOutputHelp := fmt.Sprintf(
+		`%s %s`,
+		pterm.Green("--", flagCSVOutput.Name),
+		flagCSVOutput.GetUsage(),
+	)
+
+	flagDebugHelp := fmt.Sprintf(
+		`%s %s`,
+		pterm.Green("--", flagDebug.Name),
+		flagDebug.GetUsage(),
+	)
+
+	flagDepthHelp := fmt.Sprintf(
+		`%s %s`,
+		pterm.Green("--", flagDepth.Name),
+		flagDepth.GetUsage(),
+	)
+
 	flagExcludeHelp := fmt.Sprintf(
 		`%s, %s %s`,
 		pterm.Green("-", flagExclude.Aliases[0]),
@@ -77,6 +134,18 @@ func helpText(app *cli.App) string {
 		flagExec.GetUsage(),
 	)
 
+	flagExtHelp := fmt.Sprintf(
+		`%s %s`,
+		pterm.Green("--", flagExt.Name),
+		flagExt.GetUsage(),
+	)
+
+	flagFilterTimeHelp := fmt.Sprintf(
+		`%s %s`,
+		pterm.Green("--", flagFilterTime.Name),
+		flagFilterTime.GetUsage(),
+	)
+
 	flagFixConflictsHelp := fmt.Sprintf(
 		`%s, %s %s`,
 		pterm.Green("-", flagFixConflicts.Aliases[0]),
@@ -85,11 +154,66 @@ func helpText(app *cli.App) string {
 	)
 
 	flagFixConflictsPatternHelp := fmt.Sprintf(
-		`%s %s`,
+		`%s, %s %s`,
 		pterm.Green("--", flagFixConflictsPattern.Name),
+		pterm.Green("--", flagFixConflictsPattern.Aliases[0]),
 		flagFixConflictsPattern.GetUsage(),
 	)
 
+	flagFixEmptyHelp := fmt.Sprintf(
+		`%s %s`,
+		pterm.Green("--", flagFixEmpty.Name),
+		flagFixEmpty.GetUsage(),
+	)
+
+	flagFixExtHelp := fmt.Sprintf(
+		`%s %s`,
+		pterm.Green("--", flagFixExt.Name),
+		flagFixExt.GetUsage(),
+	)
+
+	flagFollowDirLinksHelp := fmt.Sprintf(
+		`%s %s`,
+		pterm.Green("--", flagFollowDirLinks.Name),
+		flagFollowDirLinks.GetUsage(),
+	)
+
+	flagFromFileHelp := fmt.Sprintf(
+		`%s %s`,
+		pterm.Green("--", flagFromFile.Name),
+		flagFromFile.GetUsage(),
+	)
+
+	flagFuzzyHelp := fmt.Sprintf(
+		`%s %s`,
+		pterm.Green("--", flagFuzzy.Name),
+		flagFuzzy.GetUsage(),
+	)
+
+	flagGitHelp := fmt.Sprintf(
+		`%s %s`,
+		pterm.Green("--", flagGit.Name),
+		flagGit.GetUsage(),
+	)
+
+	flagGlobHelp := fmt.Sprintf(
+		`%s %s`,
+		pterm.Green("--", flagGlob.Name),
+		flagGlob.GetUsage(),
+	)
+
+	flagGrepHelp := fmt.Sprintf(
+		`%s %s`,
+		pterm.Green("--", flagGrep.Name),
+		flagGrep.GetUsage(),
+	)
+
+	flagGrepLimitHelp := fmt.Sprintf(
+		`%s %s`,
+		pterm.Green("--", flagGrepLimit.Name),
+		flagGrepLimit.GetUsage(),
+	)
+
 	flagHiddenHelp := fmt.Sprintf(
 		`%s, %s %s`,
 		pterm.Green("-", flagHidden.Aliases[0]),
@@ -97,6 +221,36 @@ func helpText(app *cli.App) string {
 		flagHidden.GetUsage(),
 	)
 
+	flagHiddenDirsHelp := fmt.Sprintf(
+		`%s %s`,
+		pterm.Green("--", flagHiddenDirs.Name),
+		flagHiddenDirs.GetUsage(),
+	)
+
+	flagHiddenFilesHelp := fmt.Sprintf(
+		`%s %s`,
+		pterm.Green("--", flagHiddenFiles.Name),
+		flagHiddenFiles.GetUsage(),
+	)
+
+	flagHistoryHelp := fmt.Sprintf(
+		`%s %s`,
+		pterm.Green("--", flagHistory.Name),
+		flagHistory.GetUsage(),
+	)
+
+	flagHistoryLimitHelp := fmt.Sprintf(
+		`%s %s`,
+		pterm.Green("--", flagHistoryLimit.Name),
+		flagHistoryLimit.GetUsage(),
+	)
+
+	flagHistoryMaxAgeHelp := fmt.Sprintf(
+		`%s %s`,
+		pterm.Green("--", flagHistoryMaxAge.Name),
+		flagHistoryMaxAge.GetUsage(),
+	)
+
 	flagIncludeDirHelp := fmt.Sprintf(
 		`%s, %s %s`,
 		pterm.Green("-", flagIncludeDir.Aliases[0]),
@@ -118,12 +272,42 @@ func helpText(app *cli.App) string {
 		flagIgnoreExt.GetUsage(),
 	)
 
+	flagInteractiveHelp := fmt.Sprintf(
+		`%s %s`,
+		pterm.Green("--", flagInteractive.Name),
+		flagInteractive.GetUsage(),
+	)
+
+	flagInvertHelp := fmt.Sprintf(
+		`%s %s`,
+		pterm.Green("--", flagInvert.Name),
+		flagInvert.GetUsage(),
+	)
+
 	flagJSONHelp := fmt.Sprintf(
 		`%s %s`,
 		pterm.Green("--", flagJSON.Name),
 		flagJSON.GetUsage(),
 	)
 
+	flagLinkHelp := fmt.Sprintf(
+		`%s %s`,
+		pterm.Green("--", flagLink.Name),
+		flagLink.GetUsage(),
+	)
+
+	flagLongPathsHelp := fmt.Sprintf(
+		`%s %s`,
+		pterm.Green("--", flagLongPaths.Name),
+		flagLongPaths.GetUsage(),
+	)
+
+	flagMatchPathHelp := fmt.Sprintf(
+		`%s %s`,
+		pterm.Green("--", flagMatchPath.Name),
+		flagMatchPath.GetUsage(),
+	)
+
 	flagMaxDepthHelp := fmt.Sprintf(
 		`%s, %s %s`,
 		pterm.Green("-", flagMaxDepth.Aliases[0]),
@@ -131,12 +315,72 @@ func helpText(app *cli.App) string {
 		flagMaxDepth.GetUsage(),
 	)
 
+	flagMaxSizeHelp := fmt.Sprintf(
+		`%s %s`,
+		pterm.Green("--", flagMaxSize.Name),
+		flagMaxSize.GetUsage(),
+	)
+
+	flagMinSizeHelp := fmt.Sprintf(
+		`%s %s`,
+		pterm.Green("--", flagMinSize.Name),
+		flagMinSize.GetUsage(),
+	)
+
+	flagNewerThanHelp := fmt.Sprintf(
+		`%s %s`,
+		pterm.Green("--", flagNewerThan.Name),
+		flagNewerThan.GetUsage(),
+	)
+
 	flagNoColorHelp := fmt.Sprintf(
 		`%s %s`,
 		pterm.Green("--", flagNoColor.Name),
 		flagNoColor.GetUsage(),
 	)
 
+	flagNoLockHelp := fmt.Sprintf(
+		`%s %s`,
+		pterm.Green("--", flagNoLock.Name),
+		flagNoLock.GetUsage(),
+	)
+
+	flagNoPreserveHelp := fmt.Sprintf(
+		`%s %s`,
+		pterm.Green("--", flagNoPreserve.Name),
+		flagNoPreserve.GetUsage(),
+	)
+
+	flagNormalizeHelp := fmt.Sprintf(
+		`%s %s`,
+		pterm.Green("--", flagNormalize.Name),
+		flagNormalize.GetUsage(),
+	)
+
+	flagNumberSkipHelp := fmt.Sprintf(
+		`%s %s`,
+		pterm.Green("--", flagNumberSkip.Name),
+		flagNumberSkip.GetUsage(),
+	)
+
+	flagOlderThanHelp := fmt.Sprintf(
+		`%s %s`,
+		pterm.Green("--", flagOlderThan.Name),
+		flagOlderThan.GetUsage(),
+	)
+
+	flagOnErrorHelp := fmt.Sprintf(
+		`%s %s`,
+		pterm.Green("--", flagOnError.Name),
+		flagOnError.GetUsage(),
+	)
+
+	flagOneFileSystemHelp := fmt.Sprintf(
+		`%s %s`,
+		pterm.Green("--", flagOneFileSystem.Name),
+		flagOneFileSystem.GetUsage(),
+	)
+
 	flagOnlyDirHelp := fmt.Sprintf(
 		`%s, %s %s`,
 		pterm.Green("-", flagOnlyDir.Aliases[0]),
@@ -144,6 +388,18 @@ func helpText(app *cli.App) string {
 		flagOnlyDir.GetUsage(),
 	)
 
+	flagOwnedByHelp := fmt.Sprintf(
+		`%s %s`,
+		pterm.Green("--", flagOwnedBy.Name),
+		flagOwnedBy.GetUsage(),
+	)
+
+	flagPadNumHelp := fmt.Sprintf(
+		`%s %s`,
+		pterm.Green("--", flagPadNum.Name),
+		flagPadNum.GetUsage(),
+	)
+
 	flagPairHelp := fmt.Sprintf(
 		`%s, %s %s`,
 		pterm.Green("-", flagPair.Aliases[0]),
@@ -157,6 +413,30 @@ func helpText(app *cli.App) string {
 		flagPairOrder.GetUsage(),
 	)
 
+	flagPCREHelp := fmt.Sprintf(
+		`%s %s`,
+		pterm.Green("--", flagPCRE.Name),
+		flagPCRE.GetUsage(),
+	)
+
+	flagPreserveOwnerHelp := fmt.Sprintf(
+		`%s %s`,
+		pterm.Green("--", flagPreserveOwner.Name),
+		flagPreserveOwner.GetUsage(),
+	)
+
+	flagPrint0Help := fmt.Sprintf(
+		`%s %s`,
+		pterm.Green("--", flagPrint0.Name),
+		flagPrint0.GetUsage(),
+	)
+
+	flagPruneHistoryHelp := fmt.Sprintf(
+		`%s %s`,
+		pterm.Green("--", flagPruneHistory.Name),
+		flagPruneHistory.GetUsage(),
+	)
+
 	flagQuietHelp := fmt.Sprintf(
 		`%s %s`,
 		pterm.Green("--", flagQuiet.Name),
@@ -170,6 +450,12 @@ func helpText(app *cli.App) string {
 		flagRecursive.GetUsage(),
 	)
 
+	flagRedoHelp := fmt.Sprintf(
+		`%s %s`,
+		pterm.Green("--", flagRedo.Name),
+		flagRedo.GetUsage(),
+	)
+
 	flagReplaceLimitHelp := fmt.Sprintf(
 		`%s, %s %s`,
 		pterm.Green("-", flagReplaceLimit.Aliases[0]),
@@ -177,12 +463,54 @@ func helpText(app *cli.App) string {
 		flagReplaceLimit.GetUsage(),
 	)
 
+	flagReplaceNthHelp := fmt.Sprintf(
+		`%s %s`,
+		pterm.Green("--", flagReplaceNth.Name),
+		flagReplaceNth.GetUsage(),
+	)
+
+	flagRenumberHelp := fmt.Sprintf(
+		`%s %s`,
+		pterm.Green("--", flagRenumber.Name),
+		flagRenumber.GetUsage(),
+	)
+
+	flagReportConflictsHelp := fmt.Sprintf(
+		`%s %s`,
+		pterm.Green("--", flagReportConflicts.Name),
+		flagReportConflicts.GetUsage(),
+	)
+
+	flagReportDuplicatesHelp := fmt.Sprintf(
+		`%s %s`,
+		pterm.Green("--", flagReportDuplicates.Name),
+		flagReportDuplicates.GetUsage(),
+	)
+
 	flagResetIndexPerDirHelp := fmt.Sprintf(
 		`%s %s`,
 		pterm.Green("--", flagResetIndexPerDir.Name),
 		flagResetIndexPerDir.GetUsage(),
 	)
 
+	flagRespectGitignoreHelp := fmt.Sprintf(
+		`%s %s`,
+		pterm.Green("--", flagRespectGitignore.Name),
+		flagRespectGitignore.GetUsage(),
+	)
+
+	flagResumeIndexHelp := fmt.Sprintf(
+		`%s %s`,
+		pterm.Green("--", flagResumeIndex.Name),
+		flagResumeIndex.GetUsage(),
+	)
+
+	flagRetriesHelp := fmt.Sprintf(
+		`%s %s`,
+		pterm.Green("--", flagRetries.Name),
+		flagRetries.GetUsage(),
+	)
+
 	flagSortHelp := fmt.Sprintf(
 		`%s %s`,
 		pterm.Green("--", flagSort.Name),
@@ -207,6 +535,12 @@ func helpText(app *cli.App) string {
 		flagSortVar.GetUsage(),
 	)
 
+	flagStepHelp := fmt.Sprintf(
+		`%s %s`,
+		pterm.Green("--", flagStep.Name),
+		flagStep.GetUsage(),
+	)
+
 	flagStringModeHelp := fmt.Sprintf(
 		`%s, %s %s`,
 		pterm.Green("-", flagStringMode.Aliases[0]),
@@ -214,6 +548,18 @@ func helpText(app *cli.App) string {
 		flagStringMode.GetUsage(),
 	)
 
+	flagSummaryHelp := fmt.Sprintf(
+		`%s %s`,
+		pterm.Green("--", flagSummary.Name),
+		flagSummary.GetUsage(),
+	)
+
+	flagSymlinksHelp := fmt.Sprintf(
+		`%s %s`,
+		pterm.Green("--", flagSymlinks.Name),
+		flagSymlinks.GetUsage(),
+	)
+
 	flagTargetDirHelp := fmt.Sprintf(
 		`%s, %s %s`,
 		pterm.Green("-", flagTargetDir.Aliases[0]),
@@ -221,6 +567,54 @@ func helpText(app *cli.App) string {
 		flagTargetDir.GetUsage(),
 	)
 
+	flagTargetOSHelp := fmt.Sprintf(
+		`%s %s`,
+		pterm.Green("--", flagTargetOS.Name),
+		flagTargetOS.GetUsage(),
+	)
+
+	flagThrottleHelp := fmt.Sprintf(
+		`%s %s`,
+		pterm.Green("--", flagThrottle.Name),
+		flagThrottle.GetUsage(),
+	)
+
+	flagTrashHelp := fmt.Sprintf(
+		`%s %s`,
+		pterm.Green("--", flagTrash.Name),
+		flagTrash.GetUsage(),
+	)
+
+	flagTraversalHelp := fmt.Sprintf(
+		`%s %s`,
+		pterm.Green("--", flagTraversal.Name),
+		flagTraversal.GetUsage(),
+	)
+
+	flagTUIHelp := fmt.Sprintf(
+		`%s %s`,
+		pterm.Green("--", flagTUI.Name),
+		flagTUI.GetUsage(),
+	)
+
+	flagTwoPhaseHelp := fmt.Sprintf(
+		`%s %s`,
+		pterm.Green("--", flagTwoPhase.Name),
+		flagTwoPhase.GetUsage(),
+	)
+
+	flagUndoIDHelp := fmt.Sprintf(
+		`%s %s`,
+		pterm.Green("--", flagUndoID.Name),
+		flagUndoID.GetUsage(),
+	)
+
+	flagUndoLastHelp := fmt.Sprintf(
+		`%s %s`,
+		pterm.Green("--", flagUndoLast.Name),
+		flagUndoLast.GetUsage(),
+	)
+
 	flagVerboseHelp := fmt.Sprintf(
 		`%s, %s %s`,
 		pterm.Green("-", flagVerbose.Aliases[0]),
@@ -228,6 +622,42 @@ func helpText(app *cli.App) string {
 		flagVerbose.GetUsage(),
 	)
 
+	flagVerifyHelp := fmt.Sprintf(
+		`%s %s`,
+		pterm.Green("--", flagVerify.Name),
+		flagVerify.GetUsage(),
+	)
+
+	flagVerifyChecksumHelp := fmt.Sprintf(
+		`%s %s`,
+		pterm.Green("--", flagVerifyChecksum.Name),
+		flagVerifyChecksum.GetUsage(),
+	)
+
+	flagWatchHelp := fmt.Sprintf(
+		`%s %s`,
+		pterm.Green("--", flagWatch.Name),
+		flagWatch.GetUsage(),
+	)
+
+	flagWhereHelp := fmt.Sprintf(
+		`%s %s`,
+		pterm.Green("--", flagWhere.Name),
+		flagWhere.GetUsage(),
+	)
+
+	flagWorkersHelp := fmt.Sprintf(
+		`%s %s`,
+		pterm.Green("--", flagWorkers.Name),
+		flagWorkers.GetUsage(),
+	)
+
+	flagWritableOnlyHelp := fmt.Sprintf(
+		`%s %s`,
+		pterm.Green("--", flagWritableOnly.Name),
+		flagWritableOnly.GetUsage(),
+	)
+
 	return fmt.Sprintf(`%s %s
 %s
 
@@ -253,8 +683,20 @@ Project repository: https://github.com/ayoisaiah/f2
 
 	%s
 
+	%s
+
 %s
 	%s
+
+	%s
+
+	%s
+
+	%s
+
+	%s
+
+	%s
 	
 	%s
 
@@ -312,6 +754,139 @@ Project repository: https://github.com/ayoisaiah/f2
 
 	%s
 
+	%s
+
+	%s
+
+	%s
+
+	%s
+
+	%s
+
+	%s
+
+	%s
+
+	%s
+
+	%s
+
+	%s
+
+	%s
+
+	%s
+
+	%s
+
+	%s
+
+	%s
+
+	%s
+
+	%s
+
+	%s
+
+	%s
+
+	%s
+
+	%s
+
+	%s
+
+	%s
+
+	%s
+
+	%s
+
+	%s
+
+	%s
+
+	%s
+
+	%s
+
+	%s
+
+	%s
+
+	%s
+
+	%s
+
+	%s
+
+	%s
+
+	%s
+
+	%s
+
+	%s
+
+	%s
+
+	%s
+
+	%s
+
+	%s
+
+	%s
+
+	%s
+
+	%s
+
+	%s
+
+	%s
+
+	%s
+
+	%s
+
+	%s
+
+	%s
+
+	%s
+
+	%s
+
+	%s
+
+	%s
+
+	%s
+
+	%s
+
+	%s
+
+	%s
+
+	%s
+
+	%s
+
+	%s
+
+	%s
+
+	%s
+
+	%s
+
+%s
+	%s
+
 %s
 	%s
 
@@ -332,37 +907,110 @@ Project repository: https://github.com/ayoisaiah/f2
 		flagReplaceHelp,
 		flagUndoHelp,
 		pterm.Bold.Sprintf("OPTIONS"),
+		flagAllowModifiedHelp,
 		flagAllowOverwritesHelp,
+		flagBackupHelp,
+		flagBackupDirHelp,
+		flagCheckHelp,
+		flagCIFSHelp,
 		flagCleanHelp,
+		flagCopyHelp,
+		flagCSVOutputHelp,
+		flagDebugHelp,
+		flagDepthHelp,
 		flagExcludeHelp,
 		flagExcludeDirHelp,
 		flagExiftoolOptsHelp,
 		flagExecHelp,
+		flagExtHelp,
+		flagFilterTimeHelp,
 		flagFixConflictsHelp,
 		flagFixConflictsPatternHelp,
+		flagFixEmptyHelp,
+		flagFixExtHelp,
+		flagFollowDirLinksHelp,
+		flagFromFileHelp,
+		flagFuzzyHelp,
+		flagGitHelp,
+		flagGlobHelp,
+		flagGrepHelp,
+		flagGrepLimitHelp,
 		flagHiddenHelp,
+		flagHiddenDirsHelp,
+		flagHiddenFilesHelp,
+		flagHistoryHelp,
+		flagHistoryLimitHelp,
+		flagHistoryMaxAgeHelp,
 		flagIncludeDirHelp,
 		flagIgnoreCaseHelp,
 		flagIgnoreExtHelp,
+		flagInteractiveHelp,
+		flagInvertHelp,
 		flagJSONHelp,
+		flagLinkHelp,
+		flagLongPathsHelp,
+		flagMatchPathHelp,
 		flagMaxDepthHelp,
+		flagMaxSizeHelp,
+		flagMinSizeHelp,
+		flagNewerThanHelp,
 		flagNoColorHelp,
+		flagNoLockHelp,
+		flagNoPreserveHelp,
+		flagNormalizeHelp,
+		flagNumberSkipHelp,
+		flagOlderThanHelp,
+		flagOnErrorHelp,
+		flagOneFileSystemHelp,
 		flagOnlyDirHelp,
+		flagOwnedByHelp,
+		flagPadNumHelp,
 		flagPairHelp,
 		flagPairOrderHelp,
+		flagPCREHelp,
+		flagPreserveOwnerHelp,
+		flagPrint0Help,
+		flagPruneHistoryHelp,
 		flagQuietHelp,
 		flagRecursiveHelp,
+		flagRedoHelp,
 		flagReplaceLimitHelp,
+		flagReplaceNthHelp,
+		flagRenumberHelp,
+		flagReportConflictsHelp,
+		flagReportDuplicatesHelp,
 		flagResetIndexPerDirHelp,
+		flagRespectGitignoreHelp,
+		flagResumeIndexHelp,
+		flagRetriesHelp,
 		flagSortHelp,
 		flagSortrHelp,
 		flagSortPerDirHelp,
 		flagSortVarHelp,
+		flagStepHelp,
 		flagStringModeHelp,
+		flagSummaryHelp,
+		flagSymlinksHelp,
 		flagTargetDirHelp,
+		flagTargetOSHelp,
+		flagThrottleHelp,
+		flagTrashHelp,
+		flagTraversalHelp,
+		flagTUIHelp,
+		flagTwoPhaseHelp,
+		flagUndoIDHelp,
+		flagUndoLastHelp,
 		flagVerboseHelp,
+		flagVerifyHelp,
+		flagVerifyChecksumHelp,
+		flagWatchHelp,
+		flagWhereHelp,
+		flagWorkersHelp,
+		flagWritableOnlyHelp,
 		pterm.Bold.Sprintf("ENVIRONMENTAL VARIABLES"),
 		envHelp(),
+		pterm.Bold.Sprintf("EXIT CODES"),
+		exitCodesHelp(),
 		pterm.Bold.Sprintf("LEARN MORE"),
 	)
 }
@@ -382,6 +1030,33 @@ func envHelp() string {
 	)
 }
 
+func exitCodesHelp() string {
+	return fmt.Sprintf(`%s
+		The operation completed successfully.
+
+	%s
+		An error occurred, such as an invalid option.
+
+	%s
+		The search criteria didn't match any files.
+
+	%s
+		Conflicts were detected in the renaming operation.
+
+	%s
+		Some files could not be renamed.
+
+	%s
+		Files would be renamed (with --check).`,
+		pterm.Green(int(osutil.ExitOK)),
+		pterm.Green(int(osutil.ExitError)),
+		pterm.Green(int(osutil.ExitNoMatches)),
+		pterm.Green(int(osutil.ExitConflicts)),
+		pterm.Green(int(osutil.ExitPartialFailure)),
+		pterm.Green(int(osutil.ExitPendingChanges)),
+	)
+}
+
 func ShortHelp(_ *cli.App) string {
 	return fmt.Sprintf(
 		`The batch renaming tool you'll actually enjoy using.
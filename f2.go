@@ -2,6 +2,7 @@ package f2
 
 import (
 	"io"
+	"time"
 
 	"github.com/urfave/cli/v2"
 
@@ -9,52 +10,193 @@ import (
 	"github.com/ayoisaiah/f2/v2/find"
 	"github.com/ayoisaiah/f2/v2/internal/apperr"
 	"github.com/ayoisaiah/f2/v2/internal/config"
+	"github.com/ayoisaiah/f2/v2/internal/osutil"
 	"github.com/ayoisaiah/f2/v2/rename"
 	"github.com/ayoisaiah/f2/v2/replace"
 	"github.com/ayoisaiah/f2/v2/report"
+	"github.com/ayoisaiah/f2/v2/tui"
 	"github.com/ayoisaiah/f2/v2/validate"
 )
 
-var errConflictDetected = &apperr.Error{
-	Message: "conflict: resolve manually or use -F/--fix-conflicts",
-}
+// The codes that f2 exits with, which scripts and programs that wrap f2 can
+// use to tell the outcome of an operation apart without parsing its output.
+const (
+	// ExitOK means that the operation completed successfully.
+	ExitOK = int(osutil.ExitOK)
+	// ExitError means that an error occurred, such as an invalid option.
+	ExitError = int(osutil.ExitError)
+	// ExitNoMatches means that the search did not match any files.
+	ExitNoMatches = int(osutil.ExitNoMatches)
+	// ExitConflicts means that conflicts were detected and nothing was
+	// renamed.
+	ExitConflicts = int(osutil.ExitConflicts)
+	// ExitPartialFailure means that some files could not be renamed.
+	ExitPartialFailure = int(osutil.ExitPartialFailure)
+	// ExitPendingChanges means that files would be renamed (with --check).
+	ExitPendingChanges = int(osutil.ExitPendingChanges)
+)
+
+var (
+	errConflictDetected = &apperr.Error{
+		Message:  "conflict: resolve manually or use -F/--fix-conflicts",
+		ExitCode: int(osutil.ExitConflicts),
+	}
+
+	// errNoMatches has no message since it is reported by report.NoMatches.
+	errNoMatches = &apperr.Error{
+		ExitCode: int(osutil.ExitNoMatches),
+	}
+
+	errWatchFile = &apperr.Error{
+		Message: "--watch requires directories but '%s' is a file",
+	}
+
+	// errPendingChanges has no message since a check only reports its outcome
+	// through the exit code.
+	errPendingChanges = &apperr.Error{
+		ExitCode: int(osutil.ExitPendingChanges),
+	}
+)
 
 // execute initiates a new renaming operation based on the provided CLI context.
-func execute(_ *cli.Context) error {
+func execute(ctx *cli.Context) error {
 	appConfig := config.Get()
 
+	if appConfig.ListHistory {
+		history, err := config.History()
+		if err != nil {
+			return err
+		}
+
+		report.History(appConfig, history)
+
+		return nil
+	}
+
+	if appConfig.PruneHistory {
+		pruned, err := config.PruneHistory(appConfig)
+		if err != nil {
+			return err
+		}
+
+		report.HistoryPruned(appConfig, pruned)
+
+		return nil
+	}
+
+	// Each batch of new files is renamed while holding the lock instead
+	if appConfig.Watch {
+		return watch(ctx.Context, appConfig)
+	}
+
+	// The files are matched while holding the lock so that they reflect the
+	// renames of an earlier operation that held it
+	if (appConfig.Exec || appConfig.TUI) && !appConfig.NoLock {
+		// The files in a CSV file are relative to its directory rather than
+		// the searched paths
+		lockedPaths := appConfig.FilesAndDirPaths
+		if appConfig.CSVFilename != "" {
+			lockedPaths = []string{appConfig.WorkingDir}
+		}
+
+		unlock, err := config.Lock(lockedPaths)
+		if err != nil {
+			return err
+		}
+
+		defer unlock()
+	}
+
 	changes, err := find.Find(appConfig)
 	if err != nil {
 		return err
 	}
 
 	if len(changes) == 0 {
+		// Nothing to rename means that the check passes
+		if appConfig.Check {
+			report.Check(appConfig, nil)
+
+			return nil
+		}
+
 		report.NoMatches(appConfig)
 
-		return nil
+		return errNoMatches
 	}
 
-	if !appConfig.Revert {
+	if !appConfig.Revert && !appConfig.Redo {
 		changes, err = replace.Replace(appConfig, changes)
 		if err != nil {
 			return err
 		}
 	}
 
+	if appConfig.ReportDuplicates {
+		report.Duplicates(appConfig, changes.Duplicates())
+
+		return nil
+	}
+
 	hasConflicts := validate.Validate(
 		changes,
 		appConfig.AutoFixConflicts,
 		appConfig.AllowOverwrites,
 	)
 
+	if appConfig.Check {
+		pending := changes.Pending()
+
+		report.Check(appConfig, pending)
+
+		if hasConflicts {
+			return errConflictDetected
+		}
+
+		if len(pending) > 0 {
+			return errPendingChanges
+		}
+
+		return nil
+	}
+
+	if appConfig.ReportConflicts {
+		report.Conflicts(appConfig, changes.Conflicts())
+
+		if hasConflicts {
+			return errConflictDetected
+		}
+
+		return nil
+	}
+
+	if appConfig.TUI {
+		var approved bool
+
+		changes, approved, err = tui.Review(appConfig, changes)
+		if err != nil || !approved {
+			return err
+		}
+
+		appConfig.Exec, hasConflicts = true, false
+	}
+
 	if hasConflicts {
 		report.Report(appConfig, changes, hasConflicts)
+		report.Summary(appConfig, changes, time.Since(appConfig.Date))
 
 		return errConflictDetected
 	}
 
 	if !appConfig.Exec {
 		report.Report(appConfig, changes, hasConflicts)
+
+		if appConfig.Clean {
+			report.EmptyDirs(appConfig, rename.EmptyDirs(appConfig, changes))
+		}
+
+		report.Summary(appConfig, changes, time.Since(appConfig.Date))
+
 		return nil
 	}
 
@@ -62,9 +204,32 @@ func execute(_ *cli.Context) error {
 
 	rename.PostRename(appConfig, changes, err)
 
+	if appConfig.Verify {
+		verifyErr := rename.Verify(appConfig, changes)
+		if err == nil {
+			err = verifyErr
+		}
+	}
+
+	report.Summary(appConfig, changes, time.Since(appConfig.Date))
+
 	return err
 }
 
+// ExitCode returns the code that f2 exits with when running the application
+// returns the error.
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+
+	if code := apperr.ExitCode(err); code != 0 {
+		return code
+	}
+
+	return ExitError
+}
+
 // New creates a new CLI application for f2.
 func New(reader io.Reader, writer io.Writer) (*cli.App, error) {
 	renamer, err := app.Get(reader, writer)
@@ -1,60 +1,1504 @@
 package f2_test
 
 import (
-	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/ayoisaiah/f2/v2"
 	"github.com/ayoisaiah/f2/v2/internal/config"
+	"github.com/ayoisaiah/f2/v2/internal/file"
+	"github.com/ayoisaiah/f2/v2/internal/osutil"
 	"github.com/ayoisaiah/f2/v2/internal/testutil"
+	"github.com/ayoisaiah/f2/v2/internal/trash"
 )
 
+// runSteps runs the test cases in order from the working directory, so that
+// each one operates on the files left by the ones before it. The SetupFunc of
+// a test case runs before f2 and its teardown once the test case is checked.
+// A test case passes if f2 exits with its ExitCode and an error that contains
+// the message of its Error, the paths in Want exist, the files in
+// WantContents have those contents, and the paths in WantMissing do not
+// exist. The test stops at the first test case that fails.
+func runSteps(t *testing.T, steps []testutil.TestCase) {
+	t.Helper()
+
+	for i := range steps {
+		tc := &steps[i]
+
+		passed := t.Run(tc.Name, func(t *testing.T) {
+			if tc.SetupFunc != nil {
+				t.Cleanup(tc.SetupFunc(t, "."))
+			}
+
+			err := testutil.RunApp(context.Background(), f2.New, tc)
+			if got := f2.ExitCode(err); got != tc.ExitCode {
+				t.Fatalf("expected exit code %d, got %d (%v)", tc.ExitCode, got, err)
+			}
+
+			if tc.Error != nil &&
+				(err == nil || !strings.Contains(err.Error(), tc.Error.Error())) {
+				t.Fatalf("expected an error containing %q, got %v", tc.Error, err)
+			}
+
+			for _, path := range tc.Want {
+				if _, err := os.Lstat(filepath.FromSlash(path)); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			for path, want := range tc.WantContents {
+				got, err := os.ReadFile(filepath.FromSlash(path))
+				if err != nil || string(got) != want {
+					t.Fatalf("expected %s to contain %q, got %q (%v)", path, want, got, err)
+				}
+			}
+
+			for _, path := range tc.WantMissing {
+				_, err := os.Lstat(filepath.FromSlash(path))
+				if !errors.Is(err, os.ErrNotExist) {
+					t.Fatalf("expected %s not to exist, got %v", path, err)
+				}
+			}
+		})
+		if !passed {
+			t.FailNow()
+		}
+	}
+}
+
+// writeFiles returns a setup function that writes the files before a test
+// case runs, such as to edit a file between two operations.
+func writeFiles(files map[string]string) func(t *testing.T, testDir string) func() {
+	return func(t *testing.T, _ string) func() {
+		t.Helper()
+
+		testutil.WriteFiles(t, files)
+
+		return func() {}
+	}
+}
+
+// assertHistoryLen returns a setup function whose teardown checks the number
+// of operations in the history after a test case runs.
+func assertHistoryLen(want int) func(t *testing.T, testDir string) func() {
+	return func(t *testing.T, _ string) func() {
+		t.Helper()
+
+		return func() {
+			history, err := config.History()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(history) != want {
+				t.Fatalf("expected %d operations in the history, got %d", want, len(history))
+			}
+		}
+	}
+}
+
+// listHistory returns the history that f2 printed with --history --json.
+func listHistory(t *testing.T, tc *testutil.TestCase) []config.HistoryEntry {
+	t.Helper()
+
+	var entries []config.HistoryEntry
+
+	err := json.Unmarshal(tc.SnapShot.Stdout, &entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return entries
+}
+
 func TestImagePairRenaming(t *testing.T) {
-	var stdout bytes.Buffer
+	tc := &testutil.TestCase{
+		Name: "image pair renaming",
+		Args: []string{
+			"-r",
+			"{x.cdt.YYYY}/{x.cdt.MM}-{x.cdt.MMM}/{x.cdt.YYYY}-{x.cdt.MM}-{x.cdt.DD}/{%03d}",
+			"-R",
+			"--target-dir",
+			".",
+			"--pair",
+			"--reset-index-per-dir",
+			"-F",
+			"--fix-conflicts-pattern",
+			"%03d",
+			"--sort",
+			"time_var",
+			"--sort-var",
+			"{x.cdt}",
+			"--pair-order",
+			"dng,jpg",
+			"--exclude",
+			"golden",
+			"testdata",
+		},
+	}
+
+	err := testutil.RunApp(context.Background(), f2.New, tc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testutil.CompareGoldenFile(t, tc)
+}
+
+func TestPCRE(t *testing.T) {
+	testutil.SetupWorkingDir(t, map[string]string{"IMG_1.jpg": ""})
+
+	runSteps(t, []testutil.TestCase{
+		{
+			Name:     "reject a lookbehind without --pcre",
+			Args:     []string{"-f", `(?<=IMG_)\d+`, "-r", "{%03d}", "-x"},
+			ExitCode: f2.ExitError,
+			Error:    errors.New("--pcre"),
+			Want:     []string{"IMG_1.jpg"},
+		},
+		{
+			Name: "match a lookbehind with --pcre",
+			Args: []string{"-f", `(?<=IMG_)\d+`, "-r", "{%03d}", "-x", "--pcre"},
+			Want: []string{"IMG_001.jpg"},
+		},
+	})
+}
 
-	var stdin bytes.Buffer
+func TestUndoHistory(t *testing.T) {
+	// The history is kept per working directory
+	testutil.SetupWorkingDir(t, map[string]string{"a.txt": ""})
 
-	var stderr bytes.Buffer
+	runSteps(t, []testutil.TestCase{
+		{
+			Name: "rename a file",
+			Args: []string{"-f", "a", "-r", "b", "-x"},
+			Want: []string{"b.txt"},
+		},
+		{
+			Name: "rename the file again",
+			Args: []string{"-f", "b", "-r", "c", "-x"},
+			Want: []string{"c.txt"},
+		},
+		{
+			Name: "undo the last operation",
+			Args: []string{"-u", "-x"},
+			Want: []string{"b.txt"},
+		},
+		{
+			// Each undo reverts the operation before the last one that was
+			// undone
+			Name: "undo the operation before it",
+			Args: []string{"-u", "-x"},
+			Want: []string{"a.txt"},
+		},
+		{
+			// Each redo performs the operation that was undone last
+			Name: "redo the first operation",
+			Args: []string{"--redo", "-x"},
+			Want: []string{"b.txt"},
+		},
+		{
+			Name: "redo the second operation",
+			Args: []string{"--redo", "-x"},
+			Want: []string{"c.txt"},
+		},
+		{
+			Name: "undo a redone operation",
+			Args: []string{"-u", "-x"},
+			Want: []string{"b.txt"},
+		},
+	})
+}
+
+func TestRedoAfterNewOperation(t *testing.T) {
+	testutil.SetupWorkingDir(t, map[string]string{"a.txt": ""})
+
+	steps := []testutil.TestCase{
+		{
+			Name: "rename a file",
+			Args: []string{"-f", "a", "-r", "b", "-x"},
+		},
+		{
+			Name: "undo the operation",
+			Args: []string{"-u", "-x"},
+		},
+		{
+			Name: "rename the file differently",
+			Args: []string{"-f", "a", "-r", "c", "-x"},
+		},
+		{
+			// A redo without an undone operation matches nothing
+			Name:     "redo after the new operation",
+			Args:     []string{"--redo", "-x"},
+			ExitCode: f2.ExitNoMatches,
+			Want:     []string{"c.txt"},
+		},
+	}
+
+	runSteps(t, steps)
+
+	if stderr := string(steps[3].SnapShot.Stderr); !strings.Contains(stderr, "nothing to redo") {
+		t.Fatalf("expected nothing to redo, but got: %s", stderr)
+	}
+}
+
+func TestUndoModifiedFile(t *testing.T) {
+	rename := testutil.TestCase{
+		Name: "rename the file",
+		Args: []string{"-f", "a", "-r", "b", "-x"},
+	}
+
+	// The file is edited after it is renamed
+	edit := writeFiles(map[string]string{"b.txt": "final"})
+
+	testCases := []struct {
+		name  string
+		steps []testutil.TestCase
+	}{
+		{
+			name: "skip a file modified after it was renamed",
+			steps: []testutil.TestCase{
+				rename,
+				{
+					Name:      "undo the operation",
+					Args:      []string{"-u", "-x"},
+					SetupFunc: edit,
+					ExitCode:  f2.ExitPartialFailure,
+					Want:      []string{"b.txt"},
+				},
+			},
+		},
+		{
+			name: "restore a modified file with --allow-modified",
+			steps: []testutil.TestCase{
+				rename,
+				{
+					Name:      "undo the operation",
+					Args:      []string{"-u", "-x", "--allow-modified"},
+					SetupFunc: edit,
+					Want:      []string{"a.txt"},
+				},
+			},
+		},
+		{
+			name: "restore a skipped file by undoing again",
+			steps: []testutil.TestCase{
+				rename,
+				{
+					Name:      "undo the operation",
+					Args:      []string{"-u", "-x"},
+					SetupFunc: edit,
+					ExitCode:  f2.ExitPartialFailure,
+					Want:      []string{"b.txt"},
+				},
+				{
+					Name: "undo the operation again",
+					Args: []string{"-u", "-x", "--allow-modified"},
+					Want: []string{"a.txt"},
+				},
+			},
+		},
+	}
 
-	app, err := f2.New(&stdin, &stdout)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testutil.SetupWorkingDir(t, map[string]string{"a.txt": "draft"})
+
+			runSteps(t, tc.steps)
+		})
+	}
+}
+
+func TestUndoFileEditedBetweenOperations(t *testing.T) {
+	testutil.SetupWorkingDir(t, map[string]string{"a.txt": "draft"})
+
+	runSteps(t, []testutil.TestCase{
+		{
+			Name: "rename the file",
+			Args: []string{"-f", "a", "-r", "b", "-x"},
+		},
+		{
+			// The file is edited before it is renamed again, so only its state
+			// after the second operation is compared when undoing both
+			Name:      "rename the edited file",
+			Args:      []string{"-f", "b", "-r", "c", "-x"},
+			SetupFunc: writeFiles(map[string]string{"b.txt": "final"}),
+		},
+		{
+			Name:         "undo both operations",
+			Args:         []string{"--undo-last", "2", "-x"},
+			WantContents: map[string]string{"a.txt": "final"},
+		},
+	})
+}
+
+func TestUndoByID(t *testing.T) {
+	// Perform an operation in one directory, and then in another
+	firstDir := testutil.SetupWorkingDir(t, map[string]string{"a.txt": ""})
+	secondDir := t.TempDir()
+
+	steps := []testutil.TestCase{
+		{
+			Name: "rename a file in the first directory",
+			Args: []string{"-f", "a", "-r", "b", "-x"},
+		},
+		{
+			Name: "rename a file in the second directory",
+			Args: []string{"-f", "a", "-r", "b", "-x"},
+			SetupFunc: func(t *testing.T, _ string) func() {
+				t.Helper()
+
+				err := os.Chdir(secondDir)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				testutil.WriteFiles(t, map[string]string{"a.txt": ""})
+
+				return func() {}
+			},
+		},
+		{
+			Name: "list the history",
+			Args: []string{"--history", "--json"},
+		},
+		{
+			// The operation in the first directory is undone from the second
+			// one
+			Name: "undo the first operation",
+			Args: []string{"--undo-id", "2", "-x"},
+			Want: []string{filepath.Join(firstDir, "a.txt"), "b.txt"},
+		},
+		{
+			Name: "list the history after undoing",
+			Args: []string{"--history", "--json"},
+		},
+		{
+			Name:     "undo an unknown operation",
+			Args:     []string{"--undo-id", "3", "-x"},
+			ExitCode: f2.ExitError,
+		},
+	}
+
+	runSteps(t, steps)
+
+	entries := listHistory(t, &steps[2])
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 operations in the history, got %d", len(entries))
+	}
+
+	if entries[0].WorkingDir != secondDir || entries[1].WorkingDir != firstDir {
+		t.Fatalf("expected the most recent operation first, got %+v", entries)
+	}
+
+	entries = listHistory(t, &steps[4])
+	if entries[0].Undone || !entries[1].Undone {
+		t.Fatalf("expected only the second operation to be undone, got %+v", entries)
+	}
+
+	runSteps(t, []testutil.TestCase{
+		{
+			Name:     "undo an operation twice",
+			Args:     []string{"--undo-id", entries[1].ID, "-x"},
+			ExitCode: f2.ExitError,
+		},
+	})
+}
+
+func TestPruneHistory(t *testing.T) {
+	testutil.SetupWorkingDir(t, map[string]string{"a.txt": ""})
+
+	historyDir, err := config.HistoryDir()
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	config.Stderr = &stderr
+	// The backups are pruned by their names, so the ones that cannot be read
+	// are pruned as well
+	unreadable := filepath.Join(historyDir, "unreadable_1.json")
 
-	err = app.Run([]string{
-		"f2_test",
-		"-r",
-		"{x.cdt.YYYY}/{x.cdt.MM}-{x.cdt.MMM}/{x.cdt.YYYY}-{x.cdt.MM}-{x.cdt.DD}/{%03d}",
-		"-R",
-		"--target-dir",
-		".",
-		"--pair",
-		"--reset-index-per-dir",
-		"-F",
-		"--fix-conflicts-pattern",
-		"%03d",
-		"--sort",
-		"time_var",
-		"--sort-var",
-		"{x.cdt}",
-		"--pair-order",
-		"dng,jpg",
-		"--exclude",
-		"golden",
-		"testdata",
+	runSteps(t, []testutil.TestCase{
+		{
+			Name: "rename a file",
+			Args: []string{"-f", "a", "-r", "b", "-x"},
+		},
+		{
+			Name:      "rename the file again",
+			Args:      []string{"-f", "b", "-r", "c", "-x"},
+			SetupFunc: assertHistoryLen(2),
+		},
+		{
+			// The oldest operation is pruned automatically once the limit is
+			// exceeded
+			Name:      "exceed the history limit",
+			Args:      []string{"-f", "c", "-r", "d", "-x", "--history-limit", "2"},
+			SetupFunc: assertHistoryLen(2),
+		},
+		{
+			Name:        "prune by the number of operations",
+			Args:        []string{"--prune-history", "--history-limit", "1"},
+			SetupFunc:   writeFiles(map[string]string{unreadable: "{"}),
+			WantMissing: []string{unreadable},
+		},
+		{
+			Name:      "keep the operations within the limit",
+			Args:      []string{"--prune-history", "--history-limit", "1"},
+			SetupFunc: assertHistoryLen(1),
+		},
+		{
+			Name:      "prune by age",
+			Args:      []string{"--prune-history", "--history-max-age", "0s"},
+			SetupFunc: assertHistoryLen(0),
+		},
 	})
+}
+
+func TestUndoLast(t *testing.T) {
+	testutil.SetupWorkingDir(t, map[string]string{"a.txt": "", "x.txt": ""})
+
+	runSteps(t, []testutil.TestCase{
+		{
+			Name: "rename the first file",
+			Args: []string{"-f", "^a", "-r", "b", "-x"},
+		},
+		{
+			Name: "rename the second file",
+			Args: []string{"-f", "^x", "-r", "y", "-x"},
+		},
+		{
+			Name: "rename the first file again",
+			Args: []string{"-f", "^b", "-r", "c", "-x"},
+			Want: []string{"c.txt", "y.txt"},
+		},
+		{
+			Name:     "undo more operations than recorded",
+			Args:     []string{"--undo-last", "4", "-x"},
+			ExitCode: f2.ExitError,
+		},
+		{
+			// The renaming of b.txt to c.txt and x.txt to y.txt is undone
+			Name: "undo the last two operations",
+			Args: []string{"--undo-last", "2", "-x"},
+			Want: []string{"b.txt", "x.txt"},
+		},
+		{
+			// The remaining operation is undone next
+			Name: "undo the remaining operation",
+			Args: []string{"-u", "-x"},
+			Want: []string{"a.txt"},
+		},
+		{
+			// Each undone operation can be redone
+			Name: "redo the first operation",
+			Args: []string{"--redo", "-x"},
+		},
+		{
+			Name: "redo the second operation",
+			Args: []string{"--redo", "-x"},
+		},
+		{
+			Name: "redo the third operation",
+			Args: []string{"--redo", "-x"},
+			Want: []string{"c.txt", "y.txt"},
+		},
+		{
+			// The operations are combined so that c.txt is renamed back to
+			// a.txt
+			Name: "undo all the operations",
+			Args: []string{"--undo-last", "3", "-x"},
+			Want: []string{"a.txt", "x.txt"},
+		},
+	})
+}
+
+func TestUndoSwap(t *testing.T) {
+	testCases := []testutil.TestCase{
+		{
+			Name:        "undo swapped files",
+			Args:        []string{"-u", "-x"},
+			WantMissing: []string{"1.txt"},
+		},
+		{
+			Name: "undo swapped files along with an earlier operation",
+			Args: []string{"--undo-last", "2", "-x"},
+			Want: []string{"1.txt"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			testutil.SetupWorkingDir(t, map[string]string{
+				"1.txt":    "one",
+				"2.txt":    "two",
+				"3.txt":    "three",
+				"swap.csv": "2.txt,3.txt\n3.txt,2.txt\n",
+			})
+
+			// Each file gets back the name it had before it was swapped
+			tc.WantContents = map[string]string{
+				"2.txt": "two",
+				"3.txt": "three",
+			}
+
+			runSteps(t, []testutil.TestCase{
+				{
+					Name: "rename a file",
+					Args: []string{"-f", "1", "-r", "4", "-x"},
+				},
+				{
+					Name: "swap two files",
+					Args: []string{"--csv", "swap.csv", "-x"},
+				},
+				tc,
+			})
+		})
+	}
+}
+
+func TestUndoConflicts(t *testing.T) {
+	testutil.SetupWorkingDir(t, map[string]string{"a.txt": "renamed"})
+
+	runSteps(t, []testutil.TestCase{
+		{
+			Name: "rename a file",
+			Args: []string{"-f", "^a", "-r", "b", "-x"},
+		},
+		{
+			// A new file takes the original name after the renaming, so
+			// nothing is reverted
+			Name:      "undo when the original name is taken",
+			Args:      []string{"-u", "-x"},
+			SetupFunc: writeFiles(map[string]string{"a.txt": "new"}),
+			ExitCode:  f2.ExitConflicts,
+			WantContents: map[string]string{
+				"a.txt": "new",
+				"b.txt": "renamed",
+			},
+		},
+		{
+			Name: "undo with --fix-conflicts",
+			Args: []string{"-u", "-x", "-F"},
+			WantContents: map[string]string{
+				"a.txt":    "new",
+				"a(1).txt": "renamed",
+			},
+		},
+		{
+			// The operation is redone from the name that the file was
+			// restored to
+			Name: "redo the operation",
+			Args: []string{"--redo", "-x"},
+			WantContents: map[string]string{
+				"a.txt": "new",
+				"b.txt": "renamed",
+			},
+		},
+	})
+}
+
+func TestUndoUnknownOperation(t *testing.T) {
+	testutil.SetupWorkingDir(t, map[string]string{"a.txt": ""})
+
+	runSteps(t, []testutil.TestCase{
+		{
+			Name:      "rename a file",
+			Args:      []string{"-f", "^a", "-r", "b", "-x"},
+			SetupFunc: assertHistoryLen(1),
+		},
+		{
+			// An operation recorded by a newer version is not reverted as a
+			// renaming
+			Name: "undo an unknown operation",
+			Args: []string{"-u", "-x"},
+			SetupFunc: func(t *testing.T, _ string) func() {
+				t.Helper()
+
+				history, err := config.History()
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				backup, err := config.ReadBackup(history[0].Path)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				backup.Operation = "unknown"
+
+				err = config.WriteBackup(history[0].Path, backup)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				return func() {}
+			},
+			ExitCode: f2.ExitError,
+			Want:     []string{"b.txt"},
+		},
+	})
+}
+
+func TestCopy(t *testing.T) {
+	testutil.SetupWorkingDir(t, map[string]string{"a.txt": "original"})
+
+	runSteps(t, []testutil.TestCase{
+		{
+			Name: "copy a file",
+			Args: []string{"-f", "^a", "-r", "b", "--copy", "-x"},
+			WantContents: map[string]string{
+				"a.txt": "original",
+				"b.txt": "original",
+			},
+		},
+		{
+			// Undoing the copy deletes it
+			Name:         "undo the copy",
+			Args:         []string{"-u", "-x"},
+			WantContents: map[string]string{"a.txt": "original"},
+			WantMissing:  []string{"b.txt"},
+		},
+		{
+			// Redoing the operation copies the file again
+			Name: "redo the copy",
+			Args: []string{"--redo", "-x"},
+			WantContents: map[string]string{
+				"a.txt": "original",
+				"b.txt": "original",
+			},
+		},
+		{
+			// A copy that was modified afterwards is kept
+			Name:         "undo a modified copy",
+			Args:         []string{"-u", "-x"},
+			SetupFunc:    writeFiles(map[string]string{"b.txt": "edited"}),
+			ExitCode:     f2.ExitPartialFailure,
+			WantContents: map[string]string{"b.txt": "edited"},
+		},
+	})
+}
+
+func TestCopyPreserve(t *testing.T) {
+	testutil.SetupWorkingDir(t, map[string]string{"a.txt": "original"})
+
+	modTime := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	err := os.Chtimes("a.txt", modTime, modTime)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	tc := &testutil.TestCase{
-		Name: "image pair renaming",
+	runSteps(t, []testutil.TestCase{
+		{
+			Name: "copy a file",
+			Args: []string{"-f", "^a", "-r", "b", "--copy", "-x"},
+		},
+		{
+			Name: "copy a file with --no-preserve",
+			Args: []string{"-f", "^a", "-r", "c", "--copy", "--no-preserve", "-x"},
+		},
+	})
+
+	info, err := os.Stat("b.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !info.ModTime().Equal(modTime) {
+		t.Fatalf("expected the copy to keep the modification time %v, got %v", modTime, info.ModTime())
 	}
 
-	tc.SnapShot.Stdout = stdout.Bytes()
-	tc.SnapShot.Stderr = stderr.Bytes()
+	if runtime.GOOS != osutil.Windows && info.Mode().Perm() != 0o600 {
+		t.Fatalf("expected the copy to keep the permissions, got %v", info.Mode().Perm())
+	}
 
-	testutil.CompareGoldenFile(t, tc)
+	info, err = os.Stat("c.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.ModTime().Equal(modTime) {
+		t.Fatal("expected the copy to get the current modification time")
+	}
+}
+
+func TestLink(t *testing.T) {
+	testCases := []struct {
+		name   string
+		link   string
+		isLink func(info os.FileInfo, original os.FileInfo) bool
+	}{
+		{
+			name: "create symbolic links",
+			link: "sym",
+			isLink: func(info, _ os.FileInfo) bool {
+				return info.Mode()&os.ModeSymlink != 0
+			},
+		},
+		{
+			name: "create hard links",
+			link: "hard",
+			isLink: func(info, original os.FileInfo) bool {
+				return os.SameFile(info, original)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testutil.SetupWorkingDir(t, map[string]string{"a.txt": "original"})
+
+			link := filepath.Join("links", "b.txt")
+
+			runSteps(t, []testutil.TestCase{
+				{
+					Name: "link a file",
+					Args: []string{
+						"-f", "^a", "-r", "links/b", "--link", tc.link, "-x",
+					},
+					WantContents: map[string]string{link: "original"},
+					SetupFunc: func(t *testing.T, _ string) func() {
+						t.Helper()
+
+						return func() {
+							info, err := os.Lstat(link)
+							if err != nil {
+								t.Fatal(err)
+							}
+
+							original, err := os.Lstat("a.txt")
+							if err != nil {
+								t.Fatal(err)
+							}
+
+							if !tc.isLink(info, original) {
+								t.Fatal("expected the target to be a link to the source")
+							}
+						}
+					},
+				},
+				{
+					// Undoing the operation deletes the link
+					Name:        "undo the operation",
+					Args:        []string{"-u", "-x"},
+					WantMissing: []string{link},
+				},
+				{
+					Name: "redo the operation",
+					Args: []string{"--redo", "-x"},
+				},
+				{
+					// A link that was replaced with a regular file is kept
+					Name: "undo a replaced link",
+					Args: []string{"-u", "-x"},
+					SetupFunc: func(t *testing.T, _ string) func() {
+						t.Helper()
+
+						err := os.Remove(link)
+						if err != nil {
+							t.Fatal(err)
+						}
+
+						testutil.WriteFiles(t, map[string]string{link: "new"})
+
+						return func() {}
+					},
+					WantContents: map[string]string{link: "new"},
+				},
+			})
+		})
+	}
+}
+
+func TestRollbackOnError(t *testing.T) {
+	testutil.SetupWorkingDir(t, map[string]string{
+		"a.txt": "",
+		"b.txt": "",
+		"dir/":  "",
+	})
+
+	runSteps(t, []testutil.TestCase{
+		{
+			// The files are renamed before the directory, which cannot be
+			// moved inside itself
+			Name: "roll back a failed operation",
+			Args: []string{
+				"-f", "^(a|b|dir)", "-r", "x$1",
+				"-f", "^xdir", "-r", "dir/sub/dir",
+				"-d", "-x", "--on-error", "rollback",
+			},
+			SetupFunc: assertHistoryLen(0),
+			ExitCode:  f2.ExitPartialFailure,
+			Want:      []string{"a.txt", "b.txt", "dir"},
+			WantMissing: []string{
+				"xa.txt",
+				"xb.txt",
+				filepath.Join("dir", "sub"),
+			},
+		},
+	})
+}
+
+func TestContinueOnError(t *testing.T) {
+	testutil.SetupWorkingDir(t, map[string]string{"a.txt": "", "dir/": ""})
+
+	steps := []testutil.TestCase{
+		{
+			// The directory cannot be moved inside itself, but the file is
+			// still renamed
+			Name: "continue after a failed rename",
+			Args: []string{
+				"-f", "^(a|dir)", "-r", "x$1",
+				"-f", "^xdir", "-r", "dir/sub/dir",
+				"-d", "-x",
+			},
+			ExitCode: f2.ExitPartialFailure,
+			Want:     []string{"xa.txt"},
+		},
+	}
+
+	runSteps(t, steps)
+
+	stderr := string(steps[0].SnapShot.Stderr)
+	if !strings.Contains(stderr, "1 of 2 file(s) could not be renamed") {
+		t.Fatalf("expected a summary of the failures, got %q", stderr)
+	}
+
+	history, err := config.History()
+	if err != nil || len(history) != 1 {
+		t.Fatalf("expected one operation in the history, got %d (%v)", len(history), err)
+	}
+
+	backup, err := config.ReadBackup(history[0].Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(backup.Changes) != 1 || backup.Changes[0].Target != "xa.txt" {
+		t.Fatalf("expected only the renamed file in the history, got %v", backup.Changes)
+	}
+}
+
+func TestWorkers(t *testing.T) {
+	files := map[string]string{"dir/": ""}
+
+	for i := range 50 {
+		name := fmt.Sprintf("file%02d.txt", i)
+		files[name] = name
+	}
+
+	// The file is moved along with the directory
+	files[filepath.Join("dir", "other.txt")] = filepath.Join("dir", "other.txt")
+
+	testutil.SetupWorkingDir(t, files)
+
+	renamed := make(map[string]string)
+	restored := make(map[string]string)
+
+	for name, contents := range files {
+		if name != "dir/" {
+			renamed["x"+name] = contents
+			restored[name] = contents
+		}
+	}
+
+	runSteps(t, []testutil.TestCase{
+		{
+			Name: "rename with workers",
+			Args: []string{
+				"-f", "^(file|dir)", "-r", "x$1", "-d", "-x", "--workers", "8",
+			},
+			WantContents: renamed,
+		},
+		{
+			Name:         "undo with workers",
+			Args:         []string{"-u", "-x", "--workers", "8"},
+			WantContents: restored,
+		},
+	})
+}
+
+func TestGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	testutil.SetupWorkingDir(t, map[string]string{"a.txt": "", "a.log": ""})
+
+	git := func(args ...string) string {
+		t.Helper()
+
+		out, err := exec.Command("git", args...).CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %s: %v: %s", args[0], err, out)
+		}
+
+		return string(out)
+	}
+
+	git("init", "-q")
+	git("add", "a.txt")
+	git(
+		"-c", "user.name=f2", "-c", "user.email=f2@example.com",
+		"-c", "commit.gpgsign=false", "commit", "-qm", "init",
+	)
+
+	runSteps(t, []testutil.TestCase{
+		{
+			Name: "rename with --git",
+			Args: []string{"-f", "^a", "-r", "b", "--git", "-x"},
+		},
+	})
+
+	// The tracked file is renamed in the index, while the untracked file is
+	// renamed without being added
+	status := git("status", "--porcelain")
+
+	for _, want := range []string{"R  a.txt -> b.txt", "?? b.log"} {
+		if !strings.Contains(status, want) {
+			t.Fatalf("expected the status to contain %q, got:\n%s", want, status)
+		}
+	}
+}
+
+func TestWatch(t *testing.T) {
+	// The SetupFunc creates the files that exist before watching, and the
+	// sources of the changes are created while watching. The targets are
+	// the paths that they are renamed to, which are the same as the sources
+	// for the files that are left alone.
+	testCases := []testutil.TestCase{
+		{
+			Name:      "rename new files",
+			SetupFunc: writeFiles(map[string]string{"old_a.txt": ""}),
+			Changes: file.Changes{
+				{Source: "new_b.txt", Target: "done_b.txt"},
+			},
+			Want: []string{"old_a.txt"},
+		},
+		{
+			Name:      "skip new files in excluded directories",
+			Args:      []string{"-R", "--exclude-dir", "skip"},
+			SetupFunc: writeFiles(map[string]string{"keep/": "", "skip/": ""}),
+			Changes: file.Changes{
+				{Source: "skip/new_a.txt", Target: "skip/new_a.txt"},
+				{Source: "keep/new_b.txt", Target: "keep/done_b.txt"},
+			},
+		},
+		{
+			Name:      "skip new files below --max-depth",
+			Args:      []string{"-R", "--max-depth", "1"},
+			SetupFunc: writeFiles(map[string]string{"a/b/": ""}),
+			Changes: file.Changes{
+				{Source: "a/b/new_a.txt", Target: "a/b/new_a.txt"},
+				{Source: "a/new_b.txt", Target: "a/done_b.txt"},
+			},
+		},
+		{
+			Name:      "skip new files that are ignored",
+			SetupFunc: writeFiles(map[string]string{".f2ignore": "new_a.txt\n"}),
+			Changes: file.Changes{
+				{Source: "new_a.txt", Target: "new_a.txt"},
+				{Source: "new_b.txt", Target: "done_b.txt"},
+			},
+		},
+		{
+			Name: "skip new hidden files",
+			Changes: file.Changes{
+				{Source: ".new_a.txt", Target: ".new_a.txt"},
+				{Source: "new_b.txt", Target: "done_b.txt"},
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := &testCases[i]
+
+		t.Run(tc.Name, func(t *testing.T) {
+			testutil.SetupWorkingDir(t, nil)
+
+			if tc.SetupFunc != nil {
+				t.Cleanup(tc.SetupFunc(t, "."))
+			}
+
+			tc.Args = append([]string{
+				"-f", "(old|new)_", "-r", "done_", "--watch", "-x",
+			}, tc.Args...)
+
+			ctx, cancel := context.WithCancel(context.Background())
+
+			done := make(chan error)
+
+			go func() {
+				done <- testutil.RunApp(ctx, f2.New, tc)
+			}()
+
+			// Give the watcher time to start before creating the files
+			time.Sleep(200 * time.Millisecond)
+
+			for _, ch := range tc.Changes {
+				err := os.WriteFile(filepath.FromSlash(ch.Source), nil, 0o600)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			deadline := time.Now().Add(5 * time.Second)
+
+			for _, ch := range tc.Changes {
+				if ch.Target == ch.Source {
+					continue
+				}
+
+				for {
+					if _, err := os.Stat(filepath.FromSlash(ch.Target)); err == nil {
+						break
+					}
+
+					if time.Now().After(deadline) {
+						t.Fatalf("expected a new file to be renamed to %s", ch.Target)
+					}
+
+					time.Sleep(50 * time.Millisecond)
+				}
+			}
+
+			// The lock is only held while a batch is renamed, so other
+			// operations can run in the watched directory
+			for {
+				unlock, err := config.Lock([]string{"."})
+				if err == nil {
+					unlock()
+					break
+				}
+
+				if time.Now().After(deadline) {
+					t.Fatal(err)
+				}
+
+				time.Sleep(50 * time.Millisecond)
+			}
+
+			cancel()
+
+			if err := <-done; err != nil {
+				t.Fatal(err)
+			}
+
+			// The files are renamed together, so the others are left alone
+			for _, ch := range tc.Changes {
+				if _, err := os.Stat(filepath.FromSlash(ch.Target)); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			for _, path := range tc.Want {
+				if _, err := os.Stat(path); err != nil {
+					t.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestPruneEmpty(t *testing.T) {
+	nested := filepath.Join("src", "a", "b")
+	kept := filepath.Join("src", "keep")
+
+	testutil.SetupWorkingDir(t, map[string]string{
+		filepath.Join(nested, "file.txt"): "",
+		filepath.Join(kept, "file.txt"):   "",
+		// The directory still has a file that is not matched after renaming
+		filepath.Join(kept, "file.md"): "",
+	})
+
+	args := []string{
+		"-f", `file\.txt`, "-r", "{p}.txt", "-t", "out", "-R", "--prune-empty", "src",
+	}
+
+	steps := []testutil.TestCase{
+		{
+			Name: "preview the removed directories",
+			Args: args,
+		},
+		{
+			Name:        "remove the emptied directories",
+			Args:        append([]string{"-x"}, args...),
+			Want:        []string{kept},
+			WantMissing: []string{filepath.Join("src", "a")},
+		},
+		{
+			// The removed directories are recreated on undo
+			Name: "undo the operation",
+			Args: []string{"-u", "-x"},
+			Want: []string{filepath.Join(nested, "file.txt")},
+		},
+	}
+
+	runSteps(t, steps)
+
+	output := string(steps[0].SnapShot.Stderr)
+
+	if !strings.Contains(output, "2 empty director(ies) will be removed") ||
+		!strings.Contains(output, nested) {
+		t.Fatalf("expected the emptied directories in the preview, got %q", output)
+	}
+
+	if strings.Contains(output, kept) {
+		t.Fatalf("expected %s to be kept in the preview, got %q", kept, output)
+	}
+}
+
+func TestTrash(t *testing.T) {
+	if runtime.GOOS == osutil.Windows || runtime.GOOS == osutil.Darwin {
+		t.Skip("the trash of the current user would be used")
+	}
+
+	testutil.SetupWorkingDir(t, map[string]string{"a.txt": "new", "b.txt": "old"})
+
+	dataHome := t.TempDir()
+	t.Setenv(trash.EnvDataHome, dataHome)
+
+	info := filepath.Join(dataHome, "Trash", "info", "b.txt.trashinfo")
+
+	runSteps(t, []testutil.TestCase{
+		{
+			// The details of the trashed file are recorded
+			Name: "trash an overwritten file",
+			Args: []string{"-f", "a", "-r", "b", "--allow-overwrites", "--trash", "-x"},
+			WantContents: map[string]string{
+				filepath.Join(dataHome, "Trash", "files", "b.txt"): "old",
+			},
+			Want: []string{info},
+		},
+		{
+			// The details of the restored file are removed
+			Name: "restore the trashed file on undo",
+			Args: []string{"-u", "-x"},
+			WantContents: map[string]string{
+				"a.txt": "new",
+				"b.txt": "old",
+			},
+			WantMissing: []string{info},
+		},
+	})
+}
+
+func TestBackup(t *testing.T) {
+	testutil.SetupWorkingDir(t, map[string]string{"a.txt": "new", "b.txt": "old"})
+
+	runSteps(t, []testutil.TestCase{
+		{
+			Name: "back up an overwritten file",
+			Args: []string{
+				"-f", "^a", "-r", "b", "--allow-overwrites", "--backup", ".bak", "-x",
+			},
+			WantContents: map[string]string{
+				"b.txt":     "new",
+				"b.txt.bak": "old",
+			},
+		},
+		{
+			// The backup is renamed back on undo
+			Name: "undo the operation",
+			Args: []string{"-u", "-x"},
+			WantContents: map[string]string{
+				"a.txt": "new",
+				"b.txt": "old",
+			},
+			WantMissing: []string{"b.txt.bak"},
+		},
+		{
+			Name: "number the backups",
+			Args: []string{
+				"-f", "^a", "-r", "b", "--allow-overwrites", "--backup", "existing", "-x",
+			},
+			SetupFunc: writeFiles(map[string]string{"b.txt.~1~": "older"}),
+			WantContents: map[string]string{
+				"b.txt":     "new",
+				"b.txt.~2~": "old",
+				"b.txt.~1~": "older",
+			},
+		},
+	})
+}
+
+func TestLock(t *testing.T) {
+	testutil.SetupWorkingDir(t, map[string]string{"a.txt": "", "other/": ""})
+
+	absDir, err := filepath.Abs(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// lock returns a setup function that holds the lock on the paths while a
+	// test case runs, as if another operation were in progress in them
+	lock := func(paths ...string) func(t *testing.T, testDir string) func() {
+		return func(t *testing.T, _ string) func() {
+			t.Helper()
+
+			unlock, err := config.Lock(paths)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			return unlock
+		}
+	}
+
+	errLocked := errors.New("another renaming operation")
+
+	runSteps(t, []testutil.TestCase{
+		{
+			Name:      "run while another directory is locked",
+			Args:      []string{"-f", "a", "-r", "a", "-x"},
+			SetupFunc: lock("other"),
+		},
+		{
+			Name:      "fail while the directory is locked",
+			Args:      []string{"-f", "a", "-r", "b", "-x"},
+			SetupFunc: lock(absDir),
+			ExitCode:  f2.ExitError,
+			Error:     errLocked,
+			Want:      []string{"a.txt"},
+		},
+		{
+			// Previews do not need the lock
+			Name:      "preview while the directory is locked",
+			Args:      []string{"-f", "a", "-r", "b"},
+			SetupFunc: lock(absDir),
+		},
+		{
+			Name:      "skip the lock with --no-lock",
+			Args:      []string{"-f", "a", "-r", "b", "--no-lock", "-x"},
+			SetupFunc: lock(absDir),
+			Want:      []string{"b.txt"},
+		},
+		{
+			// The lock covers the directory of a searched file
+			Name: "fail while the directory of a file is locked",
+			Args: []string{
+				"-f", "b", "-r", "c", "-x", filepath.Join(absDir, "b.txt"),
+			},
+			SetupFunc: lock(absDir),
+			ExitCode:  f2.ExitError,
+			Error:     errLocked,
+		},
+		{
+			Name: "run after the lock is released",
+			Args: []string{"-f", "b", "-r", "c", "-x"},
+			Want: []string{"c.txt"},
+		},
+	})
+}
+
+func TestThrottle(t *testing.T) {
+	files := make(map[string]string)
+
+	for i := range 5 {
+		files[fmt.Sprintf("a%d.txt", i)] = ""
+	}
+
+	testutil.SetupWorkingDir(t, files)
+
+	var renamed []string
+
+	for i := range 5 {
+		renamed = append(renamed, fmt.Sprintf("b%d.txt", i))
+	}
+
+	runSteps(t, []testutil.TestCase{
+		{
+			Name:     "reject an invalid --throttle value",
+			Args:     []string{"-f", "a", "-r", "b", "--throttle", "bad", "-x"},
+			ExitCode: f2.ExitError,
+		},
+		{
+			Name: "throttle the renames",
+			Args: []string{
+				"-f", "a", "-r", "b", "--throttle", "50/s", "--workers", "4", "-x",
+			},
+			SetupFunc: func(t *testing.T, _ string) func() {
+				t.Helper()
+
+				start := time.Now()
+
+				return func() {
+					// The first rename is not delayed
+					if elapsed := time.Since(start); elapsed < 80*time.Millisecond {
+						t.Fatalf("expected the renames to be spread over at least 80ms, took %v", elapsed)
+					}
+				}
+			},
+			Want: renamed,
+		},
+	})
+}
+
+func TestTwoPhase(t *testing.T) {
+	files := make(map[string]string)
+	renamed := make(map[string]string)
+
+	for i := 1; i <= 3; i++ {
+		files[fmt.Sprintf("%d.txt", i)] = strconv.Itoa(i)
+		renamed[fmt.Sprintf("%d.txt", i+1)] = strconv.Itoa(i)
+	}
+
+	testutil.SetupWorkingDir(t, files)
+
+	runSteps(t, []testutil.TestCase{
+		{
+			// Each target is the source of the next file
+			Name:         "rename in two phases",
+			Args:         []string{"-f", `^\d`, "-r", "{2%d}", "--two-phase", "-x"},
+			WantContents: renamed,
+			SetupFunc: func(t *testing.T, _ string) func() {
+				t.Helper()
+
+				return func() {
+					entries, err := os.ReadDir(".")
+					if err != nil {
+						t.Fatal(err)
+					}
+
+					if len(entries) != 3 {
+						t.Fatalf("expected no temporary files to be left, found %d entries", len(entries))
+					}
+				}
+			},
+		},
+		{
+			Name:         "undo in two phases",
+			Args:         []string{"-u", "--two-phase", "-x"},
+			WantContents: files,
+		},
+	})
+}
+
+func TestDebug(t *testing.T) {
+	testutil.SetupWorkingDir(t, map[string]string{
+		"a.txt": "",
+		"a.md":  "",
+		"b.txt": "",
+	})
+
+	steps := []testutil.TestCase{
+		{
+			Name: "print debug output",
+			Args: []string{"-f", "a", "-r", "{%d}", "--ext", "txt", "--debug", "-x"},
+		},
+	}
+
+	runSteps(t, steps)
+
+	stderr := string(steps[0].SnapShot.Stderr)
+
+	for _, want := range []string{
+		"debug: searching '.'",
+		"debug: skipping 'a.md' (extension not in --ext)",
+		"debug: skipping 'b.txt' (does not match)",
+		"debug: matched 'a.txt'",
+		"debug: resolved the variables in '{%d}.txt' to '1.txt' for 'a.txt'",
+		"debug: renamed 'a.txt' to '1.txt'",
+	} {
+		if !strings.Contains(stderr, want) {
+			t.Errorf("expected the debug output to contain %q, got:\n%s", want, stderr)
+		}
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	testutil.SetupWorkingDir(t, map[string]string{
+		"a.txt": "",
+		"b.txt": "",
+		"dir/":  "",
+	})
+
+	runSteps(t, []testutil.TestCase{
+		{
+			Name:     "invalid option",
+			Args:     []string{"-f", "a", "--sort", "unknown"},
+			ExitCode: f2.ExitError,
+		},
+		{
+			Name:     "no matches",
+			Args:     []string{"-f", "z", "-r", "y"},
+			ExitCode: f2.ExitNoMatches,
+		},
+		{
+			Name:     "conflicts",
+			Args:     []string{"-f", "(a|b)", "-r", "c"},
+			ExitCode: f2.ExitConflicts,
+		},
+		{
+			Name:     "pending changes",
+			Args:     []string{"-f", "a", "-r", "c", "--check"},
+			ExitCode: f2.ExitPendingChanges,
+		},
+		{
+			Name:     "dry run",
+			Args:     []string{"-f", "a", "-r", "c"},
+			ExitCode: f2.ExitOK,
+		},
+		{
+			// The directory cannot be moved inside itself
+			Name: "partial failure",
+			Args: []string{
+				"-f", "^(a|dir)", "-r", "x$1",
+				"-f", "^xdir", "-r", "dir/sub/dir",
+				"-d", "-x",
+			},
+			ExitCode: f2.ExitPartialFailure,
+		},
+	})
+}
+
+func TestInteractive(t *testing.T) {
+	testCases := []testutil.TestCase{
+		{
+			Name:  "answer each change",
+			Stdin: "y\nn\nmaybe\na\n",
+			Want:  []string{"a.md", "b.txt", "c.md", "d.md"},
+		},
+		{
+			Name:  "quit after the first change",
+			Stdin: "yes\nq\n",
+			Want:  []string{"a.md", "b.txt", "c.txt", "d.txt"},
+		},
+		{
+			Name:  "skip on empty answer and end of input",
+			Stdin: "\ny",
+			Want:  []string{"a.txt", "b.md", "c.txt", "d.txt"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			testutil.SetupWorkingDir(t, map[string]string{
+				"a.txt": "",
+				"b.txt": "",
+				"c.txt": "",
+				"d.txt": "",
+			})
+
+			tc.Args = []string{"-f", "txt", "-r", "md", "--interactive", "-x"}
+
+			steps := []testutil.TestCase{tc}
+
+			runSteps(t, steps)
+
+			stderr := string(steps[0].SnapShot.Stderr)
+			if !strings.Contains(stderr, "rename 'a.txt' to 'a.md'?") {
+				t.Fatalf("expected a confirmation prompt, got %q", stderr)
+			}
+		})
+	}
 }
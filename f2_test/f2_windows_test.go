@@ -0,0 +1,30 @@
+//go:build windows
+// +build windows
+
+package f2_test
+
+import (
+	"testing"
+
+	"github.com/ayoisaiah/f2/v2"
+	"github.com/ayoisaiah/f2/v2/internal/testutil"
+)
+
+// TestUnsupportedFlagsWindows ensures that the flags which cannot take effect
+// on Windows are rejected instead of being ignored.
+func TestUnsupportedFlagsWindows(t *testing.T) {
+	testutil.SetupWorkingDir(t, map[string]string{"a.txt": ""})
+
+	runSteps(t, []testutil.TestCase{
+		{
+			Name:     "one file system",
+			Args:     []string{"-f", "a", "-r", "b", "-R", "--one-file-system"},
+			ExitCode: f2.ExitError,
+		},
+		{
+			Name:     "owned by",
+			Args:     []string{"-f", "a", "-r", "b", "--owned-by", "0"},
+			ExitCode: f2.ExitError,
+		},
+	})
+}
@@ -3,6 +3,7 @@ package find
 import (
 	"bufio"
 	"encoding/csv"
+	"encoding/json"
 	"errors"
 	"os"
 	"path/filepath"
@@ -34,6 +35,34 @@ func readCSVFile(pathToCSV string) ([][]string, error) {
 	return records, nil
 }
 
+// readJSONFile reads a JSON array of {"source": "...", "target": "..."}
+// objects from the file specified by `pathToJSON`, and converts each object
+// into a record with the same layout as a row in a CSV file.
+func readJSONFile(pathToJSON string) ([][]string, error) {
+	fileBytes, err := os.ReadFile(pathToJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	var pairs []struct {
+		Source string `json:"source"`
+		Target string `json:"target"`
+	}
+
+	err = json.Unmarshal(fileBytes, &pairs)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([][]string, len(pairs))
+
+	for i := range pairs {
+		records[i] = []string{pairs[i].Source, pairs[i].Target}
+	}
+
+	return records, nil
+}
+
 // handleCSV reads the provided CSV file, and finds all the valid candidates
 // for renaming.
 func handleCSV(conf *config.Config) (file.Changes, error) {
@@ -41,7 +70,16 @@ func handleCSV(conf *config.Config) (file.Changes, error) {
 
 	var changes file.Changes
 
-	records, err := readCSVFile(conf.CSVFilename)
+	var records [][]string
+
+	var err error
+
+	if strings.EqualFold(filepath.Ext(conf.CSVFilename), ".json") {
+		records, err = readJSONFile(conf.CSVFilename)
+	} else {
+		records, err = readCSVFile(conf.CSVFilename)
+	}
+
 	if err != nil {
 		return nil, err
 	}
@@ -104,6 +142,8 @@ func handleCSV(conf *config.Config) (file.Changes, error) {
 			SourcePath:   filepath.Join(sourceDir, fileName),
 			CSVRow:       record,
 			Position:     i,
+			Size:         fileInfo.Size(),
+			ModTime:      fileInfo.ModTime(),
 		}
 
 		if conf.TargetDir != "" {
@@ -115,7 +155,6 @@ func handleCSV(conf *config.Config) (file.Changes, error) {
 
 			if filepath.IsAbs(match.Target) {
 				match.TargetDir = ""
-				continue
 			}
 		}
 
@@ -1,48 +1,167 @@
 package find
 
 import (
-	"encoding/json"
 	"errors"
 	"io/fs"
+	"maps"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/araddon/dateparse"
+	"gopkg.in/djherbis/times.v1"
 
 	"github.com/ayoisaiah/f2/v2/internal/config"
 	"github.com/ayoisaiah/f2/v2/internal/file"
+	"github.com/ayoisaiah/f2/v2/internal/fuzzy"
 	"github.com/ayoisaiah/f2/v2/internal/osutil"
 	"github.com/ayoisaiah/f2/v2/internal/pathutil"
 	"github.com/ayoisaiah/f2/v2/internal/sortfiles"
 	"github.com/ayoisaiah/f2/v2/internal/status"
+	"github.com/ayoisaiah/f2/v2/internal/timeutil"
 	"github.com/ayoisaiah/f2/v2/replace/variables"
+	"github.com/ayoisaiah/f2/v2/report"
 )
 
 const (
 	dotCharacter = 46
 )
 
-var vars variables.Variables
+var (
+	vars variables.Variables
+	// whereVars holds the variables in each --where predicate
+	whereVars []variables.Variables
+)
+
+// getFilterTime returns the file time attribute used by the --newer-than and
+// --older-than filters. It falls back to the modification time if the
+// attribute is not supported on the current platform.
+func getFilterTime(conf *config.Config, fileInfo fs.FileInfo) time.Time {
+	timeSpec := times.Get(fileInfo)
+
+	//nolint:exhaustive // mtime is the default
+	switch conf.FilterTime {
+	case timeutil.Birth:
+		if timeSpec.HasBirthTime() {
+			return timeSpec.BirthTime()
+		}
+	case timeutil.Access:
+		return timeSpec.AccessTime()
+	case timeutil.Change:
+		if timeSpec.HasChangeTime() {
+			return timeSpec.ChangeTime()
+		}
+	}
+
+	return fileInfo.ModTime()
+}
+
+// isMatch reports whether the file name matches the search pattern. In
+// --invert mode, file names that match the find pattern are skipped. In
+// --fuzzy mode, the find string only needs to approximately match part of the
+// file name.
+func isMatch(conf *config.Config, fileName string) bool {
+	if conf.InvertRegex != nil && conf.InvertRegex.MatchString(fileName) {
+		return false
+	}
+
+	if conf.Search.Fuzzy != "" {
+		_, _, ok := fuzzy.Find(
+			conf.Search.Fuzzy,
+			fileName,
+			conf.FuzzyDistance,
+			conf.IgnoreCase,
+		)
+
+		return ok
+	}
+
+	return conf.Search.Regex.MatchString(fileName)
+}
+
+// hasAllowedExt reports whether the file name ends with one of the extensions
+// provided through --ext. Comparisons are case insensitive.
+func hasAllowedExt(conf *config.Config, fileName string) bool {
+	fileName = strings.ToLower(fileName)
+
+	for _, ext := range conf.Extensions {
+		if strings.HasSuffix(fileName, "."+ext) {
+			return true
+		}
+	}
 
-// shouldFilter decides whether a match should be included in the final
-// pool of files for renaming.
-func shouldFilter(conf *config.Config, match *file.Change) bool {
+	return false
+}
+
+// filterReason decides whether a match should be included in the final
+// pool of files for renaming. It returns the reason why the match is
+// filtered out, or an empty string if it is included.
+func filterReason(
+	conf *config.Config,
+	match *file.Change,
+	fileInfo fs.FileInfo,
+) string {
 	if conf.ExcludeRegex != nil &&
 		conf.ExcludeRegex.MatchString(match.Source) {
-		return true
+		return "matches --exclude"
+	}
+
+	if len(conf.Extensions) > 0 && !match.IsDir &&
+		!hasAllowedExt(conf, match.Source) {
+		return "extension not in --ext"
+	}
+
+	// Size filters do not apply to directories
+	if !match.IsDir {
+		if conf.MinSize > 0 && fileInfo.Size() < conf.MinSize {
+			return "smaller than --min-size"
+		}
+
+		if conf.MaxSize > 0 && fileInfo.Size() > conf.MaxSize {
+			return "larger than --max-size"
+		}
+	}
+
+	if !conf.NewerThan.IsZero() || !conf.OlderThan.IsZero() {
+		fileTime := getFilterTime(conf, fileInfo)
+
+		if !conf.NewerThan.IsZero() && fileTime.Before(conf.NewerThan) {
+			return "older than --newer-than"
+		}
+
+		if !conf.OlderThan.IsZero() && fileTime.After(conf.OlderThan) {
+			return "newer than --older-than"
+		}
+	}
+
+	if conf.OwnedBy != "" {
+		// A file whose owner cannot be determined is not a match
+		if owner, ok := fileOwner(fileInfo); !ok || owner != conf.OwnedBy {
+			return "not owned by the --owned-by user"
+		}
+	}
+
+	if conf.WritableOnly && !isWritable(match.SourcePath, fileInfo) {
+		return "not writable with --writable-only"
 	}
 
 	if !conf.IncludeDir && match.IsDir {
-		return true
+		return "directories are not included without -d"
+	}
+
+	// Directories have no contents to search
+	if conf.GrepRegex != nil &&
+		(match.IsDir || !matchesContent(conf, match.SourcePath)) {
+		return "contents do not match --grep"
 	}
 
 	if conf.OnlyDir && !match.IsDir {
-		return true
+		return "not a directory with --only-dir"
 	}
 
-	return false
+	return ""
 }
 
 // skipFileIfHidden checks if a file is hidden, and if so, returns a boolean
@@ -95,15 +214,72 @@ func isMaxDepth(rootPath, currentPath string, maxDepth int) bool {
 		return true
 	}
 
-	relativePath := strings.TrimPrefix(
-		currentPath,
-		rootPath+string(os.PathSeparator),
-	)
+	// filepath.Rel is used instead of trimming the root path prefix so that
+	// roots such as "/" or "C:\" (which end with a separator) are handled
+	relativePath, err := filepath.Rel(rootPath, currentPath)
+	if err != nil {
+		return false
+	}
+
 	depthCount := strings.Count(relativePath, string(os.PathSeparator))
 
 	return depthCount > maxDepth
 }
 
+// entryDepth returns the level of the current path below the root path.
+// Entries directly inside the root path are at depth 1.
+func entryDepth(rootPath, currentPath string) int {
+	relativePath, err := filepath.Rel(rootPath, currentPath)
+	if err != nil {
+		return 0
+	}
+
+	return strings.Count(relativePath, string(os.PathSeparator)) + 1
+}
+
+// isOtherDevice reports whether the directory is on a different filesystem
+// from the root directory in --one-file-system mode.
+func isOtherDevice(
+	conf *config.Config,
+	dirInfo fs.FileInfo,
+	rootDevice uint64,
+) bool {
+	if !conf.OneFileSystem {
+		return false
+	}
+
+	device, ok := deviceID(dirInfo)
+
+	return ok && device != rootDevice
+}
+
+// matchesWhere reports whether the match satisfies all the --where
+// predicates.
+func matchesWhere(conf *config.Config, ch *file.Change) (bool, error) {
+	defer func() {
+		ch.Target = ""
+	}()
+
+	for i := range conf.Where {
+		predicate := &conf.Where[i]
+
+		// Temporarily set Target to the predicate variable due to how
+		// variables.Replace() works
+		ch.Target = predicate.Variable
+
+		err := variables.Replace(conf, ch, &whereVars[i])
+		if err != nil {
+			return false, err
+		}
+
+		if !predicate.Match(ch.Target) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
 func extractCustomSort(
 	conf *config.Config,
 	ch *file.Change,
@@ -154,9 +330,12 @@ func createFileChange(
 		BaseDir:      baseDir,
 		TargetDir:    baseDir,
 		IsDir:        fileInfo.IsDir(),
+		IsSymlink:    isSymlink(fileInfo),
 		Source:       fileName,
 		OriginalName: fileName,
 		SourcePath:   filepath.Join(baseDir, fileName),
+		Size:         fileInfo.Size(),
+		ModTime:      fileInfo.ModTime(),
 	}
 
 	if conf.TargetDir != "" {
@@ -166,11 +345,69 @@ func createFileChange(
 	return match
 }
 
+// setRelativeSource makes the source of the match relative to the root path
+// so that the replacement is applied to the entire relative path in
+// --match-path mode.
+func setRelativeSource(
+	conf *config.Config,
+	match *file.Change,
+	rootPath, currentPath string,
+) {
+	relPath, err := filepath.Rel(rootPath, currentPath)
+	if err != nil {
+		return
+	}
+
+	match.BaseDir = rootPath
+	match.TargetDir = rootPath
+	match.Source = filepath.ToSlash(relPath)
+	match.OriginalName = match.Source
+
+	if conf.TargetDir != "" {
+		match.TargetDir = conf.TargetDir
+	}
+}
+
+// keepMatch reports whether the match passes the filters and the --where
+// predicates, and extracts its custom sort value if it does.
+func keepMatch(
+	conf *config.Config,
+	match *file.Change,
+	fileInfo fs.FileInfo,
+) (bool, error) {
+	if reason := filterReason(conf, match, fileInfo); reason != "" {
+		report.Debug(conf, "skipping '%s' (%s)", match.SourcePath, reason)
+		return false, nil
+	}
+
+	ok, err := matchesWhere(conf, match)
+	if err != nil {
+		return false, err
+	}
+
+	if !ok {
+		report.Debug(conf, "skipping '%s' (does not match --where)", match.SourcePath)
+		return false, nil
+	}
+
+	err = extractCustomSort(conf, match, &vars)
+	if err != nil {
+		return false, err
+	}
+
+	report.Debug(conf, "matched '%s'", match.SourcePath)
+
+	return true, nil
+}
+
 // searchPaths walks through the filesystem and finds matches for the provided
 // search pattern.
 func searchPaths(conf *config.Config) (file.Changes, error) {
 	processedPaths := make(map[string]bool)
 
+	// real paths of the directories walked in --follow-dir-links mode
+	visited := make(visitedDirs)
+
 	var matches file.Changes
 
 	for _, rootPath := range conf.FilesAndDirPaths {
@@ -186,97 +423,203 @@ func searchPaths(conf *config.Config) (file.Changes, error) {
 				continue
 			}
 
-			if conf.Search.Regex.MatchString(fileInfo.Name()) {
-				match := createFileChange(conf, rootPath, fileInfo)
+			processedPaths[rootPath] = true
 
-				if !shouldFilter(conf, match) {
-					err := extractCustomSort(conf, match, &vars)
-					if err != nil {
-						return nil, err
-					}
+			filePath, linkPath := rootPath, ""
 
-					matches = append(matches, match)
+			linkInfo, err := os.Lstat(rootPath)
+			if err != nil {
+				return nil, err
+			}
+
+			if isSymlink(linkInfo) {
+				var ok bool
+
+				filePath, fileInfo, ok, err = resolveSymlink(
+					conf,
+					rootPath,
+					linkInfo,
+				)
+				if err != nil {
+					return nil, err
+				}
+
+				if !ok || (processedPaths[filePath] && filePath != rootPath) {
+					continue
+				}
+
+				if filePath != rootPath {
+					linkPath = rootPath
 				}
 			}
 
-			processedPaths[rootPath] = true
+			processedPaths[filePath] = true
+
+			if !isMatch(conf, fileInfo.Name()) {
+				report.Debug(conf, "skipping '%s' (does not match)", filePath)
+				continue
+			}
+
+			match := createFileChange(conf, filePath, fileInfo)
+			match.LinkPath = linkPath
+
+			ok, err := keepMatch(conf, match, fileInfo)
+			if err != nil {
+				return nil, err
+			}
+
+			if ok {
+				matches = append(matches, match)
+			}
 
 			continue
 		}
 
-		maxDepth := -1 // default value for non-recursive iterations
-		if conf.Recursive {
-			maxDepth = conf.MaxDepth
+		filter, err := newWalkFilter(
+			conf,
+			rootPath,
+			fileInfo,
+			conf.FilesAndDirPaths,
+		)
+		if err != nil {
+			return nil, err
 		}
 
-		err = filepath.WalkDir(
-			rootPath,
-			func(currentPath string, entry fs.DirEntry, err error) error {
-				if err != nil {
-					return err
+		report.Debug(conf, "searching '%s'", rootPath)
+
+		if conf.FollowDirLinks {
+			if _, err = visited.visit(rootPath); err != nil {
+				return nil, err
+			}
+		}
+
+		var walkFn fs.WalkDirFunc
+
+		walkFn = func(currentPath string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			// skip the root path and already processed paths
+			if rootPath == currentPath || processedPaths[currentPath] {
+				return nil
+			}
+
+			skip, atDepth, skipErr := filter.skip(currentPath, entry)
+			if skip {
+				return skipErr
+			}
+
+			if conf.FollowDirLinks && conf.Recursive {
+				if entry.IsDir() {
+					seen, visitErr := visited.visit(currentPath)
+					if visitErr != nil {
+						return visitErr
+					}
+
+					// The directory was already walked through a link
+					if seen {
+						return fs.SkipDir
+					}
+				} else if entry.Type()&fs.ModeSymlink != 0 {
+					linkErr := walkDirLink(
+						conf,
+						currentPath,
+						filter.rootDevice,
+						visited,
+						filter.ignored,
+						walkFn,
+					)
+					if linkErr != nil {
+						return linkErr
+					}
 				}
+			}
 
-				// skip the root path and already processed paths
-				if rootPath == currentPath || processedPaths[currentPath] {
-					return nil
+			if entry.IsDir() {
+				report.Debug(conf, "searching '%s'", currentPath)
+
+				if loadErr := filter.ignored.load(currentPath); loadErr != nil {
+					return loadErr
+				}
+			}
+
+			var linkPath string
+
+			if entry.Type()&fs.ModeSymlink != 0 &&
+				conf.Symlinks != config.SymlinksRename {
+				linkInfo, infoErr := entry.Info()
+				if infoErr != nil {
+					return infoErr
 				}
 
-				if skipHidden, hiddenErr := skipFileIfHidden(
+				targetPath, targetInfo, ok, linkErr := resolveSymlink(
+					conf,
 					currentPath,
-					conf.FilesAndDirPaths,
-					conf.IncludeHidden,
-				); hiddenErr != nil {
-					return hiddenErr
-				} else if skipHidden {
-					if entry.IsDir() {
-						return fs.SkipDir
-					}
+					linkInfo,
+				)
+				if linkErr != nil {
+					return linkErr
+				}
 
+				if !ok || processedPaths[targetPath] {
 					return nil
 				}
 
-				if entry.IsDir() && conf.Recursive &&
-					conf.ExcludeDirRegex != nil {
-					if conf.ExcludeDirRegex.MatchString(entry.Name()) {
-						return fs.SkipDir
-					}
-				}
+				linkPath, currentPath = currentPath, targetPath
+				entry = fs.FileInfoToDirEntry(targetInfo)
+			}
 
-				if isMaxDepth(rootPath, currentPath, maxDepth) {
-					return fs.SkipDir
+			fileName := entry.Name()
+
+			// Match against the path relative to the root in --match-path
+			// mode
+			if conf.MatchPath {
+				relPath, relErr := filepath.Rel(rootPath, currentPath)
+				if relErr != nil {
+					return relErr
 				}
 
-				fileName := entry.Name()
+				fileName = filepath.ToSlash(relPath)
+			}
+
+			entryIsDir := entry.IsDir()
 
-				entryIsDir := entry.IsDir()
+			if conf.IgnoreExt && !entryIsDir {
+				fileName = pathutil.StripExtension(fileName)
+			}
 
-				if conf.IgnoreExt && !entryIsDir {
-					fileName = pathutil.StripExtension(fileName)
+			if atDepth && isMatch(conf, fileName) {
+				fileInfo, infoErr := entry.Info()
+				if infoErr != nil {
+					return infoErr
 				}
 
-				if conf.Search.Regex.MatchString(fileName) {
-					fileInfo, infoErr := entry.Info()
-					if infoErr != nil {
-						return infoErr
-					}
+				match := createFileChange(conf, currentPath, fileInfo)
+				match.LinkPath = linkPath
 
-					match := createFileChange(conf, currentPath, fileInfo)
+				if conf.MatchPath {
+					setRelativeSource(conf, match, rootPath, currentPath)
+				}
 
-					if !shouldFilter(conf, match) {
-						err := extractCustomSort(conf, match, &vars)
-						if err != nil {
-							return err
-						}
+				ok, keepErr := keepMatch(conf, match, fileInfo)
+				if keepErr != nil {
+					return keepErr
+				}
 
-						matches = append(matches, match)
-					}
+				if ok {
+					matches = append(matches, match)
 				}
+			} else if atDepth {
+				report.Debug(conf, "skipping '%s' (does not match)", currentPath)
+			}
 
-				processedPaths[currentPath] = true
+			processedPaths[currentPath] = true
 
-				return nil
-			},
-		)
+			return nil
+		}
+
+		err = filepath.WalkDir(rootPath, walkFn)
 		if err != nil {
 			return nil, err
 		}
@@ -285,46 +628,35 @@ func searchPaths(conf *config.Config) (file.Changes, error) {
 	return matches, nil
 }
 
-// loadFromBackup loads the details of the previous renaming operation
-// from the backup file. It returns the changes or an error if the backup file
-// cannot be found or parsed.
-func loadFromBackup(conf *config.Config) (file.Changes, error) {
-	backupFilePath := filepath.Join(
-		os.TempDir(),
-		"f2",
-		"backups",
-		conf.BackupFilename,
-	)
-
-	_, err := os.Stat(backupFilePath)
-	if os.IsNotExist(err) {
-		return nil, nil
-	} else if err != nil {
+// loadForRedo loads the details of the renaming operation that was undone last
+// in the working directory from its backup file in the history so that it can
+// be performed again. It returns the changes or an error if the backup file
+// cannot be read or parsed.
+func loadForRedo(conf *config.Config) (file.Changes, error) {
+	backupFilePath, err := config.LastUndoneBackup(conf.WorkingDir)
+	if err != nil || backupFilePath == "" {
 		return nil, err
 	}
 
-	fileBytes, err := os.ReadFile(backupFilePath)
+	backup, err := config.ReadBackup(backupFilePath)
 	if err != nil {
 		return nil, err
 	}
 
-	var backup config.Backup
-
-	if err := json.Unmarshal(fileBytes, &backup); err != nil {
+	err = backup.CheckReversible()
+	if err != nil {
 		return nil, err
 	}
 
-	changes := backup.Changes
+	// The operation is performed again in the same way
+	if backup.Operation != "" {
+		conf.Operation = backup.Operation
+	}
 
-	// Swap source and target for each change to revert the renaming
-	for i := range changes {
-		ch := changes[i]
-		p := filepath.Join(ch.TargetDir, ch.Target)
-		ch.Target = filepath.Base(p)
-		ch.TargetDir = filepath.Dir(p)
+	// The changes are recorded in the order in which they were renamed
+	changes := backup.Changes
 
-		ch.Source, ch.Target = ch.Target, ch.Source
-		ch.BaseDir, ch.TargetDir = ch.TargetDir, ch.BaseDir
+	for _, ch := range changes {
 		ch.SourcePath = filepath.Join(ch.BaseDir, ch.Source)
 		ch.TargetPath = filepath.Join(ch.TargetDir, ch.Target)
 		ch.Status = status.OK
@@ -333,15 +665,199 @@ func loadFromBackup(conf *config.Config) (file.Changes, error) {
 		if errors.Is(err, os.ErrNotExist) {
 			ch.Status = status.SourceNotFound
 		}
+	}
+
+	return changes, nil
+}
 
-		changes[i] = ch
+var errModifiedSinceRename = errors.New(
+	"the file was modified after it was renamed (use --allow-modified to restore it)",
+)
+
+// loadFromBackup loads the details of the renaming operations that are undone
+// (the most recent one in the working directory unless --undo-id or
+// --undo-last is set) from their backup files in the history. It returns the
+// changes or an error if a backup file cannot be read or parsed.
+//
+// When several operations are undone, they are reverted from the most recent
+// one and their reversed changes are combined so that each file is renamed
+// once from its current path to the one it had before the oldest operation.
+// A file that one of the operations expects but that is moved away by a more
+// recent one is reported as missing.
+func loadFromBackup(conf *config.Config) (file.Changes, error) {
+	backupFilePaths, err := config.BackupsToUndo(conf)
+	if err != nil || len(backupFilePaths) == 0 {
+		return nil, err
+	}
+
+	var (
+		changes     file.Changes
+		cleanedDirs []string
+	)
+
+	// the combined change that renames a file to each path so far
+	byTarget := make(map[string]*file.Change)
+
+	// the paths that are renamed away by the more recent operations
+	movedAway := make(map[string]bool)
+
+	for _, backupFilePath := range backupFilePaths {
+		backup, err := config.ReadBackup(backupFilePath)
+		if err != nil {
+			return nil, err
+		}
+
+		err = backup.CheckReversible()
+		if err != nil {
+			return nil, err
+		}
+
+		if len(backup.Overwritten) > 0 && !conf.Quiet {
+			report.OverwrittenFiles(backup.Overwritten)
+		}
+
+		// Paths are relative to the directory where the operation was
+		// performed, which may differ from the working directory when
+		// --undo-id is used
+		rebase := backup.WorkingDir != "" && backup.WorkingDir != conf.WorkingDir
+
+		for _, v := range backup.CleanedDirs {
+			if rebase {
+				v = rebasePath(backup.WorkingDir, v)
+			}
+
+			cleanedDirs = append(cleanedDirs, v)
+		}
+
+		// the state of each file after the operation keyed by its path
+		states := make(map[string]config.FileState, len(backup.Files))
+
+		for p, state := range backup.Files {
+			if rebase {
+				p = rebasePath(backup.WorkingDir, p)
+			}
+
+			states[p] = state
+		}
+
+		// The changes are only combined with those of the more recent
+		// operations since each file is renamed once by an operation, even
+		// when the files are swapped or renamed in a cycle
+		targets := make(map[string]*file.Change)
+		moved := make(map[string]bool)
+
+		// Swap source and target for each change to revert the renaming
+		for _, ch := range backup.Changes {
+			p := filepath.Join(ch.TargetDir, ch.Target)
+
+			if rebase {
+				p = rebasePath(backup.WorkingDir, p)
+				ch.BaseDir = rebasePath(backup.WorkingDir, ch.BaseDir)
+			}
+
+			ch.Target = filepath.Base(p)
+			ch.TargetDir = filepath.Dir(p)
+
+			ch.Source, ch.Target = ch.Target, ch.Source
+			ch.BaseDir, ch.TargetDir = ch.TargetDir, ch.BaseDir
+			ch.SourcePath = filepath.Join(ch.BaseDir, ch.Source)
+			ch.TargetPath = filepath.Join(ch.TargetDir, ch.Target)
+			ch.Status = status.OK
+
+			// The file that the change overwrote is restored from the trash
+			// or its backup to the path that is freed when the change is
+			// undone
+			if ch.Trashed != "" {
+				ch.Restore = map[string]file.Kept{
+					ch.SourcePath: {Path: ch.Trashed, Trashed: true},
+				}
+			} else if ch.Backup != "" {
+				if rebase {
+					ch.Backup = rebasePath(backup.WorkingDir, ch.Backup)
+				}
+
+				ch.Restore = map[string]file.Kept{
+					ch.SourcePath: {Path: ch.Backup},
+				}
+			}
+
+			ch.Trashed, ch.Backup = "", ""
+
+			// the path of the file that was copied or linked
+			original := ch.TargetPath
+
+			// Undoing a copy or link deletes it since the original is still
+			// in place
+			if config.KeepsSources(backup.Operation) {
+				ch.Target = ""
+				ch.TargetPath = ""
+				ch.Remove = true
+			}
+
+			// The file was renamed to this path by a more recent operation
+			// that is also undone, so the combined change is extended
+			if prev, ok := byTarget[ch.SourcePath]; ok {
+				delete(byTarget, ch.SourcePath)
+
+				prev.Target = ch.Target
+				prev.TargetDir = ch.TargetDir
+				prev.TargetPath = ch.TargetPath
+				prev.Remove = ch.Remove
+
+				for path, kept := range ch.Restore {
+					if prev.Restore == nil {
+						prev.Restore = make(map[string]file.Kept)
+					}
+
+					prev.Restore[path] = kept
+				}
+
+				if !prev.Remove {
+					targets[prev.TargetPath] = prev
+				}
+
+				// The file was checked against its state at its current path
+				// when the more recent operation was undone, so the state at
+				// the path that it was renamed away from is not used
+
+				if prev.Remove && prev.Status == status.OK {
+					skipIfNotLink(conf, prev, backup.Operation, original)
+				}
+
+				continue
+			}
+
+			_, err := os.Stat(ch.SourcePath)
+			if errors.Is(err, os.ErrNotExist) || movedAway[ch.SourcePath] {
+				ch.Status = status.SourceNotFound
+			}
+
+			if state, ok := states[ch.SourcePath]; ok && ch.Status == status.OK {
+				skipIfModified(conf, ch, state)
+			}
+
+			if ch.Remove && ch.Status == status.OK {
+				skipIfNotLink(conf, ch, backup.Operation, original)
+			}
+
+			moved[ch.SourcePath] = true
+
+			if !ch.Remove {
+				targets[ch.TargetPath] = ch
+			}
+
+			changes = append(changes, ch)
+		}
+
+		maps.Copy(byTarget, targets)
+		maps.Copy(movedAway, moved)
 	}
 
 	if conf.Exec {
 		sortfiles.ForRenamingAndUndo(changes, conf.Revert)
 
 		// recreate empty directories that were cleaned
-		for _, v := range backup.CleanedDirs {
+		for _, v := range cleanedDirs {
 			_ = os.MkdirAll(v, osutil.DirPermission)
 		}
 	}
@@ -349,9 +865,79 @@ func loadFromBackup(conf *config.Config) (file.Changes, error) {
 	return changes, nil
 }
 
+// skipIfModified skips a file whose contents changed after it was renamed so
+// that the edited file does not silently take its old name.
+func skipIfModified(
+	conf *config.Config,
+	ch *file.Change,
+	state config.FileState,
+) {
+	if conf.AllowModified {
+		return
+	}
+
+	if modified, _ := state.Modified(ch.SourcePath); modified {
+		ch.Status = status.Ignored
+		ch.Error = errModifiedSinceRename
+
+		if !conf.Quiet {
+			report.ModifiedSinceRename(ch.SourcePath)
+		}
+	}
+}
+
+// skipIfNotLink skips a link that is deleted when undoing a link operation if
+// it no longer refers to the original file, such as when it was replaced with
+// a regular file or when the original was removed, so that no data is lost.
+func skipIfNotLink(
+	conf *config.Config,
+	ch *file.Change,
+	operation, original string,
+) {
+	var isLink bool
+
+	switch operation {
+	case config.OperationSymlink:
+		info, err := os.Lstat(ch.SourcePath)
+		isLink = err == nil && info.Mode()&os.ModeSymlink != 0
+	case config.OperationHardlink:
+		linkInfo, err := os.Lstat(ch.SourcePath)
+		if err != nil {
+			break
+		}
+
+		originalInfo, err := os.Lstat(original)
+		isLink = err == nil && os.SameFile(linkInfo, originalInfo)
+	default:
+		return
+	}
+
+	if isLink {
+		return
+	}
+
+	ch.Status = status.Ignored
+
+	if !conf.Quiet {
+		report.NotALink(ch.SourcePath, original)
+	}
+}
+
+// rebasePath joins a relative path onto the specified directory.
+func rebasePath(dir, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+
+	return filepath.Join(dir, path)
+}
+
 // Find returns a collection of files and directories that match the search
 // pattern or explicitly included as command-line arguments.
 func Find(conf *config.Config) (changes file.Changes, err error) {
+	// Reset the sort variables from any previous call
+	vars = variables.Variables{}
+
 	if conf.SortVariable != "" {
 		vars, err = variables.Extract(conf.SortVariable)
 		if err != nil {
@@ -359,10 +945,23 @@ func Find(conf *config.Config) (changes file.Changes, err error) {
 		}
 	}
 
+	whereVars = make([]variables.Variables, len(conf.Where))
+
+	for i := range conf.Where {
+		whereVars[i], err = variables.Extract(conf.Where[i].Variable)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	if conf.Revert {
 		return loadFromBackup(conf)
 	}
 
+	if conf.Redo {
+		return loadForRedo(conf)
+	}
+
 	defer func() {
 		if conf.Pair && err == nil {
 			sortfiles.Pairs(changes, conf.PairOrder)
@@ -1,8 +1,12 @@
 package find
 
 import (
+	"os"
 	"path/filepath"
+	"runtime"
 	"testing"
+
+	"github.com/ayoisaiah/f2/v2/internal/config"
 )
 
 func TestIsMaxDepth(t *testing.T) {
@@ -48,6 +52,20 @@ func TestIsMaxDepth(t *testing.T) {
 			MaxDepth:    3,
 			Expected:    false,
 		},
+		{
+			Name:        "root path ends with a separator",
+			RootPath:    "/",
+			CurrentPath: "/images/bike.jpg",
+			MaxDepth:    1,
+			Expected:    false,
+		},
+		{
+			Name:        "max depth exceeded from a root path with a separator",
+			RootPath:    "/",
+			CurrentPath: "/images/jpegs/bike.jpg",
+			MaxDepth:    1,
+			Expected:    true,
+		},
 	}
 
 	for i := range cases {
@@ -73,3 +91,57 @@ func TestIsMaxDepth(t *testing.T) {
 		})
 	}
 }
+
+func TestIsOtherDevice(t *testing.T) {
+	dirInfo, err := os.Stat(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rootDevice, _ := deviceID(dirInfo)
+
+	cases := []struct {
+		Name          string
+		RootDevice    uint64
+		OneFileSystem bool
+		Expected      bool
+	}{
+		{
+			Name:          "directory is on the same filesystem",
+			RootDevice:    rootDevice,
+			OneFileSystem: true,
+			Expected:      false,
+		},
+		{
+			Name:          "directory is on another filesystem",
+			RootDevice:    rootDevice + 1,
+			OneFileSystem: true,
+			// device IDs are not available on Windows
+			Expected: runtime.GOOS != "windows",
+		},
+		{
+			Name:          "filesystems are ignored by default",
+			RootDevice:    rootDevice + 1,
+			OneFileSystem: false,
+			Expected:      false,
+		},
+	}
+
+	for i := range cases {
+		tc := cases[i]
+
+		t.Run(tc.Name, func(t *testing.T) {
+			conf := &config.Config{OneFileSystem: tc.OneFileSystem}
+
+			got := isOtherDevice(conf, dirInfo, tc.RootDevice)
+
+			if got != tc.Expected {
+				t.Fatalf(
+					"expected other device to be: %t, but got: %t",
+					tc.Expected,
+					got,
+				)
+			}
+		})
+	}
+}
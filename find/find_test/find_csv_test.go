@@ -15,6 +15,14 @@ var csvCases = []testutil.TestCase{
 		},
 		Args: []string{"--csv", "testdata/input.csv"},
 	},
+	{
+		Name: "find matches from json file",
+		Want: []string{
+			"a.txt",
+			"b.txt",
+		},
+		Args: []string{"--csv", "testdata/input.json"},
+	},
 	// TODO: Add more tests
 }
 
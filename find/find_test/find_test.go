@@ -3,7 +3,9 @@ package find_test
 import (
 	"errors"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/ayoisaiah/f2/v2/find"
 	"github.com/ayoisaiah/f2/v2/internal/testutil"
@@ -50,6 +52,202 @@ var findFileSystem = []string{
 	"videos/tutorials/JavaScript.mp4",
 }
 
+// setupFileSizes writes some data to the videos so that they can be filtered
+// by size.
+func setupFileSizes(t *testing.T, testDir string) (teardown func()) {
+	t.Helper()
+
+	sizes := map[string]int{
+		"videos/funny_cats (3).mp4":   2000,
+		"videos/tutorials/GoLang.mp4": 500,
+	}
+
+	for name, size := range sizes {
+		err := os.Truncate(filepath.Join(testDir, name), int64(size))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return func() {
+		for name := range sizes {
+			err := os.Truncate(filepath.Join(testDir, name), 0)
+			if err != nil {
+				t.Log(err)
+			}
+		}
+	}
+}
+
+// setupFileContents writes some text to the documents so that they can be
+// matched by their contents. One of the documents is made to look like a
+// binary file.
+func setupFileContents(t *testing.T, testDir string) (teardown func()) {
+	t.Helper()
+
+	contents := map[string]string{
+		"documents/cover_letter.docx":            "Dear ACME Corporation,",
+		"backup/documents/old_cover_letter.docx": "Dear acme corporation,",
+		"backup/documents/old_resume.docx":       "ACME Corporation\x00",
+	}
+
+	for name, content := range contents {
+		err := os.WriteFile(
+			filepath.Join(testDir, name),
+			[]byte(content),
+			0o600,
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return func() {
+		for name := range contents {
+			err := os.Truncate(filepath.Join(testDir, name), 0)
+			if err != nil {
+				t.Log(err)
+			}
+		}
+	}
+}
+
+// setupHiddenDir creates a hidden directory that contains both hidden and
+// regular files alongside a hidden file in the root of the test directory.
+func setupHiddenDir(t *testing.T, testDir string) (teardown func()) {
+	t.Helper()
+
+	files := []string{
+		".config/settings.json",
+		".config/.settings.bak",
+		".settings",
+	}
+
+	for _, name := range files {
+		path := filepath.Join(testDir, name)
+
+		err := os.MkdirAll(filepath.Dir(path), 0o750)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = os.WriteFile(path, nil, 0o600)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return func() {
+		for _, name := range []string{".config", ".settings"} {
+			err := os.RemoveAll(filepath.Join(testDir, name))
+			if err != nil {
+				t.Log(err)
+			}
+		}
+	}
+}
+
+// setupFileTimes sets the modification time of one of the videos to 10 days
+// ago so that it can be filtered by date.
+func setupFileTimes(t *testing.T, testDir string) (teardown func()) {
+	t.Helper()
+
+	path := filepath.Join(testDir, "videos/tutorials/GoLang.mp4")
+	past := time.Now().AddDate(0, 0, -10)
+
+	err := os.Chtimes(path, past, past)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return func() {
+		now := time.Now()
+
+		err := os.Chtimes(path, now, now)
+		if err != nil {
+			t.Log(err)
+		}
+	}
+}
+
+// setupGitignore turns the test directory into a git repository with
+// .gitignore files at the root and in a subdirectory.
+func setupGitignore(t *testing.T, testDir string) (teardown func()) {
+	t.Helper()
+
+	files := map[string]string{
+		".gitignore":                   "backup/\n*.mp4\n!GoLang.mp4\n",
+		"projects/project1/.gitignore": "styles/\n",
+	}
+
+	err := os.Mkdir(filepath.Join(testDir, ".git"), 0o755)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, content := range files {
+		err := os.WriteFile(filepath.Join(testDir, name), []byte(content), 0o600)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return func() {
+		err := os.RemoveAll(filepath.Join(testDir, ".git"))
+		if err != nil {
+			t.Log(err)
+		}
+
+		for name := range files {
+			err := os.Remove(filepath.Join(testDir, name))
+			if err != nil {
+				t.Log(err)
+			}
+		}
+	}
+}
+
+// setupF2ignore adds a .f2ignore file to the test directory and a global one
+// to the config directory.
+func setupF2ignore(t *testing.T, testDir string) (teardown func()) {
+	t.Helper()
+
+	// Point the config directory to a temporary directory on every platform
+	tempDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", tempDir)
+	t.Setenv("HOME", tempDir)
+	t.Setenv("AppData", tempDir)
+
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = os.MkdirAll(filepath.Join(configDir, "f2"), 0o755)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string]string{
+		filepath.Join(testDir, ".f2ignore"):         "styles/\n",
+		filepath.Join(configDir, "f2", ".f2ignore"): "*.java\n",
+	}
+
+	for path, content := range files {
+		err := os.WriteFile(path, []byte(content), 0o600)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return func() {
+		err := os.Remove(filepath.Join(testDir, ".f2ignore"))
+		if err != nil {
+			t.Log(err)
+		}
+	}
+}
+
 var testCases = []testutil.TestCase{
 	{
 		Name: "include directories in search",
@@ -77,6 +275,23 @@ var testCases = []testutil.TestCase{
 		Args: []string{"-f", "photo", "-R", "-m", "2"},
 	},
 
+	{
+		Name: "match directories at an exact depth",
+		Want: []string{
+			"photos/family",
+		},
+		Args: []string{"-f", "family", "-D", "--depth", "2"},
+	},
+
+	{
+		Name: "match files at an exact depth",
+		Want: []string{
+			"photos/family/Photo1.jpg",
+			"photos/vacation/beach.jpg",
+		},
+		Args: []string{"-f", "jpg", "--depth", "3"},
+	},
+
 	{
 		Name: "match recursively but exclude certain patterns",
 		Want: []string{
@@ -105,6 +320,205 @@ var testCases = []testutil.TestCase{
 		SetupFunc: setupWindowsHidden,
 	},
 
+	{
+		Name: "exclude patterns case insensitively",
+		Want: []string{
+			"photos/family/Photo1.jpg",
+			"photos/family/photo3.gif",
+			"photos/vacation/mountains/photo1.jpg",
+		},
+		Args: []string{
+			"-f",
+			"photo",
+			"-R",
+			"-i",
+			"-E",
+			"^old|webp$",
+			"-E",
+			"png$",
+		},
+		SetupFunc: setupWindowsHidden,
+	},
+
+	{
+		Name: "match files using a glob pattern",
+		Want: []string{
+			"photos/family/photo3.gif",
+		},
+		Args: []string{"-f", "photo?.gif", "-R", "--glob"},
+	},
+
+	{
+		Name: "match files approximately in fuzzy mode",
+		Want: []string{
+			"backup/documents/old_resume.docx",
+			"documents/resume.docx",
+		},
+		Args: []string{"-f", "resmue", "-R", "--fuzzy", "2"},
+	},
+
+	{
+		Name: "skip the files ignored by git",
+		Want: []string{
+			"main.go",
+			"projects/project3/src/main.java",
+			"videos/tutorials/GoLang.mp4",
+		},
+		Args:      []string{"-f", "main|mp4", "-R", "--respect-gitignore"},
+		SetupFunc: setupGitignore,
+	},
+
+	{
+		Name: "skip the files in the .f2ignore files",
+		Want: []string{
+			"main.go",
+		},
+		Args:      []string{"-f", "main", "-R"},
+		SetupFunc: setupF2ignore,
+	},
+
+	{
+		Name: "match files that are at least the minimum size",
+		Want: []string{
+			"videos/funny_cats (3).mp4",
+		},
+		Args:      []string{"-f", "mp4", "-R", "--min-size", "1KB"},
+		SetupFunc: setupFileSizes,
+	},
+
+	{
+		Name: "match files within a size range",
+		Want: []string{
+			"videos/tutorials/GoLang.mp4",
+		},
+		Args: []string{
+			"-f",
+			"mp4",
+			"-R",
+			"--min-size",
+			"100B",
+			"--max-size",
+			"1KiB",
+		},
+		SetupFunc: setupFileSizes,
+	},
+
+	{
+		Name: "match files modified within a duration",
+		Want: []string{
+			"videos/funny_cats (3).mp4",
+			"videos/tutorials/JavaScript.mp4",
+		},
+		Args:      []string{"-f", "mp4", "-R", "--newer-than", "7d"},
+		SetupFunc: setupFileTimes,
+	},
+
+	{
+		Name: "match files by their contents",
+		Want: []string{
+			"documents/cover_letter.docx",
+		},
+		Args:      []string{"-f", "docx", "-R", "--grep", "ACME"},
+		SetupFunc: setupFileContents,
+	},
+
+	{
+		Name: "match files by their contents regardless of case",
+		Want: []string{
+			"backup/documents/old_cover_letter.docx",
+			"documents/cover_letter.docx",
+		},
+		Args:      []string{"-f", "docx", "-R", "--grep", "acme", "-i"},
+		SetupFunc: setupFileContents,
+	},
+
+	{
+		Name: "search only the beginning of files for content matches",
+		Want: []string{},
+		Args: []string{
+			"-f",
+			"docx",
+			"-R",
+			"--grep",
+			"Corporation",
+			"--grep-limit",
+			"8B",
+		},
+		SetupFunc: setupFileContents,
+	},
+
+	{
+		Name: "include hidden files and directories",
+		Want: []string{
+			".config/.settings.bak",
+			".config/settings.json",
+			".settings",
+		},
+		Args:      []string{"-f", "settings", "-RH"},
+		SetupFunc: setupHiddenDir,
+	},
+
+	{
+		Name: "recurse into hidden directories without matching hidden files",
+		Want: []string{
+			".config/settings.json",
+		},
+		Args:      []string{"-f", "settings", "-R", "--hidden-dirs"},
+		SetupFunc: setupHiddenDir,
+	},
+
+	{
+		Name: "match hidden files without recursing into hidden directories",
+		Want: []string{
+			".settings",
+		},
+		Args:      []string{"-f", "settings", "-R", "--hidden-files"},
+		SetupFunc: setupHiddenDir,
+	},
+
+	{
+		Name: "match files modified before a duration",
+		Want: []string{
+			"videos/tutorials/GoLang.mp4",
+		},
+		Args:      []string{"-f", "mp4", "-R", "--older-than", "168h"},
+		SetupFunc: setupFileTimes,
+	},
+
+	{
+		Name: "match only files with the provided extensions",
+		Want: []string{
+			"photos/family/Photo1.jpg",
+			"photos/family/photo2.PNG",
+			"photos/vacation/beach.jpg",
+			"photos/vacation/mountains/OLDPHOTO3.JPG",
+			"photos/vacation/mountains/OLD_PHOTO5.JPG",
+			"photos/vacation/mountains/photo1.jpg",
+			"projects/project2/assets/logo (1).png",
+		},
+		Args: []string{"-f", ".*", "-R", "--ext", "jpg,.png", "-E", "^old"},
+	},
+
+	{
+		Name: "match against the relative path",
+		Want: []string{
+			"photos/family/Photo1.jpg",
+			"photos/family/photo2.PNG",
+			"photos/family/photo3.gif",
+		},
+		Args: []string{"-f", "^photos/family/", "-R", "--match-path"},
+	},
+
+	{
+		Name: "match files that do not match the find pattern",
+		Want: []string{
+			"Makefile",
+			"README.md",
+			"main.go",
+		},
+		Args: []string{"-f", "LICENSE|txt", "--invert"},
+	},
+
 	{
 		Name: "match only directories",
 		Want: []string{"backup/photos", "photos"},
@@ -278,6 +692,80 @@ func TestFind(t *testing.T) {
 	findTest(t, testCases, testDir)
 }
 
+func TestFindFromFile(t *testing.T) {
+	testDir := testutil.SetupFileSystem(t, "find", findFileSystem)
+
+	pathList := filepath.Join(t.TempDir(), "paths.txt")
+
+	err := os.WriteFile(
+		pathList,
+		[]byte(
+			filepath.Join(testDir, "README.md")+"\x00"+
+				filepath.Join(testDir, "photos/family/Photo1.jpg")+"\x00",
+		),
+		0o600,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []testutil.TestCase{
+		{
+			Name: "match the paths read from a file",
+			Want: []string{
+				"main.go",
+				"README.md",
+				"photos/family/Photo1.jpg",
+			},
+			Args:     []string{"-f", ".*", "--from-file", pathList},
+			PathArgs: []string{"main.go"},
+		},
+	}
+
+	findTest(t, cases, testDir)
+}
+
+func TestFindWhere(t *testing.T) {
+	testDir := "testdata"
+
+	cases := []testutil.TestCase{
+		{
+			Name: "match files whose Exif data equals a value",
+			Want: []string{
+				"DSC100_John-Doe_20211012.dng",
+				"DSC100_John-Doe_20211012.jpg",
+				"DSC200_Auba-Hall_20240909.dng",
+				"DSC200_Auba-Hall_20240909.jpg",
+				"DSC400_Tim-Scott_20200102.dng",
+			},
+			Args: []string{
+				"-f",
+				".*",
+				"--where",
+				"exif.model=Canon EOS 350D DIGITAL",
+			},
+		},
+		{
+			Name: "match files whose Exif data does not match a regex",
+			Want: []string{
+				"a.txt",
+				"b.txt",
+				"c.txt",
+			},
+			Args: []string{
+				"-f",
+				".*",
+				"--where",
+				"{x.make}!~(?i)canon",
+				"--where",
+				"ext~txt",
+			},
+		},
+	}
+
+	findTest(t, cases, testDir)
+}
+
 // TODO: Test reverting from a backup file.
 func TestLoadFromBackup(t *testing.T) {
 	t.Skip("not implemented")
@@ -4,6 +4,9 @@
 package find_test
 
 import (
+	"os"
+	"path/filepath"
+	"strconv"
 	"testing"
 
 	"github.com/ayoisaiah/f2/v2/internal/testutil"
@@ -13,6 +16,60 @@ func setupWindowsHidden(_ *testing.T, _ string) (teardown func()) {
 	return func() {}
 }
 
+// setupSymlinks creates a symbolic link to main.go and a broken symbolic link
+// in the test directory.
+func setupSymlinks(t *testing.T, testDir string) (teardown func()) {
+	t.Helper()
+
+	links := map[string]string{
+		"link_to_main.go": "main.go",
+		"broken_main.go":  "missing.go",
+	}
+
+	for link, target := range links {
+		err := os.Symlink(target, filepath.Join(testDir, link))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return func() {
+		for link := range links {
+			err := os.Remove(filepath.Join(testDir, link))
+			if err != nil {
+				t.Log(err)
+			}
+		}
+	}
+}
+
+// setupDirSymlinks creates a symbolic link to a directory outside the backup
+// directory and a symbolic link that points back to its parent directory.
+func setupDirSymlinks(t *testing.T, testDir string) (teardown func()) {
+	t.Helper()
+
+	links := map[string]string{
+		"backup/photos_link":             filepath.Join("..", "photos", "vacation"),
+		"photos/vacation/mountains/loop": "..",
+	}
+
+	for link, target := range links {
+		err := os.Symlink(target, filepath.Join(testDir, link))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return func() {
+		for link := range links {
+			err := os.Remove(filepath.Join(testDir, link))
+			if err != nil {
+				t.Log(err)
+			}
+		}
+	}
+}
+
 var unixTestCases = []testutil.TestCase{
 	{
 		Name: "exclude hidden files by default",
@@ -30,6 +87,103 @@ var unixTestCases = []testutil.TestCase{
 		},
 		Args: []string{"-f", "hidden", "-RH"},
 	},
+
+	{
+		Name: "rename symbolic links by default",
+		Want: []string{
+			"broken_main.go",
+			"link_to_main.go",
+			"main.go",
+		},
+		Args:      []string{"-f", "main"},
+		SetupFunc: setupSymlinks,
+	},
+
+	{
+		Name: "skip symbolic links",
+		Want: []string{
+			"main.go",
+		},
+		Args:      []string{"-f", "main", "--symlinks", "skip"},
+		SetupFunc: setupSymlinks,
+	},
+
+	{
+		Name: "follow symbolic links to their targets",
+		Want: []string{
+			"main.go",
+		},
+		Args:      []string{"-f", "main", "--symlinks", "follow"},
+		SetupFunc: setupSymlinks,
+	},
+
+	{
+		Name: "follow a symbolic link provided as an argument",
+		Want: []string{
+			"main.go",
+		},
+		Args:      []string{"-f", "main", "--symlinks", "follow"},
+		PathArgs:  []string{"link_to_main.go"},
+		SetupFunc: setupSymlinks,
+	},
+
+	{
+		Name: "do not descend into symbolic links to directories by default",
+		Want: []string{
+			"backup/photos/family/old_photo1.jpg",
+		},
+		Args:      []string{"-f", "photo1", "-R"},
+		PathArgs:  []string{"backup"},
+		SetupFunc: setupDirSymlinks,
+	},
+
+	{
+		Name: "descend into symbolic links to directories",
+		Want: []string{
+			"backup/photos/family/old_photo1.jpg",
+			"backup/photos_link/mountains/photo1.jpg",
+		},
+		Args:      []string{"-f", "photo1", "-R", "--follow-dir-links"},
+		PathArgs:  []string{"backup"},
+		SetupFunc: setupDirSymlinks,
+	},
+
+	{
+		Name: "do not walk a directory twice when following symbolic links",
+		Want: []string{
+			"photos/vacation/mountains/photo1.jpg",
+		},
+		Args:      []string{"-f", "photo1", "-R", "--follow-dir-links"},
+		PathArgs:  []string{"photos"},
+		SetupFunc: setupDirSymlinks,
+	},
+
+	{
+		Name: "match files owned by the current user",
+		Want: []string{
+			"main.go",
+		},
+		Args: []string{"-f", "main", "--owned-by", strconv.Itoa(os.Getuid())},
+	},
+
+	{
+		Name: "skip files owned by another user",
+		Want: []string{},
+		Args: []string{
+			"-f",
+			"main",
+			"--owned-by",
+			strconv.Itoa(os.Getuid() + 1),
+		},
+	},
+
+	{
+		Name: "match writable files",
+		Want: []string{
+			"main.go",
+		},
+		Args: []string{"-f", "main", "--writable-only"},
+	},
 }
 
 // TestFindUnix only tests search behaviors perculiar to Linux and macOS.
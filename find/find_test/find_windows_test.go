@@ -50,14 +50,20 @@ func setupWindowsHidden(t *testing.T, testDir string) (teardown func()) {
 
 var windowsTestCases = []testutil.TestCase{
 	{
-		Name: "dot files shouldn't be regarded as hidden in Windows",
+		Name: "dot files should be regarded as hidden in Windows",
+		Want: []string{},
+		Args: []string{"-f", "hidden", "-R"},
+	},
+
+	{
+		Name: "include dot files without hidden attribute",
 		Want: []string{
 			".hidden_file",
 			"backup/documents/.hidden_resume.txt",
 			"documents/.hidden_file.txt",
 			"photos/vacation/mountains/.hidden_photo.jpg",
 		},
-		Args: []string{"-f", "hidden", "-R"},
+		Args: []string{"-f", "hidden", "-RH"},
 	},
 
 	{
@@ -3,9 +3,47 @@
 
 package find
 
+import (
+	"io/fs"
+	"strconv"
+	"syscall"
+)
+
+// writeOK is the mode used to check for write permission with access(2).
+const writeOK = 0x2
+
 // checkIfHidden checks if a file is hidden on Unix operating systems
 // the nil error is returned to match the signature of the Windows
 // version of the function.
 func checkIfHidden(filename, _ string) (bool, error) {
 	return filename[0] == dotCharacter, nil
 }
+
+// deviceID returns the ID of the device that contains the file. It reports
+// false if the ID is not available.
+func deviceID(fileInfo fs.FileInfo) (uint64, bool) {
+	stat, ok := fileInfo.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, false
+	}
+
+	//nolint:unconvert // the type of Dev differs across platforms
+	return uint64(stat.Dev), true
+}
+
+// fileOwner returns the ID of the user that owns the file. It reports false
+// if the owner is not available.
+func fileOwner(fileInfo fs.FileInfo) (string, bool) {
+	stat, ok := fileInfo.Sys().(*syscall.Stat_t)
+	if !ok {
+		return "", false
+	}
+
+	return strconv.FormatUint(uint64(stat.Uid), 10), true
+}
+
+// isWritable reports whether the current user has permission to write to the
+// file.
+func isWritable(path string, _ fs.FileInfo) bool {
+	return syscall.Access(path, writeOK) == nil
+}
@@ -4,12 +4,19 @@
 package find
 
 import (
+	"io/fs"
 	"path/filepath"
 	"syscall"
 )
 
-// checkIfHidden checks if a file is hidden on Windows.
+// checkIfHidden checks if a file is hidden on Windows. Files that start with
+// a dot character are regarded as hidden as they are on Unix, in addition to
+// files with the 'hidden' attribute.
 func checkIfHidden(filename, baseDir string) (bool, error) {
+	if filename[0] == dotCharacter {
+		return true, nil
+	}
+
 	absPath, err := filepath.Abs(filepath.Join(baseDir, filename))
 	if err != nil {
 		return false, err
@@ -31,3 +38,23 @@ func checkIfHidden(filename, baseDir string) (bool, error) {
 
 	return attributes&syscall.FILE_ATTRIBUTE_HIDDEN != 0, nil
 }
+
+// deviceID reports false on Windows since device IDs are not available
+// through the file info. The --one-file-system flag is rejected on Windows as
+// a result.
+func deviceID(_ fs.FileInfo) (uint64, bool) {
+	return 0, false
+}
+
+// fileOwner reports false on Windows since the owner of a file is not
+// available through the file info. The --owned-by flag is rejected on Windows
+// as a result.
+func fileOwner(_ fs.FileInfo) (string, bool) {
+	return "", false
+}
+
+// isWritable reports whether the file is writable by checking its read-only
+// attribute.
+func isWritable(_ string, fileInfo fs.FileInfo) bool {
+	return fileInfo.Mode().Perm()&0o200 != 0
+}
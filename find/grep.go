@@ -0,0 +1,38 @@
+package find
+
+import (
+	"bytes"
+	"io"
+	"os"
+
+	"github.com/ayoisaiah/f2/v2/internal/config"
+)
+
+// matchesContent reports whether the contents of the file at the specified
+// path match the --grep pattern. Only the first --grep-limit bytes are
+// searched unless the limit is zero. Binary files (those containing a NUL
+// byte) and files that cannot be read never match.
+func matchesContent(conf *config.Config, path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+
+	defer f.Close()
+
+	var r io.Reader = f
+	if conf.GrepLimit > 0 {
+		r = io.LimitReader(f, conf.GrepLimit)
+	}
+
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return false
+	}
+
+	if bytes.IndexByte(content, 0) != -1 {
+		return false
+	}
+
+	return conf.GrepRegex.Match(content)
+}
@@ -0,0 +1,142 @@
+package find
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/ayoisaiah/f2/v2/internal/config"
+	"github.com/ayoisaiah/f2/v2/internal/ignore"
+)
+
+const (
+	gitignoreFile = ".gitignore"
+	f2ignoreFile  = ".f2ignore"
+)
+
+// ignoreRules tracks the ignore rules that apply while walking a root
+// directory.
+type ignoreRules struct {
+	matcher *ignore.Matcher
+	// Root of the git repository containing the root directory in
+	// --respect-gitignore mode
+	repoRoot string
+}
+
+// findRepoRoot returns the root of the git repository that contains the
+// specified directory or an empty string if it is not inside a repository.
+func findRepoRoot(dir string) string {
+	for {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return dir
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+
+		dir = parent
+	}
+}
+
+// globalIgnoreFile returns the path to the .f2ignore file in the user's
+// config directory.
+func globalIgnoreFile() string {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(configDir, "f2", f2ignoreFile)
+}
+
+// newIgnoreRules loads the ignore rules that apply to the specified root
+// directory. This includes the global .f2ignore file whose patterns are
+// relative to the root directory, and the .f2ignore file in the root
+// directory. Inside a git repository, the ignore files in the parent
+// directories up to the repository root are also loaded. With
+// --respect-gitignore, the repository's .gitignore files and its
+// .git/info/exclude file are used as well. The ignore files in
+// subdirectories are loaded as they are visited.
+func newIgnoreRules(
+	conf *config.Config,
+	rootPath string,
+) (*ignoreRules, error) {
+	rules := &ignoreRules{
+		matcher: ignore.New(),
+	}
+
+	rootPath, err := filepath.Abs(rootPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if globalFile := globalIgnoreFile(); globalFile != "" {
+		err = rules.matcher.AddFile(rootPath, globalFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	repoRoot := findRepoRoot(rootPath)
+	if repoRoot == "" {
+		err = rules.load(rootPath)
+		if err != nil {
+			return nil, err
+		}
+
+		return rules, nil
+	}
+
+	if conf.RespectGitignore {
+		rules.repoRoot = repoRoot
+
+		err = rules.matcher.AddFile(
+			repoRoot,
+			filepath.Join(repoRoot, ".git", "info", "exclude"),
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Load the ignore files from the repository root first so that the rules
+	// in deeper directories take precedence
+	var dirs []string
+	for dir := rootPath; dir != repoRoot; dir = filepath.Dir(dir) {
+		dirs = append([]string{dir}, dirs...)
+	}
+
+	dirs = append([]string{repoRoot}, dirs...)
+
+	for _, dir := range dirs {
+		err = rules.load(dir)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return rules, nil
+}
+
+// load adds the rules in the ignore files present in the specified directory.
+func (r *ignoreRules) load(dir string) error {
+	if r.repoRoot != "" {
+		err := r.matcher.AddFile(dir, filepath.Join(dir, gitignoreFile))
+		if err != nil {
+			return err
+		}
+	}
+
+	return r.matcher.AddFile(dir, filepath.Join(dir, f2ignoreFile))
+}
+
+// skip reports whether the path is excluded by the ignore rules. The .git
+// directory is always skipped when respecting the .gitignore files.
+func (r *ignoreRules) skip(path string, isDir bool) bool {
+	if r.repoRoot != "" && isDir && filepath.Base(path) == ".git" {
+		return true
+	}
+
+	return r.matcher.Match(path, isDir)
+}
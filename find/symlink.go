@@ -0,0 +1,119 @@
+package find
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/ayoisaiah/f2/v2/internal/config"
+)
+
+// isSymlink reports whether the file info describes a symbolic link.
+func isSymlink(fileInfo fs.FileInfo) bool {
+	return fileInfo.Mode()&fs.ModeSymlink != 0
+}
+
+// resolveSymlink applies the --symlinks policy to the symbolic link at the
+// specified path. It returns the path and file info of the file to rename, or
+// false if the link should be skipped. Broken links are skipped when
+// following symbolic links.
+func resolveSymlink(
+	conf *config.Config,
+	linkPath string,
+	linkInfo fs.FileInfo,
+) (string, fs.FileInfo, bool, error) {
+	switch conf.Symlinks {
+	case config.SymlinksSkip:
+		return "", nil, false, nil
+	case config.SymlinksFollow:
+		targetPath, err := filepath.EvalSymlinks(linkPath)
+		if err != nil {
+			//nolint:nilerr // broken links are skipped
+			return "", nil, false, nil
+		}
+
+		targetInfo, err := os.Stat(targetPath)
+		if err != nil {
+			return "", nil, false, err
+		}
+
+		return targetPath, targetInfo, true, nil
+	case config.SymlinksRename:
+	}
+
+	return linkPath, linkInfo, true, nil
+}
+
+// visitedDirs tracks the real paths of the directories that have been walked
+// so that symbolic links to directories are followed at most once.
+type visitedDirs map[string]bool
+
+// visit records the real path of the specified directory and reports whether
+// it was visited before.
+func (v visitedDirs) visit(dirPath string) (bool, error) {
+	realPath, err := filepath.EvalSymlinks(dirPath)
+	if err != nil {
+		return false, err
+	}
+
+	if v[realPath] {
+		return true, nil
+	}
+
+	v[realPath] = true
+
+	return false, nil
+}
+
+// walkDirLink walks the directory that the symbolic link at the specified path
+// points to in --follow-dir-links mode. The entries in the directory are
+// reported under the path of the link. Links to files, broken links, links to
+// directories that have already been walked, and links to directories on
+// another filesystem in --one-file-system mode are ignored.
+func walkDirLink(
+	conf *config.Config,
+	linkPath string,
+	rootDevice uint64,
+	visited visitedDirs,
+	ignored *ignoreRules,
+	walkFn fs.WalkDirFunc,
+) error {
+	targetInfo, err := os.Stat(linkPath)
+	if err != nil || !targetInfo.IsDir() {
+		//nolint:nilerr // broken links are ignored
+		return nil
+	}
+
+	if isOtherDevice(conf, targetInfo, rootDevice) {
+		return nil
+	}
+
+	if conf.ExcludeDirRegex != nil &&
+		conf.ExcludeDirRegex.MatchString(filepath.Base(linkPath)) {
+		return nil
+	}
+
+	seen, err := visited.visit(linkPath)
+	if err != nil || seen {
+		return err
+	}
+
+	if err = ignored.load(linkPath); err != nil {
+		return err
+	}
+
+	// The trailing separator causes the link to be resolved by
+	// filepath.WalkDir instead of being reported as a file
+	linkRoot := linkPath + string(os.PathSeparator)
+
+	return filepath.WalkDir(
+		linkRoot,
+		func(currentPath string, entry fs.DirEntry, err error) error {
+			if currentPath == linkRoot {
+				return err
+			}
+
+			return walkFn(currentPath, entry, err)
+		},
+	)
+}
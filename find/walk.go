@@ -0,0 +1,267 @@
+package find
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ayoisaiah/f2/v2/internal/config"
+	"github.com/ayoisaiah/f2/v2/report"
+)
+
+// walkFilter decides which entries are skipped while walking a root
+// directory because they are hidden, ignored, excluded, too deep, or on
+// another filesystem.
+type walkFilter struct {
+	conf    *config.Config
+	ignored *ignoreRules
+	// paths that were provided as arguments, which are included even if they
+	// are hidden
+	paths      []string
+	rootPath   string
+	maxDepth   int
+	rootDevice uint64
+}
+
+// newWalkFilter returns the filter for walking the root directory.
+func newWalkFilter(
+	conf *config.Config,
+	rootPath string,
+	rootInfo fs.FileInfo,
+	paths []string,
+) (*walkFilter, error) {
+	ignored, err := newIgnoreRules(conf, rootPath)
+	if err != nil {
+		return nil, err
+	}
+
+	maxDepth := -1 // default value for non-recursive iterations
+	if conf.Recursive {
+		maxDepth = conf.MaxDepth
+	}
+
+	rootDevice, _ := deviceID(rootInfo)
+
+	return &walkFilter{
+		conf:       conf,
+		ignored:    ignored,
+		paths:      paths,
+		rootPath:   rootPath,
+		maxDepth:   maxDepth,
+		rootDevice: rootDevice,
+	}, nil
+}
+
+// skip reports whether the entry is skipped along with the error that the
+// walk function returns for it, which is fs.SkipDir when the rest of the
+// directory is skipped. In --depth mode, the entries above the exact depth
+// are walked but not matched, which is reported through atDepth.
+func (w *walkFilter) skip(
+	path string,
+	entry fs.DirEntry,
+) (skip, atDepth bool, err error) {
+	conf := w.conf
+
+	skipEntry := func() (bool, bool, error) {
+		if entry.IsDir() {
+			return true, false, fs.SkipDir
+		}
+
+		return true, false, nil
+	}
+
+	includeHidden := conf.IncludeHiddenFiles
+	if entry.IsDir() {
+		includeHidden = conf.IncludeHiddenDirs
+	}
+
+	if skipHidden, hiddenErr := skipFileIfHidden(
+		path,
+		w.paths,
+		includeHidden,
+	); hiddenErr != nil {
+		return true, false, hiddenErr
+	} else if skipHidden {
+		report.Debug(conf, "skipping '%s' (hidden)", path)
+
+		return skipEntry()
+	}
+
+	if w.ignored.skip(path, entry.IsDir()) {
+		report.Debug(conf, "skipping '%s' (ignored)", path)
+
+		return skipEntry()
+	}
+
+	if entry.IsDir() && conf.Recursive &&
+		conf.ExcludeDirRegex != nil {
+		if conf.ExcludeDirRegex.MatchString(entry.Name()) {
+			report.Debug(
+				conf,
+				"skipping '%s' (matches --exclude-dir)",
+				path,
+			)
+
+			return true, false, fs.SkipDir
+		}
+	}
+
+	if isMaxDepth(w.rootPath, path, w.maxDepth) {
+		return true, false, fs.SkipDir
+	}
+
+	// In --depth mode, entries above the exact depth are walked but not
+	// matched while entries below it are skipped entirely
+	atDepth = true
+
+	if conf.Depth > 0 {
+		depth := entryDepth(w.rootPath, path)
+		if depth > conf.Depth {
+			return skipEntry()
+		}
+
+		atDepth = depth == conf.Depth
+	}
+
+	// Mount points are skipped along with their contents
+	if entry.IsDir() && conf.OneFileSystem {
+		dirInfo, infoErr := entry.Info()
+		if infoErr != nil {
+			return true, false, infoErr
+		}
+
+		if isOtherDevice(conf, dirInfo, w.rootDevice) {
+			report.Debug(
+				conf,
+				"skipping '%s' (on another filesystem)",
+				path,
+			)
+
+			return true, false, fs.SkipDir
+		}
+	}
+
+	return false, atDepth, nil
+}
+
+// WatchDirs returns the directories in which new entries are reached by the
+// search of the root directories (--watch). These are the roots along with
+// the subdirectories that are walked in recursive mode.
+func WatchDirs(conf *config.Config, roots []string) ([]string, error) {
+	var dirs []string
+
+	for _, rootPath := range roots {
+		rootPath = filepath.Clean(rootPath)
+
+		rootInfo, err := os.Stat(rootPath)
+		if err != nil {
+			return nil, err
+		}
+
+		dirs = append(dirs, rootPath)
+
+		if !conf.Recursive {
+			continue
+		}
+
+		filter, err := newWalkFilter(conf, rootPath, rootInfo, roots)
+		if err != nil {
+			return nil, err
+		}
+
+		err = filepath.WalkDir(
+			rootPath,
+			func(path string, entry fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+
+				if path == rootPath || !entry.IsDir() {
+					return nil
+				}
+
+				skip, _, skipErr := filter.skip(path, entry)
+				if skip {
+					return skipErr
+				}
+
+				dirs = append(dirs, path)
+
+				return filter.ignored.load(path)
+			},
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return dirs, nil
+}
+
+// Walked reports whether the search of the root directories walks the
+// directory at the path, or reaches the file at it at a depth where it can be
+// matched (--watch). This is not the case when the entry or one of the
+// directories that lead to it is hidden, ignored, excluded, too deep, or on
+// another filesystem.
+func Walked(conf *config.Config, roots []string, path string) (bool, error) {
+	path = filepath.Clean(path)
+
+	for _, rootPath := range roots {
+		rootPath = filepath.Clean(rootPath)
+
+		rel, err := filepath.Rel(rootPath, path)
+		if err != nil || rel == "." || rel == ".." ||
+			strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+
+		rootInfo, err := os.Stat(rootPath)
+		if err != nil {
+			return false, err
+		}
+
+		filter, err := newWalkFilter(conf, rootPath, rootInfo, roots)
+		if err != nil {
+			return false, err
+		}
+
+		// Each directory from the root to the entry is checked in the order
+		// that the search walks it
+		current := rootPath
+		parts := strings.Split(rel, string(filepath.Separator))
+
+		for i, part := range parts {
+			current = filepath.Join(current, part)
+
+			info, err := os.Lstat(current)
+			if err != nil {
+				return false, err
+			}
+
+			skip, atDepth, skipErr := filter.skip(
+				current,
+				fs.FileInfoToDirEntry(info),
+			)
+			if skipErr != nil && !errors.Is(skipErr, fs.SkipDir) {
+				return false, skipErr
+			}
+
+			if skip {
+				return false, nil
+			}
+
+			if i == len(parts)-1 {
+				return info.IsDir() || atDepth, nil
+			}
+
+			err = filter.ignored.load(current)
+			if err != nil {
+				return false, err
+			}
+		}
+	}
+
+	return false, nil
+}
@@ -1,11 +1,17 @@
 package apperr
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+)
 
 type Error struct {
 	Cause   error
 	Context any
 	Message string
+	// ExitCode is the code that the program exits with when the error is
+	// returned. The generic error code is used if it is zero.
+	ExitCode int
 }
 
 func (e *Error) Error() string {
@@ -38,3 +44,14 @@ func (e *Error) WithCtx(ctx any) *Error {
 	e.Context = ctx
 	return e
 }
+
+// ExitCode returns the exit code of the first error in the chain that sets
+// one, or zero if none does.
+func ExitCode(err error) int {
+	var appErr *Error
+	if errors.As(err, &appErr) && appErr.ExitCode != 0 {
+		return appErr.ExitCode
+	}
+
+	return 0
+}
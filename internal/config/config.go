@@ -3,13 +3,15 @@
 package config
 
 import (
-	"crypto/md5"
+	"bufio"
 	"encoding/json"
-	"fmt"
+	"errors"
 	"io"
 	"os"
 	"path/filepath"
 	"regexp"
+	"regexp/syntax"
+	"runtime"
 	"strings"
 	"time"
 
@@ -20,6 +22,8 @@ import (
 	"github.com/urfave/cli/v2"
 
 	"github.com/ayoisaiah/f2/v2/internal/file"
+	"github.com/ayoisaiah/f2/v2/internal/osutil"
+	"github.com/ayoisaiah/f2/v2/internal/pattern"
 )
 
 const (
@@ -44,6 +48,9 @@ var (
 	customFixConfictsPatternRegex   = regexp.MustCompile(
 		`^(\D*?(%(\d+)?d)\D*?)$`,
 	)
+	numberSkipRegex = regexp.MustCompile(
+		`^(?:\d+(?:-\d+)?|existing)(?:;\s*(?:\d+(?:-\d+)?|existing))*$`,
+	)
 )
 
 var conf *Config
@@ -57,9 +64,82 @@ type ExiftoolOpts struct {
 	ExtractEmbedded bool   `long:"extractEmbedded" json:"extract_embedded"` // corresponds to the `-extractEmbedded` flag
 }
 
+// The types of operation that are performed on the matched files. Backups
+// created by earlier versions do not record the type of operation and are
+// treated as renaming operations.
+const (
+	OperationRename = "rename"
+	// OperationCopy copies the matched files to their new names (--copy)
+	OperationCopy = "copy"
+	// OperationSymlink and OperationHardlink create links to the matched
+	// files at their new names (--link)
+	OperationSymlink  = "symlink"
+	OperationHardlink = "hardlink"
+)
+
 type Backup struct {
 	Changes     file.Changes `json:"changes"`
 	CleanedDirs []string     `json:"cleaned_dirs,omitempty"`
+	// Operation is the type of operation that was performed so that it is
+	// reverted in the right way
+	Operation string `json:"operation,omitempty"`
+	// WorkingDir, Find, Replace, and CSV describe the operation when the
+	// history is listed
+	WorkingDir string   `json:"working_dir,omitempty"`
+	CSV        string   `json:"csv,omitempty"`
+	Find       []string `json:"find,omitempty"`
+	Replace    []string `json:"replace,omitempty"`
+	// Overwritten contains the paths of the files that were replaced with
+	// --allow-overwrites. They cannot be restored when the operation is undone
+	Overwritten []string `json:"overwritten,omitempty"`
+	// Files contains the state of each renamed file keyed by its new path so
+	// that the files which were modified afterwards can be detected when the
+	// operation is undone
+	Files map[string]FileState `json:"files,omitempty"`
+}
+
+// KeepsSources reports whether the operation leaves the matched files in
+// place, in which case their paths are not freed for other targets.
+func KeepsSources(operation string) bool {
+	return operation != "" && operation != OperationRename
+}
+
+// CheckReversible returns an error if the operation in the backup cannot be
+// undone or redone by this version.
+func (b *Backup) CheckReversible() error {
+	switch b.Operation {
+	case "", OperationRename, OperationCopy, OperationSymlink, OperationHardlink:
+		return nil
+	}
+
+	return errIrreversibleOperation.Fmt(b.Operation)
+}
+
+// FileState is the size and checksum of a file after it was renamed.
+type FileState struct {
+	Checksum string `json:"checksum"`
+	Size     int64  `json:"size"`
+}
+
+// Modified reports whether the contents of the file at the specified path
+// differ from the recorded state. The checksum is only computed if the size
+// is unchanged.
+func (s FileState) Modified(path string) (bool, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false, err
+	}
+
+	if info.Size() != s.Size {
+		return true, nil
+	}
+
+	checksum, err := osutil.Checksum(path)
+	if err != nil {
+		return false, err
+	}
+
+	return checksum != s.Checksum, nil
 }
 
 func (b Backup) RenderJSON(w io.Writer) error {
@@ -77,7 +157,9 @@ func (b Backup) RenderJSON(w io.Writer) error {
 }
 
 type Search struct {
-	Regex *regexp.Regexp `json:"regex"`
+	Regex pattern.Regexp `json:"regex"`
+	// Literal find string matched approximately in --fuzzy mode
+	Fuzzy string `json:"fuzzy"`
 	// Replacement index
 	Index int `json:"index"`
 }
@@ -85,55 +167,207 @@ type Search struct {
 // Config represents the program configuration.
 type Config struct {
 	Date                     time.Time      `json:"date"`
+	NewerThan                time.Time      `json:"newer_than"`
+	OlderThan                time.Time      `json:"older_than"`
 	BackupLocation           io.Writer      `json:"-"`
 	ExcludeDirRegex          *regexp.Regexp `json:"exclude_dir_regex"`
 	ExcludeRegex             *regexp.Regexp `json:"exclude_regex"`
+	InvertRegex              pattern.Regexp `json:"invert_regex"`
+	GrepRegex                *regexp.Regexp `json:"grep_regex"`
 	Search                   *Search        `json:"search_regex"`
 	FixConflictsPatternRegex *regexp.Regexp `json:"fix_conflicts_pattern_regex"`
 	Replacement              string         `json:"replacement"`
 	WorkingDir               string         `json:"working_dir"`
 	FixConflictsPattern      string         `json:"fix_conflicts_pattern"`
+	FixEmpty                 string         `json:"fix_empty"`
 	CSVFilename              string         `json:"csv_filename"`
 	BackupFilename           string         `json:"backup_filename"`
 	TargetDir                string         `json:"target_dir"`
 	SortVariable             string         `json:"sort_variable"`
+	FilterTime               string         `json:"filter_time"`
+	NumberSkip               string         `json:"number_skip"`
+	OwnedBy                  string         `json:"owned_by"`
 	ExiftoolOpts             ExiftoolOpts   `json:"exiftool_opts"`
 	PairOrder                []string       `json:"pair_order"`
+	Extensions               []string       `json:"extensions"`
+	SkipNumbers              []NumberRange  `json:"skip_numbers"`
+	Where                    []Predicate    `json:"where"`
 	FindSlice                []string       `json:"find_slice"`
 	FilesAndDirPaths         []string       `json:"files_and_dir_paths"`
 	ReplacementSlice         []string       `json:"replacement_slice"`
+	MinSize                  int64          `json:"min_size"`
+	MaxSize                  int64          `json:"max_size"`
+	GrepLimit                int64          `json:"grep_limit"`
 	ReplaceLimit             int            `json:"replace_limit"`
+	ReplaceNth               int            `json:"replace_nth"`
 	StartNumber              int            `json:"start_number"`
+	IndexStep                int            `json:"index_step"`
 	MaxDepth                 int            `json:"max_depth"`
+	Depth                    int            `json:"depth"`
+	PadNum                   int            `json:"pad_num"`
+	FuzzyDistance            int            `json:"fuzzy_distance"`
 	Sort                     Sort           `json:"sort"`
+	Symlinks                 Symlinks       `json:"symlinks"`
+	Traversal                Traversal      `json:"traversal"`
+	TargetOS                 string         `json:"target_os"`
+	Normalize                string         `json:"normalize"`
 	Revert                   bool           `json:"revert"`
+	Redo                     bool           `json:"redo"`
+	ListHistory              bool           `json:"list_history"`
+	PruneHistory             bool           `json:"prune_history"`
+	HistoryLimit             int            `json:"history_limit"`
+	HistoryExpiry            time.Time      `json:"history_expiry"`
+	UndoID                   string         `json:"undo_id"`
+	UndoLast                 int            `json:"undo_last"`
 	IncludeDir               bool           `json:"include_dir"`
 	IgnoreExt                bool           `json:"ignore_ext"`
 	IgnoreCase               bool           `json:"ignore_case"`
 	Verbose                  bool           `json:"verbose"`
+	Summary                  bool           `json:"summary"`
 	IncludeHidden            bool           `json:"include_hidden"`
+	IncludeHiddenDirs        bool           `json:"include_hidden_dirs"`
+	IncludeHiddenFiles       bool           `json:"include_hidden_files"`
 	Quiet                    bool           `json:"quiet"`
 	NoColor                  bool           `json:"no_color"`
 	AutoFixConflicts         bool           `json:"auto_fix_conflicts"`
 	Exec                     bool           `json:"exec"`
 	StringLiteralMode        bool           `json:"string_literal_mode"`
+	GlobMode                 bool           `json:"glob_mode"`
+	MatchPath                bool           `json:"match_path"`
+	InvertMatch              bool           `json:"invert_match"`
+	RespectGitignore         bool           `json:"respect_gitignore"`
 	JSON                     bool           `json:"json"`
+	CSVOutput                bool           `json:"csv_output"`
+	Print0                   bool           `json:"print0"`
 	Debug                    bool           `json:"debug"`
 	Recursive                bool           `json:"recursive"`
+	FollowDirLinks           bool           `json:"follow_dir_links"`
+	OneFileSystem            bool           `json:"one_file_system"`
+	WritableOnly             bool           `json:"writable_only"`
 	ResetIndexPerDir         bool           `json:"reset_index_per_dir"`
+	Renumber                 bool           `json:"renumber"`
+	ReportDuplicates         bool           `json:"report_duplicates"`
+	ReportConflicts          bool           `json:"report_conflicts"`
+	Check                    bool           `json:"check"`
+	FixExt                   bool           `json:"fix_ext"`
+	ResumeIndex              bool           `json:"resume_index"`
+	SkipExistingNumbers      bool           `json:"skip_existing_numbers"`
 	OnlyDir                  bool           `json:"only_dir"`
 	PipeOutput               bool           `json:"is_output_to_pipe"`
 	ReverseSort              bool           `json:"reverse_sort"`
 	AllowOverwrites          bool           `json:"allow_overwrites"`
+	AllowModified            bool           `json:"allow_modified"`
+	LongPaths                bool           `json:"long_paths"`
+	CaseInsensitiveFS        bool           `json:"case_insensitive_fs"`
+	AbortOnError             bool           `json:"abort_on_error"`
+	RollbackOnError          bool           `json:"rollback_on_error"`
+	Workers                  int            `json:"workers"`
+	Throttle                 time.Duration  `json:"throttle"`
+	Retries                  int            `json:"retries"`
+	Verify                   bool           `json:"verify"`
+	VerifyChecksum           bool           `json:"verify_checksum"`
+	NoPreserve               bool           `json:"no_preserve"`
+	PreserveOwner            bool           `json:"preserve_owner"`
+	Trash                    bool           `json:"trash"`
+	TwoPhase                 bool           `json:"two_phase"`
+	Interactive              bool           `json:"interactive"`
+	TUI                      bool           `json:"tui"`
+	NoLock                   bool           `json:"no_lock"`
+	BackupMode               string         `json:"backup_mode"`
+	BackupSuffix             string         `json:"backup_suffix"`
+	BackupDir                string         `json:"backup_dir"`
+	Git                      bool           `json:"git"`
+	Watch                    bool           `json:"watch"`
+	Operation                string         `json:"operation"`
+	PCRE                     bool           `json:"pcre"`
 	Pair                     bool           `json:"pair"`
 	SortPerDir               bool           `json:"sort_per_dir"`
 	Clean                    bool           `json:"clean"`
 }
 
+// globToRegex converts a shell-style glob pattern into an anchored regular
+// expression. Each wildcard (* and ?) becomes a capture group so that the
+// matched text can be referenced in the replacement (e.g. $1).
+func globToRegex(pattern string) string {
+	var sb strings.Builder
+
+	sb.WriteString("^")
+
+	runes := []rune(pattern)
+
+	for i := 0; i < len(runes); i++ {
+		switch r := runes[i]; r {
+		case '*':
+			sb.WriteString("(.*)")
+		case '?':
+			sb.WriteString("(.)")
+		case '[':
+			end := i + 1
+			if end < len(runes) && (runes[end] == '!' || runes[end] == '^') {
+				end++
+			}
+
+			// The first character after the opening bracket may be a literal ]
+			if end < len(runes) && runes[end] == ']' {
+				end++
+			}
+
+			for end < len(runes) && runes[end] != ']' {
+				end++
+			}
+
+			if end >= len(runes) {
+				sb.WriteString(regexp.QuoteMeta(string(r)))
+				continue
+			}
+
+			class := string(runes[i+1 : end])
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+
+			sb.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+
+			i = end
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+
+	sb.WriteString("$")
+
+	return sb.String()
+}
+
+// isUnsupportedRegexSyntax reports whether a regex compilation error was
+// caused by lookarounds or backreferences which are valid in PCRE but not
+// supported by Go's RE2 engine.
+func isUnsupportedRegexSyntax(err error) bool {
+	var syntaxErr *syntax.Error
+	if !errors.As(err, &syntaxErr) {
+		return false
+	}
+
+	//nolint:exhaustive // only lookarounds and backreferences are relevant
+	switch syntaxErr.Code {
+	case syntax.ErrInvalidPerlOp, syntax.ErrInvalidNamedCapture:
+		for _, prefix := range []string{"(?=", "(?!", "(?<=", "(?<!"} {
+			if strings.HasPrefix(syntaxErr.Expr, prefix) {
+				return true
+			}
+		}
+	case syntax.ErrInvalidEscape:
+		return len(syntaxErr.Expr) == 2 &&
+			syntaxErr.Expr[1] >= '1' && syntaxErr.Expr[1] <= '9'
+	}
+
+	return false
+}
+
 // SetFindStringRegex compiles a regular expression for the
 // find string of the corresponding replacement index (if any).
 // Otherwise, the created regex will match the entire file name.
-// It takes into account the StringLiteralMode and IgnoreCase options.
+// It takes into account the StringLiteralMode, GlobMode and IgnoreCase options.
 //
 // If a find string exists for the given replacementIndex, it's used as the pattern.
 // Otherwise, the pattern defaults to ".*" to match the entire file name.
@@ -148,8 +382,10 @@ func (c *Config) SetFindStringRegex(replacementIndex int) error {
 		findPattern = c.FindSlice[replacementIndex]
 
 		// Escape all regular expression metacharacters in string literal mode
-		if c.StringLiteralMode {
+		if c.StringLiteralMode || c.FuzzyDistance > 0 {
 			findPattern = regexp.QuoteMeta(findPattern)
+		} else if c.GlobMode {
+			findPattern = globToRegex(findPattern)
 		}
 
 		if c.IgnoreCase {
@@ -157,16 +393,41 @@ func (c *Config) SetFindStringRegex(replacementIndex int) error {
 		}
 	}
 
-	re, err := regexp.Compile(findPattern)
+	var re pattern.Regexp
+
+	var err error
+
+	if c.PCRE {
+		re, err = pattern.CompilePCRE(findPattern)
+	} else {
+		re, err = regexp.Compile(findPattern)
+	}
+
 	if err != nil {
+		if isUnsupportedRegexSyntax(err) {
+			return errUnsupportedRegexSyntax.Fmt(findPattern).Wrap(err)
+		}
+
 		return err
 	}
 
+	// In invert mode, the first find pattern selects the files to skip while
+	// the replacement is applied to the entire file name
+	if c.InvertMatch && replacementIndex == 0 && len(c.FindSlice) > 0 {
+		c.InvertRegex = re
+
+		re = regexp.MustCompile(".*")
+	}
+
 	c.Search = &Search{
 		Regex: re,
 		Index: replacementIndex,
 	}
 
+	if c.FuzzyDistance > 0 && len(c.FindSlice) > replacementIndex {
+		c.Search.Fuzzy = c.FindSlice[replacementIndex]
+	}
+
 	return nil
 }
 
@@ -174,14 +435,38 @@ func (c *Config) setOptions(ctx *cli.Context) error {
 	if len(ctx.StringSlice("find")) == 0 &&
 		len(ctx.StringSlice("replace")) == 0 &&
 		ctx.String("csv") == "" &&
-		!ctx.Bool("undo") {
+		ctx.Uint("pad-num") == 0 &&
+		!ctx.Bool("renumber") &&
+		!ctx.Bool("fix-ext") &&
+		!ctx.Bool("report-duplicates") &&
+		!ctx.Bool("undo") &&
+		!ctx.Bool("redo") &&
+		!ctx.Bool("history") &&
+		!ctx.Bool("prune-history") &&
+		ctx.String("undo-id") == "" &&
+		ctx.Uint("undo-last") == 0 {
 		return errInvalidArgument
 	}
 
+	if (ctx.Bool("undo") || ctx.String("undo-id") != "" ||
+		ctx.Uint("undo-last") > 0) && ctx.Bool("redo") {
+		return errConflictingUndoRedo
+	}
+
+	if ctx.String("undo-id") != "" && ctx.Uint("undo-last") > 0 {
+		return errConflictingUndoLast
+	}
+
 	c.FindSlice = ctx.StringSlice("find")
 	c.ReplacementSlice = ctx.StringSlice("replace")
 	c.CSVFilename = ctx.String("csv")
-	c.Revert = ctx.Bool("undo")
+	c.UndoID = ctx.String("undo-id")
+	//nolint:gosec // acceptable use
+	c.UndoLast = int(ctx.Uint("undo-last"))
+	c.Revert = ctx.Bool("undo") || c.UndoID != "" || c.UndoLast > 0
+	c.Redo = ctx.Bool("redo")
+	c.ListHistory = ctx.Bool("history")
+	c.PruneHistory = ctx.Bool("prune-history")
 	c.Debug = ctx.Bool("debug")
 	c.FilesAndDirPaths = ctx.Args().Slice()
 	c.TargetDir = ctx.String("target-dir")
@@ -190,6 +475,78 @@ func (c *Config) setOptions(ctx *cli.Context) error {
 	c.PairOrder = strings.Split(ctx.String("pair-order"), ",")
 	c.Clean = ctx.Bool("clean")
 	c.SortVariable = ctx.String("sort-var")
+	//nolint:gosec // acceptable use
+	c.PadNum = int(ctx.Uint("pad-num"))
+	c.Renumber = ctx.Bool("renumber")
+	c.FixExt = ctx.Bool("fix-ext")
+	c.ReportDuplicates = ctx.Bool("report-duplicates")
+	c.ReportConflicts = ctx.Bool("report-conflicts")
+	c.Check = ctx.Bool("check")
+
+	c.Operation = OperationRename
+
+	if ctx.Bool("copy") {
+		c.Operation = OperationCopy
+
+		if c.Revert || c.Redo {
+			return errOperationWithUndo.Fmt("copy")
+		}
+
+		// Copies of directories cannot be removed safely when the operation
+		// is undone
+		if c.IncludeDir {
+			return errCopyDirs
+		}
+	}
+
+	if ctx.String("link") != "" {
+		if c.Operation == OperationCopy {
+			return errConflictingCopyLink
+		}
+
+		if c.Revert || c.Redo {
+			return errOperationWithUndo.Fmt("link")
+		}
+
+		var err error
+
+		c.Operation, err = parseLinkArg(ctx.String("link"))
+		if err != nil {
+			return err
+		}
+
+		if c.Operation == OperationHardlink && c.IncludeDir {
+			return errHardlinkDirs
+		}
+	}
+
+	c.Watch = ctx.Bool("watch")
+
+	if c.Watch {
+		if c.Revert || c.Redo {
+			return errOperationWithUndo.Fmt("watch")
+		}
+
+		if c.CSVFilename != "" {
+			return errWatchCSV
+		}
+	}
+
+	// Match all the numbers in the file name when padding or renumbering
+	// without an explicit find or replacement pattern
+	if (c.PadNum > 0 || c.Renumber) && len(c.FindSlice) == 0 &&
+		len(c.ReplacementSlice) == 0 && c.CSVFilename == "" {
+		c.FindSlice = []string{`\d+`}
+		c.ReplacementSlice = []string{"${0}"}
+	}
+
+	// Match every file name when fixing extensions or reporting duplicates
+	// without an explicit find or replacement pattern
+	if (c.FixExt || c.ReportDuplicates) && len(c.FindSlice) == 0 &&
+		len(c.ReplacementSlice) == 0 && c.CSVFilename == "" {
+		c.FindSlice = []string{".*"}
+		c.ReplacementSlice = []string{"${0}"}
+	}
 
 	if c.SortVariable != "" && !sortVarRegex.MatchString(c.SortVariable) {
 		return errInvalidSortVariable.Fmt(c.SortVariable)
@@ -219,6 +576,15 @@ func (c *Config) setOptions(ctx *cli.Context) error {
 		c.FilesAndDirPaths = ctx.Args().Slice()
 	}
 
+	if ctx.String("from-file") != "" {
+		paths, err := readPathList(ctx.String("from-file"))
+		if err != nil {
+			return err
+		}
+
+		c.FilesAndDirPaths = append(c.FilesAndDirPaths, paths...)
+	}
+
 	// Default to the current working directory if no path arguments are provided
 	if len(c.FilesAndDirPaths) == 0 {
 		c.FilesAndDirPaths = append(c.FilesAndDirPaths, DefaultWorkingDir)
@@ -233,41 +599,174 @@ func (c *Config) setOptions(ctx *cli.Context) error {
 	return c.SetFindStringRegex(0)
 }
 
+// readPathList reads the newline or NUL-delimited list of paths in the
+// specified file. Empty entries are ignored.
+func readPathList(pathList string) ([]string, error) {
+	f, err := os.Open(pathList)
+	if err != nil {
+		return nil, errReadingPathList.Fmt(pathList).Wrap(err)
+	}
+
+	defer f.Close()
+
+	var paths []string
+
+	scanner := bufio.NewScanner(f)
+	scanner.Split(osutil.ScanPaths())
+
+	for scanner.Scan() {
+		if scanner.Text() != "" {
+			paths = append(paths, scanner.Text())
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, errReadingPathList.Fmt(pathList).Wrap(err)
+	}
+
+	return paths, nil
+}
+
+// fixConflictsPatternRegex returns the regular expression that matches the
+// number added to the end of a file name with a custom conflict pattern. The
+// text around the number is matched literally.
+func fixConflictsPatternRegex(pattern string) *regexp.Regexp {
+	verb := customFixConfictsPatternRegex.FindStringSubmatch(pattern)[2]
+	prefix, suffix, _ := strings.Cut(pattern, verb)
+
+	return regexp.MustCompile(
+		regexp.QuoteMeta(prefix) + `(\d+)` + regexp.QuoteMeta(suffix) + `$`,
+	)
+}
+
 // setDefaultOpts applies any options that may be set through
 // F2_DEFAULT_OPTS.
 func (c *Config) setDefaultOpts(ctx *cli.Context) error {
 	c.AutoFixConflicts = ctx.Bool("fix-conflicts")
 	c.IncludeDir = ctx.Bool("include-dir")
 	c.IncludeHidden = ctx.Bool("hidden")
+	c.IncludeHiddenDirs = c.IncludeHidden || ctx.Bool("hidden-dirs")
+	c.IncludeHiddenFiles = c.IncludeHidden || ctx.Bool("hidden-files")
 	c.IgnoreCase = ctx.Bool("ignore-case")
 	c.IgnoreExt = ctx.Bool("ignore-ext")
 	c.Recursive = ctx.Bool("recursive")
+	c.FollowDirLinks = ctx.Bool("follow-dir-links")
+	c.OneFileSystem = ctx.Bool("one-file-system")
+	c.WritableOnly = ctx.Bool("writable-only")
 	c.OnlyDir = ctx.Bool("only-dir")
 	c.StringLiteralMode = ctx.Bool("string-mode")
+	c.PCRE = ctx.Bool("pcre")
+	c.GlobMode = ctx.Bool("glob")
+	//nolint:gosec // acceptable use
+	c.FuzzyDistance = int(ctx.Uint("fuzzy"))
+	c.MatchPath = ctx.Bool("match-path")
+	c.InvertMatch = ctx.Bool("invert")
+	c.RespectGitignore = ctx.Bool("respect-gitignore")
 	//nolint:gosec // acceptable use
 	c.MaxDepth = int(ctx.Uint("max-depth"))
+	//nolint:gosec // acceptable use
+	c.Depth = int(ctx.Uint("depth"))
+
+	// Entries at an exact depth can only be found by searching recursively
+	if c.Depth > 0 {
+		c.Recursive = true
+	}
 	c.Verbose = ctx.Bool("verbose")
+	c.Summary = ctx.Bool("summary")
 	c.AllowOverwrites = ctx.Bool("allow-overwrites")
+	c.AllowModified = ctx.Bool("allow-modified")
+	c.LongPaths = ctx.Bool("long-paths")
+	c.CaseInsensitiveFS = ctx.Bool("ci-fs")
 	c.ReplaceLimit = ctx.Int("replace-limit")
+	c.ReplaceNth = ctx.Int("replace-nth")
+	c.IndexStep = ctx.Int("step")
+	c.NumberSkip = ctx.String("number-skip")
 	c.Quiet = ctx.Bool("quiet")
 	c.JSON = ctx.Bool("json")
+	c.CSVOutput = ctx.Bool("csv-output")
+	c.Print0 = ctx.Bool("print0")
 	c.Exec = ctx.Bool("exec")
 	c.FixConflictsPattern = ctx.String("fix-conflicts-pattern")
 	c.ResetIndexPerDir = ctx.Bool("reset-index-per-dir")
+	c.ResumeIndex = ctx.Bool("resume-index")
 	c.NoColor = ctx.Bool("no-color")
+	c.NoPreserve = ctx.Bool("no-preserve")
+	c.PreserveOwner = ctx.Bool("preserve-owner")
+	c.Trash = ctx.Bool("trash")
+	c.TwoPhase = ctx.Bool("two-phase")
+	c.Interactive = ctx.Bool("interactive")
+	c.TUI = ctx.Bool("tui")
+	c.NoLock = ctx.Bool("no-lock")
+	c.VerifyChecksum = ctx.Bool("verify-checksum")
+	c.Verify = ctx.Bool("verify") || c.VerifyChecksum
+	c.Git = ctx.Bool("git")
+	//nolint:gosec // acceptable use
+	c.HistoryLimit = int(ctx.Uint("history-limit"))
+	//nolint:gosec // acceptable use
+	c.Workers = max(1, int(ctx.Uint("workers")))
+	//nolint:gosec // acceptable use
+	c.Retries = int(ctx.Uint("retries"))
+
+	if ctx.String("history-max-age") != "" {
+		var err error
+
+		c.HistoryExpiry, err = parseTimeArg(
+			"history-max-age",
+			ctx.String("history-max-age"),
+			c.Date,
+		)
+		if err != nil {
+			return err
+		}
+	}
 
 	if c.FixConflictsPattern == "" {
 		c.FixConflictsPattern = DefaultFixConflictsPattern
 		c.FixConflictsPatternRegex = defaultFixConflictsPatternRegex
 	} else if !customFixConfictsPatternRegex.MatchString(c.FixConflictsPattern) {
 		return errParsingFixConflictsPattern.Fmt(c.FixConflictsPattern)
+	} else {
+		c.FixConflictsPatternRegex = fixConflictsPatternRegex(
+			c.FixConflictsPattern,
+		)
+	}
+
+	if c.ReplaceNth != 0 && c.ReplaceLimit != 0 {
+		return errConflictingReplaceLimit
+	}
+
+	// Device IDs are not available through the file info on Windows
+	if c.OneFileSystem && runtime.GOOS == osutil.Windows {
+		return errOneFileSystemUnsupported
+	}
+
+	for _, v := range ctx.StringSlice("ext") {
+		for _, ext := range strings.Split(v, ",") {
+			ext = strings.TrimPrefix(strings.TrimSpace(ext), ".")
+			if ext != "" {
+				c.Extensions = append(c.Extensions, strings.ToLower(ext))
+			}
+		}
+	}
+
+	for _, v := range ctx.StringSlice("where") {
+		predicate, err := parseWhereArg(v)
+		if err != nil {
+			return err
+		}
+
+		c.Where = append(c.Where, predicate)
 	}
 
 	excludePattern := ctx.StringSlice("exclude")
 	if len(excludePattern) > 0 {
-		excludeMatchRegex, err := regexp.Compile(
-			strings.Join(excludePattern, "|"),
-		)
+		pattern := strings.Join(excludePattern, "|")
+
+		if c.IgnoreCase {
+			pattern = "(?i)" + pattern
+		}
+
+		excludeMatchRegex, err := regexp.Compile(pattern)
 		if err != nil {
 			return err
 		}
@@ -275,6 +774,21 @@ func (c *Config) setDefaultOpts(ctx *cli.Context) error {
 		c.ExcludeRegex = excludeMatchRegex
 	}
 
+	if ctx.String("grep") != "" {
+		pattern := ctx.String("grep")
+
+		if c.IgnoreCase {
+			pattern = "(?i)" + pattern
+		}
+
+		grepRegex, err := regexp.Compile(pattern)
+		if err != nil {
+			return errInvalidGrep.Fmt(ctx.String("grep")).Wrap(err)
+		}
+
+		c.GrepRegex = grepRegex
+	}
+
 	excludeDirPattern := ctx.StringSlice("exclude-dir")
 	if len(excludeDirPattern) > 0 {
 		excludeDirMatchRegex, err := regexp.Compile(
@@ -307,6 +821,130 @@ func (c *Config) setDefaultOpts(ctx *cli.Context) error {
 		c.ReverseSort = true
 	}
 
+	c.Symlinks, err = parseSymlinksArg(ctx.String("symlinks"))
+	if err != nil {
+		return err
+	}
+
+	c.Traversal, err = parseTraversalArg(ctx.String("traversal"))
+	if err != nil {
+		return err
+	}
+
+	c.TargetOS, err = parseTargetOSArg(ctx.String("target-os"))
+	if err != nil {
+		return err
+	}
+
+	c.Normalize, err = parseNormalizeArg(ctx.String("normalize"))
+	if err != nil {
+		return err
+	}
+
+	c.Throttle, err = parseThrottleArg(ctx.String("throttle"))
+	if err != nil {
+		return err
+	}
+
+	c.BackupMode, c.BackupSuffix, err = parseBackupArg(ctx.String("backup"))
+	if err != nil {
+		return err
+	}
+
+	c.BackupDir = ctx.String("backup-dir")
+
+	// A backup directory implies simple backups
+	if c.BackupDir != "" && c.BackupMode == "" {
+		c.BackupMode, c.BackupSuffix = BackupSimple, DefaultBackupSuffix
+	}
+
+	if c.BackupMode != "" && c.Trash {
+		return errBackupWithTrash
+	}
+
+	if c.CSVOutput && c.JSON {
+		return errCSVOutputWithJSON
+	}
+
+	if c.Print0 && (c.JSON || c.CSVOutput) {
+		return errPrint0WithOutputFormat
+	}
+
+	c.AbortOnError, c.RollbackOnError, err = parseOnErrorArg(
+		ctx.String("on-error"),
+	)
+	if err != nil {
+		return err
+	}
+
+	c.FixEmpty, err = parseFixEmptyArg(ctx.String("fix-empty"))
+	if err != nil {
+		return err
+	}
+
+	c.SkipNumbers, c.SkipExistingNumbers, err = parseNumberSkipArg(c.NumberSkip)
+	if err != nil {
+		return err
+	}
+
+	if ctx.String("min-size") != "" {
+		c.MinSize, err = parseSizeArg("min-size", ctx.String("min-size"))
+		if err != nil {
+			return err
+		}
+	}
+
+	if ctx.String("max-size") != "" {
+		c.MaxSize, err = parseSizeArg("max-size", ctx.String("max-size"))
+		if err != nil {
+			return err
+		}
+	}
+
+	c.GrepLimit, err = parseSizeArg("grep-limit", ctx.String("grep-limit"))
+	if err != nil {
+		return err
+	}
+
+	if ctx.String("newer-than") != "" {
+		c.NewerThan, err = parseTimeArg(
+			"newer-than",
+			ctx.String("newer-than"),
+			c.Date,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	if ctx.String("older-than") != "" {
+		c.OlderThan, err = parseTimeArg(
+			"older-than",
+			ctx.String("older-than"),
+			c.Date,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	if ctx.String("owned-by") != "" {
+		// The owner of a file is not available through the file info on Windows
+		if runtime.GOOS == osutil.Windows {
+			return errOwnedByUnsupported
+		}
+
+		c.OwnedBy, err = parseOwnerArg(ctx.String("owned-by"))
+		if err != nil {
+			return err
+		}
+	}
+
+	c.FilterTime, err = parseFilterTimeArg(ctx.String("filter-time"))
+	if err != nil {
+		return err
+	}
+
 	if ctx.String("exiftool-opts") != "" {
 		args, err := shellquote.Split(ctx.String("exiftool-opts"))
 		if err != nil {
@@ -322,20 +960,19 @@ func (c *Config) setDefaultOpts(ctx *cli.Context) error {
 	return nil
 }
 
-// generateBackupFilename generates a unique filename for storing backup data
-// based on the MD5 hash of the working directory path.
-func generateBackupFilename(workingDir string) string {
-	h := md5.New()
-	h.Write([]byte(workingDir))
-
-	return fmt.Sprintf("%x", h.Sum(nil)) + ".json"
-}
-
 // IsATTY checks if the given file descriptor is associated with a terminal.
 func IsATTY(fd uintptr) bool {
 	return isatty.IsTerminal(fd) || isatty.IsCygwinTerminal(fd)
 }
 
+// StderrIsTerminal reports whether the messages that are written to the
+// standard error are shown in a terminal.
+func StderrIsTerminal() bool {
+	f, ok := Stderr.(*os.File)
+
+	return ok && IsATTY(f.Fd())
+}
+
 // Get retrives an already set config or panics if the configuration
 // has not yet been initialized.
 func Get() *Config {
@@ -346,21 +983,37 @@ func Get() *Config {
 	return conf
 }
 
-// configureOutput configures the output behavior of the application based
-// on environment variables and piping status. All output is suppressed in
-// quiet mode.
-func (c *Config) configureOutput() {
+// ColorDisabled reports whether colored output is turned off by the
+// environment, which is the case when NO_COLOR or F2_NO_COLOR is set, when
+// the terminal does not support colors, or when the standard error is not a
+// terminal such as when it is captured in a log file.
+func ColorDisabled() bool {
 	// Disable coloured output if NO_COLOR is set
 	if _, exists := os.LookupEnv(EnvNoColor); exists {
-		c.NoColor = true
+		return true
 	}
 
 	// Disable coloured output if F2_NO_COLOR is set
 	if _, exists := os.LookupEnv(EnvF2NoColor); exists {
-		c.NoColor = true
+		return true
+	}
+
+	if os.Getenv("TERM") == "dumb" {
+		return true
+	}
+
+	if f, ok := Stderr.(*os.File); ok && !IsATTY(f.Fd()) {
+		return true
 	}
 
-	if c.PipeOutput {
+	return false
+}
+
+// configureOutput configures the output behavior of the application based
+// on environment variables and piping status. All output is suppressed in
+// quiet mode.
+func (c *Config) configureOutput() {
+	if ColorDisabled() || c.PipeOutput {
 		c.NoColor = true
 	}
 
@@ -373,6 +1026,15 @@ func (c *Config) configureOutput() {
 	}
 }
 
+// Renew prepares the configuration for another renaming operation on the
+// specified paths in watch mode so that each operation is recorded separately
+// in the history.
+func (c *Config) Renew(paths []string) {
+	c.Date = time.Now()
+	c.BackupFilename = generateBackupFilename(c.WorkingDir, c.Date)
+	c.FilesAndDirPaths = paths
+}
+
 // Get retrieves the current configuration or panics if not initialized.
 func Init(ctx *cli.Context, pipeOutput bool) (*Config, error) {
 	conf = &Config{
@@ -402,7 +1064,7 @@ func Init(ctx *cli.Context, pipeOutput bool) (*Config, error) {
 		}
 	}
 
-	conf.BackupFilename = generateBackupFilename(conf.WorkingDir)
+	conf.BackupFilename = generateBackupFilename(conf.WorkingDir, conf.Date)
 
 	conf.configureOutput()
 
@@ -4,7 +4,7 @@ import "github.com/ayoisaiah/f2/v2/internal/apperr"
 
 var (
 	errInvalidArgument = &apperr.Error{
-		Message: "requires one of: -f, -r, --csv, or -u. Run f2 --help for usage",
+		Message: "requires one of: -f, -r, --csv, -u, --undo-last, --undo-id, --redo, --history, or --prune-history. Run f2 --help for usage",
 	}
 
 	errParsingFixConflictsPattern = &apperr.Error{
@@ -19,6 +19,150 @@ var (
 		Message: "the provided sort variable '%s' is invalid",
 	}
 
+	errInvalidSymlinks = &apperr.Error{
+		Message: "the provided --symlinks value '%s' is invalid",
+	}
+
+	errInvalidTraversal = &apperr.Error{
+		Message: "the provided --traversal value '%s' is invalid",
+	}
+
+	errInvalidNormalize = &apperr.Error{
+		Message: "the provided --normalize value '%s' is invalid",
+	}
+
+	errInvalidFixEmpty = &apperr.Error{
+		Message: "the provided --fix-empty value '%s' is invalid",
+	}
+
+	errInvalidBackup = &apperr.Error{
+		Message: "the provided --backup suffix '%s' must not contain a path separator",
+	}
+
+	errBackupWithTrash = &apperr.Error{
+		Message: "--backup cannot be used with --trash",
+	}
+
+	errCSVOutputWithJSON = &apperr.Error{
+		Message: "--csv-output cannot be used with --json",
+	}
+
+	errPrint0WithOutputFormat = &apperr.Error{
+		Message: "--print0 cannot be used with --json or --csv-output",
+	}
+
+	errInvalidThrottle = &apperr.Error{
+		Message: "the provided --throttle value '%s' is invalid (use a rate such as 10/s or a delay such as 200ms)",
+	}
+
+	errLocked = &apperr.Error{
+		Message: "another renaming operation is in progress in '%s' (process %s); wait for it to finish or use --no-lock",
+	}
+
+	errInvalidOnError = &apperr.Error{
+		Message: "the provided --on-error value '%s' is invalid",
+	}
+
+	errInvalidTargetOS = &apperr.Error{
+		Message: "the provided --target-os value '%s' is invalid",
+	}
+
+	errInvalidNumberSkip = &apperr.Error{
+		Message: "the provided --number-skip value '%s' is invalid",
+	}
+
+	errUnknownOperation = &apperr.Error{
+		Message: "no operation in the history matches '%s' (see --history)",
+	}
+
+	errNotEnoughHistory = &apperr.Error{
+		Message: "cannot undo the last %d operations since only %d can be undone in this directory",
+	}
+
+	errOperationWithUndo = &apperr.Error{
+		Message: "--%s cannot be used when undoing or redoing an operation",
+	}
+
+	errCopyDirs = &apperr.Error{
+		Message: "--copy does not support directories (-d or -D)",
+	}
+
+	errInvalidLink = &apperr.Error{
+		Message: "the provided --link value '%s' is invalid (expected 'sym' or 'hard')",
+	}
+
+	errConflictingCopyLink = &apperr.Error{
+		Message: "--copy cannot be used with --link",
+	}
+
+	errWatchCSV = &apperr.Error{
+		Message: "--watch cannot be used with --csv",
+	}
+
+	errHardlinkDirs = &apperr.Error{
+		Message: "hard links cannot be created for directories (-d or -D)",
+	}
+
+	errConflictingUndoLast = &apperr.Error{
+		Message: "--undo-last cannot be used with --undo-id",
+	}
+
+	errIrreversibleOperation = &apperr.Error{
+		Message: "cannot revert the '%s' operation recorded in the history with this version of f2",
+	}
+
+	errOperationUndone = &apperr.Error{
+		Message: "the operation '%s' has already been undone",
+	}
+
+	errConflictingUndoRedo = &apperr.Error{
+		Message: "-u/--undo cannot be used with --redo",
+	}
+
+	errConflictingReplaceLimit = &apperr.Error{
+		Message: "--replace-nth cannot be used with -l/--replace-limit",
+	}
+
+	errInvalidSize = &apperr.Error{
+		Message: "the provided --%s value '%s' is not a valid size",
+	}
+
+	errInvalidTime = &apperr.Error{
+		Message: "the provided --%s value '%s' is not a valid date or duration",
+	}
+
+	errInvalidGrep = &apperr.Error{
+		Message: "the provided --grep pattern '%s' is invalid",
+	}
+
+	errOneFileSystemUnsupported = &apperr.Error{
+		Message: "--one-file-system is not supported on Windows",
+	}
+
+	errOwnedByUnsupported = &apperr.Error{
+		Message: "--owned-by is not supported on Windows",
+	}
+
+	errInvalidOwner = &apperr.Error{
+		Message: "the user '%s' provided to --owned-by could not be found",
+	}
+
+	errInvalidFilterTime = &apperr.Error{
+		Message: "the provided --filter-time value '%s' is invalid",
+	}
+
+	errInvalidWhere = &apperr.Error{
+		Message: "the provided --where value '%s' is invalid",
+	}
+
+	errReadingPathList = &apperr.Error{
+		Message: "unable to read the list of paths in '%s'",
+	}
+
+	errUnsupportedRegexSyntax = &apperr.Error{
+		Message: "the find pattern '%s' uses lookarounds or backreferences which are not supported by the RE2 syntax (use --pcre)",
+	}
+
 	errInvalidTargetDir = &apperr.Error{
 		Message: "target path '%s' exists but is not a directory",
 	}
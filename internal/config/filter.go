@@ -0,0 +1,169 @@
+package config
+
+import (
+	"os/user"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/araddon/dateparse"
+
+	"github.com/ayoisaiah/f2/v2/internal/timeutil"
+)
+
+var (
+	sizeArgRegex     = regexp.MustCompile(`^(\d+(?:\.\d+)?)\s*([kmgt]i?)?b?$`)
+	durationArgRegex = regexp.MustCompile(`^(\d+)(d|w)$`)
+	whereArgRegex    = regexp.MustCompile(`^\s*([^=!~]+?)\s*(!=|!~|=|~)\s*(.*?)\s*$`)
+)
+
+// Predicate is a condition provided through --where. The variable is replaced
+// with its value for each matched file before it is compared to the expected
+// value or regular expression.
+type Predicate struct {
+	Regex    *regexp.Regexp `json:"regex"`
+	Variable string         `json:"variable"`
+	Value    string         `json:"value"`
+	Negate   bool           `json:"negate"`
+}
+
+// Match reports whether the actual value of the variable satisfies the
+// predicate.
+func (p *Predicate) Match(actual string) bool {
+	matched := actual == p.Value
+	if p.Regex != nil {
+		matched = p.Regex.MatchString(actual)
+	}
+
+	return matched != p.Negate
+}
+
+// parseWhereArg parses a --where predicate such as 'exif.model=ILCE-7M3'. The
+// left-hand side is a variable with or without the surrounding braces, and
+// the supported operators are =, !=, ~ (regex match), and !~ (regex does not
+// match).
+func parseWhereArg(arg string) (Predicate, error) {
+	submatch := whereArgRegex.FindStringSubmatch(arg)
+	if submatch == nil {
+		return Predicate{}, errInvalidWhere.Fmt(arg)
+	}
+
+	variable, operator, value := submatch[1], submatch[2], submatch[3]
+
+	if !strings.HasPrefix(variable, "{") {
+		variable = "{" + variable + "}"
+	}
+
+	p := Predicate{
+		Variable: variable,
+		Value:    value,
+		Negate:   strings.HasPrefix(operator, "!"),
+	}
+
+	if strings.HasSuffix(operator, "~") {
+		re, err := regexp.Compile(value)
+		if err != nil {
+			return Predicate{}, errInvalidWhere.Fmt(arg).Wrap(err)
+		}
+
+		p.Regex = re
+	}
+
+	return p, nil
+}
+
+// parseSizeArg converts a human-readable size such as 100MB or 1.5GiB into
+// bytes. Units without the "i" use powers of 1000 while units with the "i"
+// (KiB, MiB, GiB, TiB) use powers of 1024.
+func parseSizeArg(flag, arg string) (int64, error) {
+	submatch := sizeArgRegex.FindStringSubmatch(
+		strings.ToLower(strings.TrimSpace(arg)),
+	)
+	if submatch == nil {
+		return 0, errInvalidSize.Fmt(flag, arg)
+	}
+
+	value, err := strconv.ParseFloat(submatch[1], 64)
+	if err != nil {
+		return 0, errInvalidSize.Fmt(flag, arg)
+	}
+
+	unit := submatch[2]
+
+	base := 1000.0
+	if strings.HasSuffix(unit, "i") {
+		base = 1024.0
+	}
+
+	multiplier := 1.0
+
+	if unit != "" {
+		for range strings.Index("kmgt", unit[:1]) + 1 {
+			multiplier *= base
+		}
+	}
+
+	return int64(value * multiplier), nil
+}
+
+// parseTimeArg converts a date (such as 2024-01-15) or a duration relative to
+// now (such as 36h, 7d, or 2w) into an absolute time.
+func parseTimeArg(flag, arg string, now time.Time) (time.Time, error) {
+	arg = strings.TrimSpace(arg)
+
+	if duration, err := time.ParseDuration(arg); err == nil {
+		return now.Add(-duration), nil
+	}
+
+	if submatch := durationArgRegex.FindStringSubmatch(arg); submatch != nil {
+		n, err := strconv.Atoi(submatch[1])
+		if err != nil {
+			return time.Time{}, errInvalidTime.Fmt(flag, arg)
+		}
+
+		days := n
+		if submatch[2] == "w" {
+			days *= 7
+		}
+
+		return now.AddDate(0, 0, -days), nil
+	}
+
+	t, err := dateparse.ParseLocal(arg)
+	if err != nil {
+		return time.Time{}, errInvalidTime.Fmt(flag, arg)
+	}
+
+	return t, nil
+}
+
+// parseFilterTimeArg validates the file time attribute used by the
+// --newer-than and --older-than filters.
+func parseFilterTimeArg(arg string) (string, error) {
+	switch arg {
+	case "", timeutil.Mod:
+		return timeutil.Mod, nil
+	case timeutil.Birth, timeutil.Access, timeutil.Change:
+		return arg, nil
+	}
+
+	return "", errInvalidFilterTime.Fmt(arg)
+}
+
+// parseOwnerArg converts the user name or numeric user ID provided through
+// --owned-by into a user ID.
+func parseOwnerArg(arg string) (string, error) {
+	arg = strings.TrimSpace(arg)
+
+	if _, err := strconv.ParseUint(arg, 10, 32); err == nil {
+		return arg, nil
+	}
+
+	u, err := user.Lookup(arg)
+	if err != nil {
+		return "", errInvalidOwner.Fmt(arg).Wrap(err)
+	}
+
+	return u.Uid, nil
+}
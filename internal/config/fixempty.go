@@ -0,0 +1,29 @@
+package config
+
+import "strings"
+
+const (
+	// FixEmptyKeep leaves files whose new name is empty unchanged.
+	FixEmptyKeep = "keep"
+	// FixEmptySkip skips files whose new name is empty with a warning.
+	FixEmptySkip = "skip"
+)
+
+// parseFixEmptyArg returns the strategy for fixing empty file names
+// (--fix-empty). Values other than 'keep' and 'skip' are templates for the new
+// name, and must contain a single %d placeholder for the index.
+func parseFixEmptyArg(arg string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(arg)) {
+	case "", FixEmptyKeep:
+		return FixEmptyKeep, nil
+	case FixEmptySkip:
+		return FixEmptySkip, nil
+	}
+
+	if !customFixConfictsPatternRegex.MatchString(arg) ||
+		strings.ContainsAny(arg, `/\`) {
+		return "", errInvalidFixEmpty.Fmt(arg)
+	}
+
+	return arg, nil
+}
@@ -0,0 +1,460 @@
+package config
+
+import (
+	"cmp"
+	"crypto/md5"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ayoisaiah/f2/v2/internal/osutil"
+)
+
+// EnvStateHome is the base directory for the state files of applications
+// according to the XDG Base Directory Specification.
+const EnvStateHome = "XDG_STATE_HOME"
+
+const (
+	backupExt = ".json"
+	// undoneBackupExt marks the backup of an operation that was undone so
+	// that it can be redone
+	undoneBackupExt = ".undone.json"
+)
+
+// HistoryDir returns the directory where each renaming operation is recorded
+// so that it can be undone later. This is $XDG_STATE_HOME/f2/history, which
+// defaults to ~/.local/state/f2/history (or %LocalAppData%\f2\history on
+// Windows).
+func HistoryDir() (string, error) {
+	if dir := os.Getenv(EnvStateHome); filepath.IsAbs(dir) {
+		return filepath.Join(dir, "f2", "history"), nil
+	}
+
+	if runtime.GOOS == osutil.Windows {
+		// The cache directory is %LocalAppData% on Windows
+		dir, err := os.UserCacheDir()
+		if err != nil {
+			return "", err
+		}
+
+		return filepath.Join(dir, "f2", "history"), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(home, ".local", "state", "f2", "history"), nil
+}
+
+// historyPrefix returns the prefix of the backup files of the renaming
+// operations performed in the working directory. It is based on the MD5 hash
+// of the working directory path.
+func historyPrefix(workingDir string) string {
+	h := md5.New()
+	h.Write([]byte(workingDir))
+
+	return fmt.Sprintf("%x_", h.Sum(nil))
+}
+
+// generateBackupFilename generates a unique filename for storing the backup
+// data of a renaming operation performed in the working directory at the
+// specified time.
+func generateBackupFilename(workingDir string, date time.Time) string {
+	return historyPrefix(workingDir) + strconv.FormatInt(date.UnixNano(), 10) +
+		backupExt
+}
+
+// legacyBackupPath returns the path where earlier versions stored the backup
+// of the last renaming operation in the working directory.
+func legacyBackupPath(workingDir string) string {
+	return filepath.Join(
+		os.TempDir(),
+		"f2",
+		"backups",
+		strings.TrimSuffix(historyPrefix(workingDir), "_")+".json",
+	)
+}
+
+// historyBackups returns the paths to the backup files with the specified
+// extension of the renaming operations in the working directory, ordered from
+// the oldest to the most recent operation.
+func historyBackups(workingDir, ext string) ([]string, error) {
+	dir, err := HistoryDir()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	prefix := historyPrefix(workingDir)
+
+	times := make(map[string]int64)
+
+	var paths []string
+
+	for _, entry := range entries {
+		stamp, ok := strings.CutPrefix(entry.Name(), prefix)
+		if !ok {
+			continue
+		}
+
+		stamp, ok = strings.CutSuffix(stamp, ext)
+		if !ok {
+			continue
+		}
+
+		t, err := strconv.ParseInt(stamp, 10, 64)
+		if err != nil {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		times[path] = t
+		paths = append(paths, path)
+	}
+
+	slices.SortFunc(paths, func(a, b string) int {
+		return cmp.Compare(times[a], times[b])
+	})
+
+	return paths, nil
+}
+
+// LatestBackup returns the path to the backup file of the most recent
+// renaming operation in the working directory that has not been undone. An
+// empty string is returned if there is none.
+func LatestBackup(workingDir string) (string, error) {
+	backups, err := historyBackups(workingDir, backupExt)
+	if err != nil {
+		return "", err
+	}
+
+	if len(backups) > 0 {
+		return backups[len(backups)-1], nil
+	}
+
+	// Fall back to the backup that was created by an earlier version
+	legacyPath := legacyBackupPath(workingDir)
+
+	if _, err := os.Stat(legacyPath); err == nil {
+		return legacyPath, nil
+	}
+
+	return "", nil
+}
+
+// LastUndoneBackup returns the path to the backup file of the renaming
+// operation in the working directory that was undone last. Operations are
+// undone from the most recent one, so this is the oldest undone operation. An
+// empty string is returned if there is none.
+func LastUndoneBackup(workingDir string) (string, error) {
+	backups, err := historyBackups(workingDir, undoneBackupExt)
+	if err != nil || len(backups) == 0 {
+		return "", err
+	}
+
+	return backups[0], nil
+}
+
+// MarkUndone records that the operation in the backup file was undone so
+// that it can be redone. The backup that was created by an earlier version is
+// removed instead since it is not part of the history.
+func MarkUndone(workingDir, backupPath string) error {
+	if backupPath == legacyBackupPath(workingDir) {
+		return os.Remove(backupPath)
+	}
+
+	return os.Rename(
+		backupPath,
+		strings.TrimSuffix(backupPath, backupExt)+undoneBackupExt,
+	)
+}
+
+// MarkRedone records that the undone operation in the backup file was redone
+// so that it can be undone again.
+func MarkRedone(backupPath string) error {
+	return os.Rename(
+		backupPath,
+		strings.TrimSuffix(backupPath, undoneBackupExt)+backupExt,
+	)
+}
+
+// ClearUndone removes the undone operations in the working directory from
+// the history. This is done when a new operation is performed since the
+// undone operations may no longer apply.
+func ClearUndone(workingDir string) error {
+	backups, err := historyBackups(workingDir, undoneBackupExt)
+	if err != nil {
+		return err
+	}
+
+	for _, path := range backups {
+		err = os.Remove(path)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ReadBackup reads and parses the backup file of a renaming operation.
+func ReadBackup(backupPath string) (*Backup, error) {
+	fileBytes, err := os.ReadFile(backupPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var backup Backup
+
+	err = json.Unmarshal(fileBytes, &backup)
+	if err != nil {
+		return nil, err
+	}
+
+	return &backup, nil
+}
+
+// WriteBackup replaces the contents of the backup file of a renaming
+// operation.
+func WriteBackup(backupPath string, backup *Backup) error {
+	f, err := os.Create(backupPath)
+	if err != nil {
+		return err
+	}
+
+	err = backup.RenderJSON(f)
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// HistoryEntry describes a renaming operation in the history.
+type HistoryEntry struct {
+	Date       time.Time `json:"date"`
+	ID         string    `json:"id"`
+	WorkingDir string    `json:"working_dir"`
+	CSV        string    `json:"csv,omitempty"`
+	Path       string    `json:"-"`
+	Find       []string  `json:"find,omitempty"`
+	Replace    []string  `json:"replace,omitempty"`
+	Files      int       `json:"files"`
+	// Index is the position of the operation in the history starting from 1
+	// for the most recent one
+	Index  int  `json:"index"`
+	Undone bool `json:"undone"`
+}
+
+// parseHistoryName extracts the ID and the time of the operation from the name
+// of a backup file in the history, and reports whether it was undone.
+func parseHistoryName(name string) (id string, date time.Time, undone, ok bool) {
+	_, stamp, found := strings.Cut(name, "_")
+	if !found {
+		return "", time.Time{}, false, false
+	}
+
+	id, undone = strings.CutSuffix(stamp, undoneBackupExt)
+	if !undone {
+		id, found = strings.CutSuffix(stamp, backupExt)
+		if !found {
+			return "", time.Time{}, false, false
+		}
+	}
+
+	t, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return "", time.Time{}, false, false
+	}
+
+	return id, time.Unix(0, t), undone, true
+}
+
+// historyFile is a backup file in the history along with the details of its
+// operation that are encoded in its name.
+type historyFile struct {
+	date   time.Time
+	id     string
+	path   string
+	undone bool
+}
+
+// historyFiles returns the backup files in the history across all
+// directories, ordered from the most recent to the oldest one. Only the names
+// of the files are read.
+func historyFiles() ([]historyFile, error) {
+	dir, err := HistoryDir()
+	if err != nil {
+		return nil, err
+	}
+
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	var files []historyFile
+
+	for _, dirEntry := range dirEntries {
+		id, date, undone, ok := parseHistoryName(dirEntry.Name())
+		if !ok {
+			continue
+		}
+
+		files = append(files, historyFile{
+			date:   date,
+			id:     id,
+			path:   filepath.Join(dir, dirEntry.Name()),
+			undone: undone,
+		})
+	}
+
+	slices.SortFunc(files, func(a, b historyFile) int {
+		return b.date.Compare(a.date)
+	})
+
+	return files, nil
+}
+
+// History returns the renaming operations that are recorded in the history
+// across all directories, ordered from the most recent to the oldest one.
+// Backup files that cannot be read are left out.
+func History() ([]HistoryEntry, error) {
+	files, err := historyFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	var history []HistoryEntry
+
+	for i, f := range files {
+		backup, err := ReadBackup(f.path)
+		if err != nil {
+			continue
+		}
+
+		history = append(history, HistoryEntry{
+			Date:       f.date,
+			ID:         f.id,
+			WorkingDir: backup.WorkingDir,
+			CSV:        backup.CSV,
+			Path:       f.path,
+			Find:       backup.Find,
+			Replace:    backup.Replace,
+			Files:      len(backup.Changes),
+			Index:      i + 1,
+			Undone:     f.undone,
+		})
+	}
+
+	return history, nil
+}
+
+// FindBackup returns the path to the backup file of the operation in the
+// history that matches the reference, which is either its number in the
+// history listing or its ID.
+func FindBackup(ref string) (string, error) {
+	files, err := historyFiles()
+	if err != nil {
+		return "", err
+	}
+
+	index, _ := strconv.Atoi(ref)
+
+	for i, f := range files {
+		if f.id != ref && i+1 != index {
+			continue
+		}
+
+		if f.undone {
+			return "", errOperationUndone.Fmt(ref)
+		}
+
+		return f.path, nil
+	}
+
+	return "", errUnknownOperation.Fmt(ref)
+}
+
+// BackupsToUndo returns the paths to the backup files of the operations that
+// are undone from the most recent one. This is the operation selected with
+// --undo-id, the number of operations in the working directory set with
+// --undo-last, or the most recent operation in the working directory.
+func BackupsToUndo(conf *Config) ([]string, error) {
+	if conf.UndoID != "" {
+		backupPath, err := FindBackup(conf.UndoID)
+		if err != nil {
+			return nil, err
+		}
+
+		return []string{backupPath}, nil
+	}
+
+	if conf.UndoLast <= 1 {
+		backupPath, err := LatestBackup(conf.WorkingDir)
+		if err != nil || backupPath == "" {
+			return nil, err
+		}
+
+		return []string{backupPath}, nil
+	}
+
+	backups, err := historyBackups(conf.WorkingDir, backupExt)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(backups) < conf.UndoLast {
+		return nil, errNotEnoughHistory.Fmt(conf.UndoLast, len(backups))
+	}
+
+	backups = backups[len(backups)-conf.UndoLast:]
+
+	slices.Reverse(backups)
+
+	return backups, nil
+}
+
+// PruneHistory removes the operations that exceed the history limit or are
+// older than the expiry time from the history across all directories. It
+// returns the number of operations that were removed.
+func PruneHistory(conf *Config) (int, error) {
+	// The files are pruned by their names so that the backups do not have to
+	// be read after each operation
+	files, err := historyFiles()
+	if err != nil {
+		return 0, err
+	}
+
+	var pruned int
+
+	for i, f := range files {
+		if (conf.HistoryLimit == 0 || i < conf.HistoryLimit) &&
+			(conf.HistoryExpiry.IsZero() || !f.date.Before(conf.HistoryExpiry)) {
+			continue
+		}
+
+		err = os.Remove(f.path)
+		if err != nil {
+			return pruned, err
+		}
+
+		pruned++
+	}
+
+	return pruned, nil
+}
@@ -0,0 +1,16 @@
+package config
+
+import "strings"
+
+// parseLinkArg returns the operation that creates the kind of link at each
+// target (--link).
+func parseLinkArg(arg string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(arg)) {
+	case "sym", "symlink", "soft":
+		return OperationSymlink, nil
+	case "hard", "hardlink":
+		return OperationHardlink, nil
+	}
+
+	return "", errInvalidLink.Fmt(arg)
+}
@@ -0,0 +1,136 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/ayoisaiah/f2/v2/internal/osutil"
+)
+
+// lockDir returns the directory of the lock files, which is next to the
+// history directory.
+func lockDir() (string, error) {
+	historyDir, err := HistoryDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(filepath.Dir(historyDir), "locks"), nil
+}
+
+// lockedDirs returns the directories that are locked for the searched
+// paths, which are the searched directories and the directories that
+// contain the searched files. Their absolute paths are resolved so that a
+// directory has the same lock regardless of the path that leads to it.
+func lockedDirs(paths []string) ([]string, error) {
+	dirs := make([]string, 0, len(paths))
+
+	for _, path := range paths {
+		absPath, err := filepath.Abs(path)
+		if err != nil {
+			return nil, err
+		}
+
+		if info, err := os.Stat(absPath); err == nil && !info.IsDir() {
+			absPath = filepath.Dir(absPath)
+		}
+
+		if resolved, err := filepath.EvalSymlinks(absPath); err == nil {
+			absPath = resolved
+		}
+
+		dirs = append(dirs, absPath)
+	}
+
+	slices.Sort(dirs)
+
+	return slices.Compact(dirs), nil
+}
+
+// Lock takes an advisory lock on each of the searched paths so that the
+// renaming operations of concurrent invocations in the same directories
+// cannot interleave. It returns a function that releases the locks, or an
+// error if another invocation holds one of them. The locks are also released
+// if the process exits without calling it.
+func Lock(paths []string) (func(), error) {
+	dir, err := lockDir()
+	if err != nil {
+		return nil, err
+	}
+
+	err = os.MkdirAll(dir, osutil.DirPermission)
+	if err != nil {
+		return nil, err
+	}
+
+	lockedPaths, err := lockedDirs(paths)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]*os.File, 0, len(lockedPaths))
+
+	unlock := func() {
+		for _, f := range files {
+			_ = f.Truncate(0)
+			_ = f.Close()
+		}
+	}
+
+	for _, path := range lockedPaths {
+		f, err := lock(dir, path)
+		if err != nil {
+			unlock()
+			return nil, err
+		}
+
+		files = append(files, f)
+	}
+
+	return unlock, nil
+}
+
+// lock takes the advisory lock on the directory and returns the lock file
+// that holds it.
+func lock(lockDir, path string) (*os.File, error) {
+	lockPath := filepath.Join(
+		lockDir,
+		strings.TrimSuffix(historyPrefix(path), "_")+".lock",
+	)
+
+	f, err := os.OpenFile(lockPath, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	locked, err := osutil.TryLock(f)
+	if err != nil || !locked {
+		_ = f.Close()
+
+		if err != nil {
+			return nil, err
+		}
+
+		// The holder records its process ID in the lock file
+		pid := "unknown"
+		if b, err := os.ReadFile(lockPath); err == nil && len(b) > 0 {
+			pid = strings.TrimSpace(string(b))
+		}
+
+		// A copy is formatted since the lock can fail for each batch of new
+		// files in --watch mode
+		lockErr := *errLocked
+
+		return nil, lockErr.Fmt(path, pid)
+	}
+
+	// The process ID is only informative, so failing to record it is ignored
+	if err = f.Truncate(0); err == nil {
+		_, _ = f.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0)
+	}
+
+	return f, nil
+}
@@ -0,0 +1,21 @@
+package config
+
+import (
+	"slices"
+	"strings"
+)
+
+// normalForms are the Unicode normalization forms accepted by --normalize.
+var normalForms = []string{"nfc", "nfd", "nfkc", "nfkd"}
+
+// parseNormalizeArg returns the Unicode normalization form that the target
+// names are converted to (--normalize).
+func parseNormalizeArg(arg string) (string, error) {
+	form := strings.ToLower(strings.TrimSpace(arg))
+
+	if form == "" || slices.Contains(normalForms, form) {
+		return form, nil
+	}
+
+	return "", errInvalidNormalize.Fmt(arg)
+}
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"strconv"
+	"strings"
+)
+
+// NumberSkipExisting is the --number-skip value that skips the numbers that
+// are already used by the files in the target directory.
+const NumberSkipExisting = "existing"
+
+// NumberRange is an inclusive range of numbers.
+type NumberRange struct {
+	Min int `json:"min"`
+	Max int `json:"max"`
+}
+
+// parseNumberSkipArg returns the numbers that are skipped when numbering files
+// (--number-skip), and whether the numbers used by existing files are skipped
+// as well. The argument is a semicolon separated list of numbers, number
+// ranges (such as 20-29), and the word "existing".
+func parseNumberSkipArg(
+	arg string,
+) (ranges []NumberRange, existing bool, err error) {
+	if arg == "" {
+		return nil, false, nil
+	}
+
+	if !numberSkipRegex.MatchString(arg) {
+		return nil, false, errInvalidNumberSkip.Fmt(arg)
+	}
+
+	for _, val := range strings.Split(arg, ";") {
+		val = strings.TrimSpace(val)
+
+		if val == NumberSkipExisting {
+			existing = true
+			continue
+		}
+
+		start, end, isRange := strings.Cut(val, "-")
+		if !isRange {
+			end = start
+		}
+
+		startNum, err := strconv.Atoi(start)
+		if err != nil {
+			return nil, false, errInvalidNumberSkip.Fmt(arg)
+		}
+
+		endNum, err := strconv.Atoi(end)
+		if err != nil {
+			return nil, false, errInvalidNumberSkip.Fmt(arg)
+		}
+
+		ranges = append(ranges, NumberRange{
+			Min: min(startNum, endNum),
+			Max: max(startNum, endNum),
+		})
+	}
+
+	return ranges, existing, nil
+}
+
+// SkipsNumber reports whether the number is skipped by --number-skip.
+func (c *Config) SkipsNumber(n int) bool {
+	for _, r := range c.SkipNumbers {
+		if n >= r.Min && n <= r.Max {
+			return true
+		}
+	}
+
+	return false
+}
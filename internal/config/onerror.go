@@ -0,0 +1,19 @@
+package config
+
+import "strings"
+
+// parseOnErrorArg reports whether renaming should stop at the first file that
+// cannot be renamed, and whether the files renamed before it should be
+// restored to their original names (--on-error).
+func parseOnErrorArg(arg string) (abort, rollback bool, err error) {
+	switch strings.ToLower(strings.TrimSpace(arg)) {
+	case "", "continue":
+		return false, false, nil
+	case "abort":
+		return true, false, nil
+	case "rollback":
+		return true, true, nil
+	}
+
+	return false, false, errInvalidOnError.Fmt(arg)
+}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"strings"
+)
+
+// The ways of naming the backups of the files that are overwritten
+// (--backup), which follow the version control methods of GNU mv.
+const (
+	// BackupSimple adds a suffix to the name of the file
+	BackupSimple = "simple"
+	// BackupNumbered adds an increasing number to the name of the file
+	BackupNumbered = "numbered"
+	// BackupExisting makes numbered backups of the files that have them
+	// already and simple backups of the others
+	BackupExisting = "existing"
+)
+
+// DefaultBackupSuffix is the suffix of simple backups unless another one is
+// provided.
+const DefaultBackupSuffix = "~"
+
+// parseBackupArg returns the way that the files which are overwritten are
+// backed up along with the suffix of simple backups (--backup). The argument
+// is either a method or the suffix of simple backups, while an empty
+// argument, none, or off disables backups.
+func parseBackupArg(arg string) (mode, suffix string, err error) {
+	switch strings.ToLower(strings.TrimSpace(arg)) {
+	case "", "none", "off":
+		return "", "", nil
+	case BackupSimple, "never":
+		return BackupSimple, DefaultBackupSuffix, nil
+	case BackupNumbered, "t":
+		return BackupNumbered, "", nil
+	case BackupExisting, "nil":
+		return BackupExisting, DefaultBackupSuffix, nil
+	}
+
+	if strings.ContainsAny(arg, `/\`) {
+		return "", "", errInvalidBackup.Fmt(arg)
+	}
+
+	return BackupSimple, arg, nil
+}
@@ -0,0 +1,33 @@
+package config
+
+import (
+	"strings"
+)
+
+// Symlinks is the policy for handling symbolic links (--symlinks).
+type Symlinks int
+
+const (
+	SymlinksRename Symlinks = iota
+	SymlinksSkip
+	SymlinksFollow
+)
+
+func (s Symlinks) String() string {
+	return [...]string{"rename", "skip", "follow"}[s]
+}
+
+func parseSymlinksArg(arg string) (Symlinks, error) {
+	arg = strings.TrimSpace(arg)
+
+	switch arg {
+	case "", SymlinksRename.String():
+		return SymlinksRename, nil
+	case SymlinksSkip.String():
+		return SymlinksSkip, nil
+	case SymlinksFollow.String():
+		return SymlinksFollow, nil
+	}
+
+	return SymlinksRename, errInvalidSymlinks.Fmt(arg)
+}
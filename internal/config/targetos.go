@@ -0,0 +1,26 @@
+package config
+
+import (
+	"runtime"
+	"strings"
+
+	"github.com/ayoisaiah/f2/v2/internal/osutil"
+)
+
+// parseTargetOSArg returns the operating system whose naming rules are used to
+// validate the renamed paths (--target-os). It defaults to the current
+// operating system.
+func parseTargetOSArg(arg string) (string, error) {
+	arg = strings.ToLower(strings.TrimSpace(arg))
+
+	switch arg {
+	case "":
+		return runtime.GOOS, nil
+	case osutil.Windows, osutil.Darwin, osutil.Linux:
+		return arg, nil
+	case "macos":
+		return osutil.Darwin, nil
+	}
+
+	return "", errInvalidTargetOS.Fmt(arg)
+}
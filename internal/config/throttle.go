@@ -0,0 +1,54 @@
+package config
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parseThrottleArg returns the minimum interval between the changes to the
+// filesystem (--throttle). The argument is either a rate such as 10/s or
+// 300/m, where a bare number is a rate per second, or the delay between the
+// changes such as 200ms. An empty argument does not limit the rate.
+func parseThrottleArg(arg string) (time.Duration, error) {
+	arg = strings.ToLower(strings.TrimSpace(arg))
+	if arg == "" {
+		return 0, nil
+	}
+
+	count, unit, isRate := strings.Cut(arg, "/")
+	if !isRate {
+		if _, err := strconv.ParseFloat(arg, 64); err == nil {
+			count, unit, isRate = arg, "s", true
+		}
+	}
+
+	if !isRate {
+		delay, err := time.ParseDuration(arg)
+		if err != nil || delay <= 0 {
+			return 0, errInvalidThrottle.Fmt(arg)
+		}
+
+		return delay, nil
+	}
+
+	var period time.Duration
+
+	switch unit {
+	case "s", "sec", "second":
+		period = time.Second
+	case "m", "min", "minute":
+		period = time.Minute
+	case "h", "hour":
+		period = time.Hour
+	default:
+		return 0, errInvalidThrottle.Fmt(arg)
+	}
+
+	n, err := strconv.ParseFloat(count, 64)
+	if err != nil || n <= 0 {
+		return 0, errInvalidThrottle.Fmt(arg)
+	}
+
+	return time.Duration(float64(period) / n), nil
+}
@@ -0,0 +1,37 @@
+package config
+
+import (
+	"strings"
+)
+
+// Traversal is the order in which the matches in nested directories are
+// processed (--traversal).
+type Traversal int
+
+const (
+	TraversalDefault Traversal = iota
+	TraversalBreadth
+	TraversalDepth
+	TraversalDepthPost
+)
+
+func (t Traversal) String() string {
+	return [...]string{"default", "breadth", "depth", "depth-post"}[t]
+}
+
+func parseTraversalArg(arg string) (Traversal, error) {
+	arg = strings.TrimSpace(arg)
+
+	switch arg {
+	case "":
+		return TraversalDefault, nil
+	case TraversalBreadth.String():
+		return TraversalBreadth, nil
+	case TraversalDepth.String():
+		return TraversalDepth, nil
+	case TraversalDepthPost.String():
+		return TraversalDepthPost, nil
+	}
+
+	return TraversalDefault, errInvalidTraversal.Fmt(arg)
+}
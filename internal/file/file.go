@@ -1,9 +1,12 @@
 package file
 
 import (
+	"encoding/csv"
 	"encoding/json"
 	"io"
+	"io/fs"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
@@ -25,15 +28,67 @@ type Change struct {
 	OriginalName string        `json:"-"`
 	Status       status.Status `json:"status"`
 	SourcePath   string        `json:"-"`
+	LinkPath     string        `json:"link_path,omitempty"`
 	CustomSort   struct {
 		Time   time.Time
 		String string
 		Int    int
 	} `json:"-"`
-	CSVRow        []string `json:"-"`
-	Position      int      `json:"-"`
-	IsDir         bool     `json:"is_dir"`
-	WillOverwrite bool     `json:"-"`
+	// ModTime and Size are recorded when the source is matched so that
+	// changes to the file before it is renamed can be detected
+	ModTime       time.Time `json:"-"`
+	CSVRow        []string  `json:"-"`
+	Position      int       `json:"-"`
+	Size          int64     `json:"-"`
+	IsDir         bool      `json:"is_dir"`
+	WillOverwrite bool      `json:"-"`
+	IsSymlink     bool      `json:"is_symlink,omitempty"`
+	// Remove is set when undoing a copy so that the copy at the source is
+	// deleted instead of renamed
+	Remove bool `json:"-"`
+	// Trashed is the path in the trash that the file which was overwritten by
+	// the target was moved to (--trash)
+	Trashed string `json:"trashed,omitempty"`
+	// Backup is the path that the file which was overwritten by the target
+	// was renamed to (--backup)
+	Backup string `json:"backup,omitempty"`
+	// Restore maps the paths of the files that are restored once the change
+	// is undone to where they were kept when they were overwritten
+	Restore map[string]Kept `json:"-"`
+}
+
+// MarshalJSON renders the error of the change as its message since errors
+// have no JSON representation of their own.
+func (c Change) MarshalJSON() ([]byte, error) {
+	type change Change
+
+	var msg string
+	if c.Error != nil {
+		msg = c.Error.Error()
+	}
+
+	return json.Marshal(struct {
+		change
+		Error string `json:"error,omitempty"`
+	}{change(c), msg})
+}
+
+// Kept is where a file that was overwritten by a change is kept so that it
+// can be restored, which is either the trash or a backup path.
+type Kept struct {
+	Path    string
+	Trashed bool
+}
+
+// SourceModified reports whether the size or modification time of the source
+// differs from when it was matched. Only regular files are compared since the
+// modification time of a directory changes when its contents are renamed.
+func (c *Change) SourceModified(info fs.FileInfo) bool {
+	if c.IsDir || c.IsSymlink || c.ModTime.IsZero() || !info.Mode().IsRegular() {
+		return false
+	}
+
+	return info.Size() != c.Size || !info.ModTime().Equal(c.ModTime)
 }
 
 // AutoFixTarget sets the new target name.
@@ -55,6 +110,249 @@ func (c *Change) AutoFixTarget(newTarget string) {
 
 type Changes []*Change
 
+// Duplicate is a name shared by the targets of changes in different
+// directories.
+type Duplicate struct {
+	Name    string  `json:"name"`
+	Changes Changes `json:"changes"`
+}
+
+// Duplicates returns the target names that are shared by changes in different
+// source directories. This includes the files that already share a name and
+// are left unchanged. The duplicates are listed in the order in which the
+// names first appear.
+func (c Changes) Duplicates() []Duplicate {
+	groups := make(map[string]Changes)
+
+	var names []string
+
+	for _, change := range c {
+		if _, ok := groups[change.Target]; !ok {
+			names = append(names, change.Target)
+		}
+
+		groups[change.Target] = append(groups[change.Target], change)
+	}
+
+	var duplicates []Duplicate
+
+	for _, name := range names {
+		group := groups[name]
+
+		for _, change := range group[1:] {
+			if change.BaseDir != group[0].BaseDir {
+				duplicates = append(duplicates, Duplicate{
+					Name:    name,
+					Changes: group,
+				})
+
+				break
+			}
+		}
+	}
+
+	return duplicates
+}
+
+// Conflicts returns the changes whose status is a conflict that prevents the
+// renaming operation.
+func (c Changes) Conflicts() Changes {
+	var conflicts Changes
+
+	for _, change := range c {
+		//nolint:exhaustive // default case covers the conflicts
+		switch change.Status {
+		case status.OK, status.Unchanged, status.Overwriting, status.Ignored:
+		default:
+			conflicts = append(conflicts, change)
+		}
+	}
+
+	return conflicts
+}
+
+// Pending returns the changes that would rename a file, excluding the ones
+// that are skipped or leave the path unchanged.
+func (c Changes) Pending() Changes {
+	var pending Changes
+
+	for _, change := range c {
+		if change.Status == status.Ignored ||
+			change.SourcePath == change.TargetPath {
+			continue
+		}
+
+		pending = append(pending, change)
+	}
+
+	return pending
+}
+
+// Cycles returns the rename cycles in the changes. A cycle is formed when each
+// change renames a file to the source of the next change, and the last change
+// renames a file to the source of the first one (for example, a.txt to b.txt
+// and b.txt to a.txt). Each cycle is a list of indices in cycle order that
+// starts with the change that comes first.
+func (c Changes) Cycles() [][]int {
+	bySource := make(map[string]int, len(c))
+
+	for i, change := range c {
+		if change.Status == status.Ignored ||
+			change.SourcePath == change.TargetPath {
+			continue
+		}
+
+		bySource[change.SourcePath] = i
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	state := make([]int, len(c))
+
+	var cycles [][]int
+
+	for i := range c {
+		if _, ok := bySource[c[i].SourcePath]; !ok || state[i] != unvisited {
+			continue
+		}
+
+		// Each change leads to at most one other change (the one whose source
+		// is its target), so following the chain either ends or loops back
+		var chain []int
+
+		next, ok := i, true
+
+		for ok && state[next] == unvisited {
+			state[next] = visiting
+			chain = append(chain, next)
+			next, ok = bySource[c[next].TargetPath]
+		}
+
+		if ok && state[next] == visiting {
+			cycle := chain[slices.Index(chain, next):]
+			start := slices.Index(cycle, slices.Min(cycle))
+
+			cycles = append(
+				cycles,
+				slices.Concat(cycle[start:], cycle[:start]),
+			)
+		}
+
+		for _, j := range chain {
+			state[j] = visited
+		}
+	}
+
+	return cycles
+}
+
+// CommitOrder returns the order in which the changes are committed. A change
+// whose target is the source of another change (such as 1.txt to 2.txt and
+// 2.txt to 3.txt) is committed after that change so that its target is free
+// by the time it is renamed to. Otherwise, changes are committed in order.
+//
+// Rename cycles (such as a.txt to b.txt and b.txt to a.txt) are committed by
+// moving the first change in the cycle to a temporary path, followed by the
+// rest of the cycle in reverse. The first change is then moved from the
+// temporary path to its target, so its index appears twice in the order.
+func (c Changes) CommitOrder() []int {
+	cycles := make(map[int][]int)
+
+	// the first change in the cycle that each change belongs to
+	cycleStart := make(map[int]int)
+
+	for _, cycle := range c.Cycles() {
+		cycles[cycle[0]] = cycle
+
+		for _, i := range cycle {
+			cycleStart[i] = cycle[0]
+		}
+	}
+
+	bySource := make(map[string]int, len(c))
+
+	for i, change := range c {
+		if change.Status == status.Ignored ||
+			change.SourcePath == change.TargetPath {
+			continue
+		}
+
+		bySource[change.SourcePath] = i
+	}
+
+	order := make([]int, 0, len(c))
+
+	committed := make([]bool, len(c))
+
+	for i := range c {
+		// Follow the changes that free each target until one that is already
+		// committed, one whose target is not changing, or a cycle is reached
+		var chain []int
+
+		next, ok := i, true
+
+		for ok && !committed[next] {
+			if _, inCycle := cycleStart[next]; inCycle {
+				break
+			}
+
+			committed[next] = true
+			chain = append(chain, next)
+			next, ok = bySource[c[next].TargetPath]
+		}
+
+		if start, inCycle := cycleStart[next]; ok && inCycle &&
+			!committed[start] {
+			cycle := cycles[start]
+
+			order = append(order, start)
+
+			for j := len(cycle) - 1; j > 0; j-- {
+				order = append(order, cycle[j])
+			}
+
+			order = append(order, start)
+
+			for _, j := range cycle {
+				committed[j] = true
+			}
+		}
+
+		for j := len(chain) - 1; j >= 0; j-- {
+			order = append(order, chain[j])
+		}
+	}
+
+	return order
+}
+
+// RenderDuplicatesTable prints the duplicate names and the files that share
+// them in a table.
+func RenderDuplicatesTable(w io.Writer, duplicates []Duplicate, noColor bool) {
+	var data [][]string
+
+	for _, duplicate := range duplicates {
+		for i, change := range duplicate.Changes {
+			name := duplicate.Name
+			if i > 0 {
+				name = ""
+			}
+
+			data = append(data, []string{
+				name,
+				change.SourcePath,
+				change.TargetPath,
+			})
+		}
+	}
+
+	printTable(data, []string{"NAME", "ORIGINAL", "RENAMED"}, w, noColor)
+}
+
 func (c Changes) RenderJSON(w io.Writer) error {
 	jsonData, err := json.Marshal(c)
 	if err != nil {
@@ -70,21 +368,79 @@ func (c Changes) RenderJSON(w io.Writer) error {
 }
 
 func (c Changes) RenderTable(w io.Writer, noColor bool) {
+	printTable(c.rows(true), []string{"ORIGINAL", "RENAMED", "STATUS"}, w, noColor)
+}
+
+// RenderCSV writes the changes in CSV format with the same columns as the
+// table.
+func (c Changes) RenderCSV(w io.Writer) error {
+	csvWriter := csv.NewWriter(w)
+
+	err := csvWriter.Write([]string{"ORIGINAL", "RENAMED", "STATUS"})
+	if err != nil {
+		return err
+	}
+
+	err = csvWriter.WriteAll(c.rows(false))
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// highlightChange colors the part of the source and the target that differs
+// between them so that a small change to a long path stands out. The part is
+// what is left after the prefix and the suffix that they share.
+func highlightChange(source, target string) (string, string) {
+	s, t := []rune(source), []rune(target)
+
+	prefix := 0
+	for prefix < len(s) && prefix < len(t) && s[prefix] == t[prefix] {
+		prefix++
+	}
+
+	suffix := 0
+	for suffix < len(s)-prefix && suffix < len(t)-prefix &&
+		s[len(s)-1-suffix] == t[len(t)-1-suffix] {
+		suffix++
+	}
+
+	mark := func(r []rune, colorize func(a ...any) string) string {
+		changed := r[prefix : len(r)-suffix]
+		if len(changed) == 0 {
+			return string(r)
+		}
+
+		return string(r[:prefix]) + colorize(string(changed)) +
+			string(r[len(r)-suffix:])
+	}
+
+	return mark(s, pterm.Red), mark(t, pterm.Green)
+}
+
+// rows returns the original path, the new path, and the status of each
+// change as they are shown in the table. If color is set, the status is
+// colored according to the outcome of the change, and the part of the paths
+// that changes is highlighted.
+func (c Changes) rows(color bool) [][]string {
 	data := make([][]string, len(c))
 
 	for i := range c {
 		change := c[i]
 
-		var changeStatus string
+		changeStatus := string(change.Status)
+
+		var colorize func(a ...any) string
 
 		//nolint:exhaustive // default case covers other statuses
 		switch change.Status {
 		case status.OK:
-			changeStatus = pterm.Green(change.Status)
+			colorize = pterm.Green
 		case status.Unchanged, status.Overwriting, status.Ignored:
-			changeStatus = pterm.Yellow(change.Status)
+			colorize = pterm.Yellow
 		default:
-			changeStatus = pterm.Red(change.Status)
+			colorize = pterm.Red
 		}
 
 		if change.Error != nil {
@@ -93,31 +449,62 @@ func (c Changes) RenderTable(w io.Writer, noColor bool) {
 				msg = strings.TrimSpace(msg[strings.IndexByte(msg, ':'):])
 			}
 
-			changeStatus = pterm.Red(strings.TrimPrefix(msg, ": "))
+			changeStatus = strings.TrimPrefix(msg, ": ")
+			colorize = pterm.Red
+		}
+
+		if color {
+			changeStatus = colorize(changeStatus)
+		}
+
+		source, target := change.SourcePath, change.TargetPath
+		if color && !change.Remove {
+			source, target = highlightChange(source, target)
 		}
 
-		d := []string{change.SourcePath, change.TargetPath, changeStatus}
+		// Label symbolic links so that it is clear what will be renamed
+		switch {
+		case change.IsSymlink:
+			source += " (symlink)"
+		case change.LinkPath != "":
+			source += " (via " + change.LinkPath + ")"
+		}
+
+		if change.Remove {
+			target = "(removed)"
+		}
+
+		d := []string{source, target, changeStatus}
 		data[i] = d
 	}
 
-	printTable(data, w, noColor)
+	return data
 }
 
-func printTable(data [][]string, w io.Writer, noColor bool) {
+// PrintTable prints the rows of data under the header in a table.
+func PrintTable(w io.Writer, header []string, data [][]string, noColor bool) {
+	printTable(data, header, w, noColor)
+}
+
+func printTable(data [][]string, header []string, w io.Writer, noColor bool) {
 	// using tablewriter as pterm table rendering is too slow
 	table := tablewriter.NewWriter(w)
-	table.SetHeader([]string{"ORIGINAL", "RENAMED", "STATUS"})
+	table.SetHeader(header)
 	table.SetCenterSeparator("*")
 	table.SetColumnSeparator("|")
 	table.SetRowSeparator("—")
 	table.SetAutoWrapText(false)
 
 	if !noColor {
-		table.SetHeaderColor(
-			tablewriter.Colors{tablewriter.Bold, tablewriter.FgGreenColor},
-			tablewriter.Colors{tablewriter.Bold, tablewriter.FgGreenColor},
-			tablewriter.Colors{tablewriter.Bold, tablewriter.FgGreenColor},
-		)
+		colors := make([]tablewriter.Colors, len(header))
+		for i := range colors {
+			colors[i] = tablewriter.Colors{
+				tablewriter.Bold,
+				tablewriter.FgGreenColor,
+			}
+		}
+
+		table.SetHeaderColor(colors...)
 	}
 
 	table.AppendBulk(data)
@@ -0,0 +1,187 @@
+// Package filetype detects the type of a file from its signature (the magic
+// number at the start of its contents).
+package filetype
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"slices"
+	"strings"
+)
+
+// headerSize is the number of bytes read from the start of a file to detect
+// its type.
+const headerSize = 64
+
+// id3HeaderSize is the size of the header of an ID3v2 tag.
+const id3HeaderSize = 10
+
+// Type is a file type detected from a file signature.
+type Type struct {
+	// Extensions are the extensions used for the file type. The first one is
+	// the preferred extension.
+	Extensions []string
+}
+
+// Ext returns the preferred extension for the file type including the
+// leading dot.
+func (t Type) Ext() string {
+	return "." + t.Extensions[0]
+}
+
+// HasExt reports whether the provided extension (with or without the leading
+// dot) is used for the file type. The comparison is case insensitive.
+func (t Type) HasExt(ext string) bool {
+	ext = strings.ToLower(strings.TrimPrefix(ext, "."))
+
+	return slices.Contains(t.Extensions, ext)
+}
+
+// signature identifies a file type by the bytes at an offset from the start
+// of the file.
+type signature struct {
+	magic  []byte
+	offset int
+	typ    Type
+}
+
+var (
+	jpeg = Type{[]string{"jpg", "jpeg", "jpe", "jfif"}}
+	png  = Type{[]string{"png"}}
+	gif  = Type{[]string{"gif"}}
+	webp = Type{[]string{"webp"}}
+	bmp  = Type{[]string{"bmp"}}
+	// TIFF is the container for several camera raw formats
+	tiff = Type{[]string{
+		"tif", "tiff", "dng", "cr2", "nef", "nrw", "arw", "srf", "sr2", "pef",
+		"orf", "3fr", "erf", "mos", "iiq",
+	}}
+	heic = Type{[]string{"heic", "heif", "avif"}}
+	pdf  = Type{[]string{"pdf"}}
+	// ZIP is the container for office documents, e-books, and archives
+	zip = Type{[]string{
+		"zip", "docx", "xlsx", "pptx", "odt", "ods", "odp", "epub", "jar",
+		"apk", "cbz", "kmz",
+	}}
+	gzip     = Type{[]string{"gz", "tgz"}}
+	sevenZip = Type{[]string{"7z"}}
+	rar      = Type{[]string{"rar", "cbr"}}
+	mp3      = Type{[]string{"mp3"}}
+	flac     = Type{[]string{"flac"}}
+	ogg      = Type{[]string{"ogg", "oga", "ogv", "opus"}}
+	wav      = Type{[]string{"wav"}}
+	avi      = Type{[]string{"avi"}}
+	mp4      = Type{[]string{"mp4", "m4v", "m4a", "m4b", "3gp", "3g2"}}
+	mov      = Type{[]string{"mov", "qt"}}
+	mkv      = Type{[]string{"mkv", "webm", "mka"}}
+)
+
+// signatures are checked in order, so the more specific signatures come before
+// the generic ones that they share a prefix with.
+var signatures = []signature{
+	{magic: []byte{0xFF, 0xD8, 0xFF}, typ: jpeg},
+	{magic: []byte("\x89PNG\r\n\x1a\n"), typ: png},
+	{magic: []byte("GIF87a"), typ: gif},
+	{magic: []byte("GIF89a"), typ: gif},
+	{magic: []byte("WEBP"), offset: 8, typ: webp},
+	{magic: []byte("WAVE"), offset: 8, typ: wav},
+	{magic: []byte("AVI "), offset: 8, typ: avi},
+	{magic: []byte("BM"), typ: bmp},
+	{magic: []byte("II*\x00"), typ: tiff},
+	{magic: []byte("MM\x00*"), typ: tiff},
+	{magic: []byte("ftypheic"), offset: 4, typ: heic},
+	{magic: []byte("ftypheix"), offset: 4, typ: heic},
+	{magic: []byte("ftypmif1"), offset: 4, typ: heic},
+	{magic: []byte("ftypavif"), offset: 4, typ: heic},
+	{magic: []byte("ftypqt  "), offset: 4, typ: mov},
+	{magic: []byte("ftyp"), offset: 4, typ: mp4},
+	{magic: []byte("%PDF-"), typ: pdf},
+	{magic: []byte("PK\x03\x04"), typ: zip},
+	{magic: []byte{0x1F, 0x8B}, typ: gzip},
+	{magic: []byte{'7', 'z', 0xBC, 0xAF, 0x27, 0x1C}, typ: sevenZip},
+	{magic: []byte("Rar!\x1a\x07"), typ: rar},
+	{magic: []byte("fLaC"), typ: flac},
+	{magic: []byte("OggS"), typ: ogg},
+	{magic: []byte{0x1A, 0x45, 0xDF, 0xA3}, typ: mkv},
+}
+
+// match returns the file type whose signature matches the header.
+func match(header []byte) (Type, bool) {
+	for _, sig := range signatures {
+		end := sig.offset + len(sig.magic)
+		if len(header) >= end &&
+			bytes.Equal(header[sig.offset:end], sig.magic) {
+			return sig.typ, true
+		}
+	}
+
+	if isMPEGFrame(header) {
+		return mp3, true
+	}
+
+	return Type{}, false
+}
+
+// isMPEGFrame reports whether the header starts with the frame sync of an
+// MPEG audio frame (11 set bits followed by a valid layer).
+func isMPEGFrame(header []byte) bool {
+	return len(header) >= 2 && header[0] == 0xFF &&
+		header[1]&0xE0 == 0xE0 && header[1]&0x06 != 0
+}
+
+// id3TagSize returns the size of the ID3v2 tag at the start of the header
+// including its header, or zero if the header does not start with a tag.
+func id3TagSize(header []byte) int64 {
+	if len(header) < id3HeaderSize || !bytes.HasPrefix(header, []byte("ID3")) {
+		return 0
+	}
+
+	// The size is stored as a 28-bit synchsafe integer
+	var size int64
+	for _, b := range header[6:10] {
+		size = size<<7 | int64(b&0x7F)
+	}
+
+	return size + id3HeaderSize
+}
+
+// Detect returns the type of the file at the specified path from its
+// signature. It reports false if the type is not recognized.
+func Detect(path string) (Type, bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Type{}, false, err
+	}
+
+	defer f.Close()
+
+	header := make([]byte, headerSize)
+
+	n, err := io.ReadFull(f, header)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) &&
+		!errors.Is(err, io.EOF) {
+		return Type{}, false, err
+	}
+
+	header = header[:n]
+
+	// An ID3 tag is usually found at the start of MP3 files, but it is also
+	// prepended to other audio formats such as FLAC, so the type is detected
+	// from the data that follows the tag
+	if tagSize := id3TagSize(header); tagSize > 0 {
+		header = make([]byte, headerSize)
+
+		n, err = f.ReadAt(header, tagSize)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return Type{}, false, err
+		}
+
+		header = header[:n]
+	}
+
+	typ, ok := match(header)
+
+	return typ, ok, nil
+}
@@ -0,0 +1,102 @@
+// Package fuzzy provides approximate string matching based on the
+// Levenshtein edit distance
+package fuzzy
+
+import (
+	"unicode"
+)
+
+// equal reports whether the runes are the same, or the same under simple
+// case folding if ignoreCase is set.
+func equal(a, b rune, ignoreCase bool) bool {
+	if a == b {
+		return true
+	}
+
+	if !ignoreCase {
+		return false
+	}
+
+	for r := unicode.SimpleFold(a); r != a; r = unicode.SimpleFold(r) {
+		if r == b {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Find locates the substring of text that is closest to the pattern in terms
+// of edit distance (insertions, deletions, and substitutions). It returns the
+// byte offsets of the substring in text and true if its distance from the
+// pattern does not exceed maxDistance. Case is ignored rune by rune so that
+// the offsets stay valid when folding changes the length of a rune.
+func Find(
+	pattern, text string,
+	maxDistance int,
+	ignoreCase bool,
+) (start, end int, ok bool) {
+	p, t := []rune(pattern), []rune(text)
+
+	if len(p) == 0 {
+		return 0, 0, false
+	}
+
+	// dist[i][j] is the smallest edit distance between p[:i] and any
+	// substring of t ending at j
+	dist := make([][]int, len(p)+1)
+	for i := range dist {
+		dist[i] = make([]int, len(t)+1)
+		dist[i][0] = i
+	}
+
+	for i := 1; i <= len(p); i++ {
+		for j := 1; j <= len(t); j++ {
+			cost := 1
+			if equal(p[i-1], t[j-1], ignoreCase) {
+				cost = 0
+			}
+
+			dist[i][j] = min(
+				dist[i-1][j-1]+cost,
+				dist[i-1][j]+1,
+				dist[i][j-1]+1,
+			)
+		}
+	}
+
+	endRune := 0
+	for j := 1; j <= len(t); j++ {
+		if dist[len(p)][j] < dist[len(p)][endRune] {
+			endRune = j
+		}
+	}
+
+	if dist[len(p)][endRune] > maxDistance {
+		return 0, 0, false
+	}
+
+	// Trace the edits back to the start of the substring
+	i, j := len(p), endRune
+	for i > 0 {
+		switch {
+		case j > 0 && equal(p[i-1], t[j-1], ignoreCase) &&
+			dist[i][j] == dist[i-1][j-1]:
+			i, j = i-1, j-1
+		case j > 0 && dist[i][j] == dist[i-1][j-1]+1:
+			i, j = i-1, j-1
+		case dist[i][j] == dist[i-1][j]+1:
+			i--
+		default:
+			j--
+		}
+	}
+
+	startRune := j
+
+	if startRune == endRune {
+		return 0, 0, false
+	}
+
+	return len(string(t[:startRune])), len(string(t[:endRune])), true
+}
@@ -0,0 +1,99 @@
+// Package gitutil renames the files that are tracked in git repositories
+// through git so that the renames are staged
+package gitutil
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+var errNoRepository = errors.New("not in a git work tree")
+
+// git runs the git command with the arguments in the directory and returns
+// its output without the trailing newline.
+func git(dir string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return "", fmt.Errorf(
+				"git %s: %s",
+				args[0],
+				strings.TrimSpace(stderr.String()),
+			)
+		}
+
+		return "", err
+	}
+
+	return strings.TrimRight(stdout.String(), "\n"), nil
+}
+
+// root returns the top-level directory of the work tree that contains the
+// directory.
+func root(dir string) (string, error) {
+	top, err := git(dir, "rev-parse", "--show-toplevel")
+	if err != nil || top == "" {
+		return "", errNoRepository
+	}
+
+	return filepath.Clean(top), nil
+}
+
+// Move renames the source to the target with 'git mv' if the source is
+// tracked in a git work tree that also contains the target. It reports false
+// without renaming anything if git is not installed, the source is not
+// tracked, or the target is outside its work tree, in which case the source
+// should be renamed by other means.
+func Move(source, target string) (bool, error) {
+	source, err := filepath.Abs(source)
+	if err != nil {
+		return false, err
+	}
+
+	target, err = filepath.Abs(target)
+	if err != nil {
+		return false, err
+	}
+
+	sourceRoot, err := root(filepath.Dir(source))
+	if err != nil {
+		return false, nil
+	}
+
+	targetRoot, err := root(filepath.Dir(target))
+	if err != nil || targetRoot != sourceRoot {
+		return false, nil
+	}
+
+	// The paths are passed relative to the directory of the source since
+	// git resolves symbolic links in the path of the work tree
+	dir := filepath.Dir(source)
+
+	rel, err := filepath.Rel(dir, target)
+	if err != nil {
+		return false, nil
+	}
+
+	_, err = git(dir, "ls-files", "--error-unmatch", "--", filepath.Base(source))
+	if err != nil {
+		return false, nil
+	}
+
+	_, err = git(dir, "mv", "--", filepath.Base(source), rel)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
@@ -0,0 +1,218 @@
+// Package ignore matches paths against gitignore-style patterns
+package ignore
+
+import (
+	"bufio"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+type rule struct {
+	regex   *regexp.Regexp
+	baseDir string
+	negate  bool
+	dirOnly bool
+}
+
+// Matcher holds the ignore rules loaded from one or more ignore files. Rules
+// added later take precedence over those added earlier.
+type Matcher struct {
+	rules []rule
+}
+
+// New returns an empty Matcher.
+func New() *Matcher {
+	return &Matcher{}
+}
+
+// patternToRegex converts a gitignore pattern into an equivalent regular
+// expression that is matched against slash separated relative paths.
+func patternToRegex(pattern string) (*regexp.Regexp, error) {
+	// Patterns without a slash (other than a trailing one) match at any depth
+	anchored := strings.Contains(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+
+	var sb strings.Builder
+
+	sb.WriteString("^")
+
+	if !anchored {
+		sb.WriteString("(?:.*/)?")
+	}
+
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+
+		switch c {
+		case '*':
+			if strings.HasPrefix(pattern[i:], "**") {
+				rest := pattern[i+2:]
+
+				switch {
+				case rest == "":
+					sb.WriteString(".*")
+				case strings.HasPrefix(rest, "/"):
+					sb.WriteString("(?:.*/)?")
+
+					i++
+				default:
+					sb.WriteString("[^/]*")
+				}
+
+				i++
+
+				continue
+			}
+
+			sb.WriteString("[^/]*")
+		case '?':
+			sb.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end == -1 {
+				sb.WriteString(`\[`)
+				continue
+			}
+
+			class := pattern[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+
+			sb.WriteString("[" + class + "]")
+
+			i += end + 1
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+				sb.WriteString(regexp.QuoteMeta(string(pattern[i])))
+			}
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	// A matched directory also excludes everything inside it
+	sb.WriteString("(/.*)?$")
+
+	return regexp.Compile(sb.String())
+}
+
+// AddPatterns adds the given gitignore-style patterns to the matcher. The
+// patterns are interpreted relative to baseDir. Blank lines, comments, and
+// invalid patterns are skipped.
+func (m *Matcher) AddPatterns(baseDir string, patterns []string) {
+	baseDir, err := filepath.Abs(baseDir)
+	if err != nil {
+		return
+	}
+
+	for _, pattern := range patterns {
+		pattern = strings.TrimRight(pattern, " \t\r")
+
+		if pattern == "" || strings.HasPrefix(pattern, "#") {
+			continue
+		}
+
+		r := rule{
+			baseDir: baseDir,
+		}
+
+		if strings.HasPrefix(pattern, "!") {
+			r.negate = true
+			pattern = pattern[1:]
+		} else if strings.HasPrefix(pattern, `\!`) ||
+			strings.HasPrefix(pattern, `\#`) {
+			pattern = pattern[1:]
+		}
+
+		if strings.HasSuffix(pattern, "/") {
+			r.dirOnly = true
+			pattern = strings.TrimRight(pattern, "/")
+		}
+
+		if pattern == "" {
+			continue
+		}
+
+		r.regex, err = patternToRegex(pattern)
+		if err != nil {
+			continue
+		}
+
+		m.rules = append(m.rules, r)
+	}
+}
+
+// AddFile reads the patterns in the specified ignore file and adds them to
+// the matcher relative to baseDir. A missing file is not considered an error.
+func (m *Matcher) AddFile(baseDir, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+
+		return err
+	}
+
+	defer f.Close()
+
+	var patterns []string
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		patterns = append(patterns, scanner.Text())
+	}
+
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	m.AddPatterns(baseDir, patterns)
+
+	return nil
+}
+
+// Match reports whether the path is ignored by the loaded rules.
+func (m *Matcher) Match(path string, isDir bool) bool {
+	if len(m.rules) == 0 {
+		return false
+	}
+
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+
+	var ignored bool
+
+	for _, r := range m.rules {
+		relPath, err := filepath.Rel(r.baseDir, path)
+		if err != nil || relPath == "." || relPath == ".." ||
+			strings.HasPrefix(relPath, ".."+string(filepath.Separator)) {
+			continue
+		}
+
+		relPath = filepath.ToSlash(relPath)
+
+		loc := r.regex.FindStringSubmatchIndex(relPath)
+		if loc == nil {
+			continue
+		}
+
+		// Directory only patterns match a file only through one of its
+		// parent directories
+		if r.dirOnly && !isDir && loc[2] == -1 {
+			continue
+		}
+
+		ignored = !r.negate
+	}
+
+	return ignored
+}
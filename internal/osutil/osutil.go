@@ -1,7 +1,18 @@
 package osutil
 
 import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
 	"regexp"
+	"strings"
+
+	"github.com/djherbis/times"
 )
 
 var (
@@ -9,27 +20,323 @@ var (
 	// characters in Windows' file names. This does not include also forbidden
 	// forward and back slash characters because their presence will cause a new
 	// directory to be created.
-	PartialWindowsForbiddenCharRegex = regexp.MustCompile(`<|>|:|"|\||\?|\*`)
+	PartialWindowsForbiddenCharRegex = regexp.MustCompile(`<|>|:|"|\||\?|\*|\x00`)
 	// CompleteWindowsForbiddenCharRegex is like windowsForbiddenRegex but includes
 	// forward and backslashes.
 	CompleteWindowsForbiddenCharRegex = regexp.MustCompile(
-		`<|>|:|"|\||\?|\*|/|\\`,
+		`<|>|:|"|\||\?|\*|\x00|/|\\`,
 	)
 	// MacForbiddenCharRegex is used to match the strings that contain forbidden
 	// characters in macOS' file names.
-	MacForbiddenCharRegex = regexp.MustCompile(`:`)
+	MacForbiddenCharRegex = regexp.MustCompile(`:|\x00`)
+	// UnixForbiddenCharRegex is used to match the strings that contain
+	// forbidden characters in file names on Linux and other unix-based OSes.
+	// The forward slash is not included because its presence will cause a new
+	// directory to be created.
+	UnixForbiddenCharRegex = regexp.MustCompile(`\x00`)
 )
 
 const (
 	Windows = "windows"
 	Darwin  = "darwin"
+	Linux   = "linux"
 )
 
 type exitCode int
 
+// The exit codes allow scripts to tell the outcome of an operation apart
+// without parsing the output.
 const (
-	ExitOK    exitCode = 0
-	ExitError exitCode = 1
+	ExitOK             exitCode = 0
+	ExitError          exitCode = 1
+	ExitNoMatches      exitCode = 2
+	ExitConflicts      exitCode = 3
+	ExitPartialFailure exitCode = 4
+	ExitPendingChanges exitCode = 5
 )
 
 const DirPermission = 0o755
+
+// pathSniffLength is the number of bytes at the start of the input in which a
+// NUL character must be found for the paths to be NUL-delimited. It is longer
+// than the longest path that most systems allow.
+const pathSniffLength = 4096
+
+// ScanPaths returns a split function for a bufio.Scanner that returns each
+// path in the input. Paths are delimited by NUL characters if one is present
+// at the start of the input, or by newlines otherwise. The delimiter is picked
+// once so that NUL-delimited paths may contain newlines regardless of how the
+// input is read.
+func ScanPaths() bufio.SplitFunc {
+	var sniffed, nulDelimited bool
+
+	return func(data []byte, atEOF bool) (advance int, token []byte, err error) {
+		if !sniffed {
+			switch {
+			case bytes.IndexByte(data, 0) >= 0:
+				sniffed, nulDelimited = true, true
+			case atEOF || len(data) > pathSniffLength:
+				sniffed = true
+			default:
+				// Read more of the input before picking the delimiter
+				return 0, nil, nil
+			}
+		}
+
+		if !nulDelimited {
+			return bufio.ScanLines(data, atEOF)
+		}
+
+		if i := bytes.IndexByte(data, 0); i >= 0 {
+			return i + 1, data[:i], nil
+		}
+
+		if atEOF && len(data) > 0 {
+			return len(data), data, nil
+		}
+
+		return 0, nil, nil
+	}
+}
+
+// Checksum returns the SHA-256 checksum of the contents of the file at the
+// specified path in hexadecimal.
+func Checksum(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+
+	defer f.Close()
+
+	h := sha256.New()
+
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// IsCaseChange reports whether the target path differs from the source path
+// only in case and does not refer to another file. On case-insensitive
+// filesystems, the target is the source itself, while on case-sensitive ones
+// the target must not exist yet.
+func IsCaseChange(source, target string) bool {
+	if source == target || !strings.EqualFold(source, target) {
+		return false
+	}
+
+	targetInfo, err := os.Lstat(target)
+	if err != nil {
+		return errors.Is(err, os.ErrNotExist)
+	}
+
+	sourceInfo, err := os.Lstat(source)
+	if err != nil {
+		return false
+	}
+
+	return os.SameFile(sourceInfo, targetInfo)
+}
+
+// CopyOptions controls how files are copied.
+type CopyOptions struct {
+	// Progress is called as the contents of each file are copied if set
+	Progress func(copied, total int64)
+	// Times keeps the access and modification times of the files
+	Times bool
+	// Owner keeps the owner and group of the files where permitted
+	Owner bool
+}
+
+// CopyFile copies the file at the source path to the target path, replacing
+// the target if it exists. The contents are written to a temporary file next
+// to the target first so that an interrupted copy does not leave a partial
+// file at the target. Symbolic links are copied as links to the same path.
+// The permissions of the file are always kept, while its other attributes are
+// kept according to the options.
+func CopyFile(source, target string, opts CopyOptions) error {
+	info, err := os.Lstat(source)
+	if err != nil {
+		return err
+	}
+
+	if info.Mode()&os.ModeSymlink != 0 {
+		return copySymlink(source, target, info, opts)
+	}
+
+	return copyFile(source, target, info, opts)
+}
+
+// Move moves the file or directory at the source path to the target path by
+// copying it and removing the source. This is used when the target is on
+// another filesystem where renaming is not possible. The copied files keep
+// their attributes according to the options. A directory that was partly
+// copied is removed if the copy fails.
+func Move(source, target string, opts CopyOptions) error {
+	_, statErr := os.Lstat(target)
+
+	err := copyTree(source, target, opts)
+	if err != nil {
+		if errors.Is(statErr, os.ErrNotExist) {
+			_ = os.RemoveAll(target)
+		}
+
+		return err
+	}
+
+	return os.RemoveAll(source)
+}
+
+// copyTree copies the file, symbolic link, or directory at the source path to
+// the target path along with the contents of directories.
+func copyTree(source, target string, opts CopyOptions) error {
+	info, err := os.Lstat(source)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case info.Mode()&os.ModeSymlink != 0:
+		return copySymlink(source, target, info, opts)
+	case info.IsDir():
+		err = os.Mkdir(target, info.Mode().Perm())
+		if err != nil {
+			return err
+		}
+
+		if opts.Owner {
+			err = preserveOwner(target, info)
+			if err != nil {
+				return err
+			}
+		}
+
+		entries, err := os.ReadDir(source)
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range entries {
+			err = copyTree(
+				filepath.Join(source, entry.Name()),
+				filepath.Join(target, entry.Name()),
+				opts,
+			)
+			if err != nil {
+				return err
+			}
+		}
+
+		if !opts.Times {
+			return nil
+		}
+
+		// The times are set last since adding the contents changes them
+		return preserveTimes(target, info)
+	}
+
+	return copyFile(source, target, info, opts)
+}
+
+// copySymlink creates a symbolic link at the target path that points to the
+// same path as the link at the source path.
+func copySymlink(
+	source, target string,
+	info os.FileInfo,
+	opts CopyOptions,
+) error {
+	link, err := os.Readlink(source)
+	if err != nil {
+		return err
+	}
+
+	err = os.Symlink(link, target)
+	if err != nil || !opts.Owner {
+		return err
+	}
+
+	return preserveOwner(target, info)
+}
+
+// copyFile copies the contents of the regular file at the source path to a
+// temporary file next to the target, which is then moved to the target. The
+// permissions of the source are applied to the copy, along with its other
+// attributes according to the options.
+func copyFile(
+	source, target string,
+	info os.FileInfo,
+	opts CopyOptions,
+) error {
+	src, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+
+	defer src.Close()
+
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".f2-copy-*")
+	if err != nil {
+		return err
+	}
+
+	var r io.Reader = src
+
+	if opts.Progress != nil {
+		r = &progressReader{
+			r:        src,
+			total:    info.Size(),
+			progress: opts.Progress,
+		}
+	}
+
+	_, err = io.Copy(tmp, r)
+	if err == nil {
+		err = tmp.Chmod(info.Mode().Perm())
+	}
+
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err == nil && opts.Owner {
+		err = preserveOwner(tmp.Name(), info)
+	}
+
+	if err == nil && opts.Times {
+		err = preserveTimes(tmp.Name(), info)
+	}
+
+	if err == nil {
+		err = os.Rename(tmp.Name(), target)
+	}
+
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+	}
+
+	return err
+}
+
+// preserveTimes sets the access and modification times of the path to the
+// ones in the file info.
+func preserveTimes(path string, info os.FileInfo) error {
+	return os.Chtimes(path, times.Get(info).AccessTime(), info.ModTime())
+}
+
+// progressReader reports the number of bytes that were read so far.
+type progressReader struct {
+	r        io.Reader
+	progress func(copied, total int64)
+	copied   int64
+	total    int64
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	p.copied += int64(n)
+	p.progress(p.copied, p.total)
+
+	return n, err
+}
@@ -0,0 +1,59 @@
+package osutil_test
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ayoisaiah/f2/v2/internal/osutil"
+)
+
+func TestScanPaths(t *testing.T) {
+	cases := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{
+			name:  "newline-delimited paths",
+			input: "a.txt\nb.txt\r\nc.txt",
+			want:  []string{"a.txt", "b.txt", "c.txt"},
+		},
+		{
+			name:  "NUL-delimited paths",
+			input: "a.txt\x00b.txt\x00c.txt",
+			want:  []string{"a.txt", "b.txt", "c.txt"},
+		},
+		{
+			name:  "NUL-delimited paths with newlines",
+			input: "a\nb.txt\x00c.txt\n\x00",
+			want:  []string{"a\nb.txt", "c.txt\n"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			// The input is read one byte at a time so that the delimiter cannot
+			// be picked from the whole input at once
+			scanner := bufio.NewScanner(
+				iotest.OneByteReader(strings.NewReader(tc.input)),
+			)
+			scanner.Split(osutil.ScanPaths())
+
+			var got []string
+
+			for scanner.Scan() {
+				got = append(got, scanner.Text())
+			}
+
+			if err := scanner.Err(); err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
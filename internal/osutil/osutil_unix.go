@@ -0,0 +1,68 @@
+//go:build !windows
+// +build !windows
+
+package osutil
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// IsCrossDevice reports whether a rename failed because the source and target
+// are on different filesystems.
+func IsCrossDevice(err error) bool {
+	return errors.Is(err, syscall.EXDEV)
+}
+
+// IsTransient reports whether a file operation failed due to a condition that
+// may clear by itself, such as a busy file or a network share that timed out,
+// in which case it is worth retrying.
+func IsTransient(err error) bool {
+	for _, errno := range []syscall.Errno{
+		syscall.EBUSY,
+		syscall.EAGAIN,
+		syscall.EINTR,
+		syscall.ETIMEDOUT,
+		syscall.ECONNRESET,
+		syscall.ECONNABORTED,
+		syscall.ENETDOWN,
+		syscall.ENETUNREACH,
+		syscall.EHOSTUNREACH,
+	} {
+		if errors.Is(err, errno) {
+			return true
+		}
+	}
+
+	return os.IsTimeout(err)
+}
+
+// TryLock takes an exclusive advisory lock on the open file without waiting.
+// It reports false if the lock is held through another open file. The lock is
+// released when the file is closed.
+func TryLock(f *os.File) (bool, error) {
+	err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	if errors.Is(err, syscall.EWOULDBLOCK) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+// preserveOwner sets the owner and group of the path to the ones in the file
+// info. Failures due to a lack of privileges are ignored so that the path
+// keeps the current user as its owner.
+func preserveOwner(path string, info os.FileInfo) error {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return nil
+	}
+
+	err := os.Lchown(path, int(stat.Uid), int(stat.Gid))
+	if errors.Is(err, os.ErrPermission) {
+		return nil
+	}
+
+	return err
+}
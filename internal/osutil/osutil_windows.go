@@ -0,0 +1,68 @@
+//go:build windows
+// +build windows
+
+package osutil
+
+import (
+	"errors"
+	"os"
+	"syscall"
+
+	"golang.org/x/sys/windows"
+)
+
+// errorNotSameDevice is the error code returned when a file is moved to
+// another drive (ERROR_NOT_SAME_DEVICE).
+const errorNotSameDevice syscall.Errno = 17
+
+// IsCrossDevice reports whether a rename failed because the source and target
+// are on different drives.
+func IsCrossDevice(err error) bool {
+	return errors.Is(err, errorNotSameDevice)
+}
+
+// IsTransient reports whether a file operation failed due to a condition that
+// may clear by itself, such as a file that is open in another program or a
+// network share that timed out, in which case it is worth retrying.
+func IsTransient(err error) bool {
+	for _, errno := range []windows.Errno{
+		windows.ERROR_SHARING_VIOLATION,
+		windows.ERROR_LOCK_VIOLATION,
+		windows.ERROR_NETWORK_BUSY,
+		windows.ERROR_UNEXP_NET_ERR,
+		windows.ERROR_NETNAME_DELETED,
+		windows.ERROR_SEM_TIMEOUT,
+	} {
+		if errors.Is(err, errno) {
+			return true
+		}
+	}
+
+	return os.IsTimeout(err)
+}
+
+// TryLock takes an exclusive lock on the open file without waiting. It reports
+// false if the lock is held through another open file. The lock is released
+// when the file is closed. A byte far beyond the contents of the file is
+// locked since locks on Windows also prevent others from reading the locked
+// range.
+func TryLock(f *os.File) (bool, error) {
+	err := windows.LockFileEx(
+		windows.Handle(f.Fd()),
+		windows.LOCKFILE_EXCLUSIVE_LOCK|windows.LOCKFILE_FAIL_IMMEDIATELY,
+		0,
+		1,
+		0,
+		&windows.Overlapped{OffsetHigh: 1},
+	)
+	if errors.Is(err, windows.ERROR_LOCK_VIOLATION) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+// preserveOwner does nothing on Windows where files do not have a Unix owner.
+func preserveOwner(_ string, _ os.FileInfo) error {
+	return nil
+}
@@ -0,0 +1,323 @@
+// Package pattern provides the regular expressions that find patterns are
+// compiled to. Patterns use Go's RE2 syntax by default, or a backtracking
+// engine that supports lookarounds and backreferences with --pcre
+package pattern
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/dlclark/regexp2"
+)
+
+// matchTimeout limits how long a backtracking pattern may take to match a
+// name, since some patterns take exponential time. A match that times out is
+// treated as no match.
+const matchTimeout = time.Second
+
+// Regexp is a compiled find pattern. It is implemented by *regexp.Regexp and
+// by the backtracking engine that is used with --pcre.
+type Regexp interface {
+	MatchString(s string) bool
+	FindAllString(s string, n int) []string
+	FindAllStringSubmatchIndex(s string, n int) [][]int
+	ExpandString(dst []byte, template string, src string, match []int) []byte
+	ReplaceAllString(src, repl string) string
+	String() string
+}
+
+// backtracking is a pattern that is matched by a backtracking engine which is
+// compatible with Perl. The matches are reported in byte offsets like those
+// of the regexp package.
+type backtracking struct {
+	re *regexp2.Regexp
+	// slots maps each group number to the position of the group in a match
+	slots map[int]int
+	// names maps the name of each named group to its number
+	names map[string]int
+}
+
+// groupNameStart matches the start of a named group, which is (?P<name>,
+// (?<name>, or (?'name', as opposed to a lookbehind.
+var groupNameStart = regexp.MustCompile(`^\(\?(?:P?<|')([A-Za-z_][A-Za-z0-9_]*)[>']`)
+
+// backreferenceStart matches the start of a named backreference, which is
+// \k<name> or \k'name'.
+var backreferenceStart = regexp.MustCompile(`^\\k(?:<|')([A-Za-z_][A-Za-z0-9_]*)[>']`)
+
+// numberGroups turns the named groups in the expression into unnamed ones
+// and returns the expression along with the number of each named group. The
+// engine numbers named groups after the unnamed ones, so this keeps the groups
+// numbered in the order in which they open like in the regexp package.
+// Backreferences to named groups are changed to refer to their numbers.
+func numberGroups(expr string) (string, map[string]int) {
+	var sb strings.Builder
+
+	names := make(map[string]int)
+	groups := 0
+
+	for i := 0; i < len(expr); i++ {
+		rest := expr[i:]
+
+		switch {
+		case strings.HasPrefix(rest, `\k`):
+			m := backreferenceStart.FindStringSubmatch(rest)
+			if m == nil {
+				sb.WriteString(rest[:2])
+				i++
+
+				continue
+			}
+
+			if num, ok := names[m[1]]; ok {
+				sb.WriteString(`\k<` + strconv.Itoa(num) + ">")
+			} else {
+				sb.WriteString(m[0])
+			}
+
+			i += len(m[0]) - 1
+		case rest[0] == '\\' && len(rest) > 1:
+			sb.WriteString(rest[:2])
+			i++
+		case rest[0] == '[':
+			end := classEnd(rest)
+			sb.WriteString(rest[:end])
+			i += end - 1
+		case strings.HasPrefix(rest, "(?"):
+			m := groupNameStart.FindStringSubmatch(rest)
+			if m == nil {
+				sb.WriteByte('(')
+				continue
+			}
+
+			groups++
+
+			if _, ok := names[m[1]]; !ok {
+				names[m[1]] = groups
+			}
+
+			sb.WriteByte('(')
+			i += len(m[0]) - 1
+		case rest[0] == '(':
+			groups++
+
+			sb.WriteByte('(')
+		default:
+			sb.WriteByte(rest[0])
+		}
+	}
+
+	return sb.String(), names
+}
+
+// classEnd returns the length of the character class at the start of the
+// expression, in which parentheses do not start groups.
+func classEnd(expr string) int {
+	i := 1
+
+	// A closing bracket right after the opening one is a literal
+	if strings.HasPrefix(expr[i:], "^") {
+		i++
+	}
+
+	if strings.HasPrefix(expr[i:], "]") {
+		i++
+	}
+
+	for ; i < len(expr); i++ {
+		switch {
+		case expr[i] == '\\':
+			i++
+		case strings.HasPrefix(expr[i:], "[:"):
+			if end := strings.Index(expr[i:], ":]"); end >= 0 {
+				i += end + 1
+			}
+		case expr[i] == ']':
+			return i + 1
+		}
+	}
+
+	return len(expr)
+}
+
+// CompilePCRE compiles the expression with the backtracking engine (--pcre).
+// The expression may use lookarounds and backreferences in addition to the
+// RE2 syntax.
+func CompilePCRE(expr string) (Regexp, error) {
+	expr, names := numberGroups(expr)
+
+	re, err := regexp2.Compile(expr, regexp2.RE2)
+	if err != nil {
+		return nil, err
+	}
+
+	re.MatchTimeout = matchTimeout
+
+	b := &backtracking{
+		re:    re,
+		slots: make(map[int]int),
+		names: names,
+	}
+
+	for slot, num := range re.GetGroupNumbers() {
+		b.slots[num] = slot
+	}
+
+	return b, nil
+}
+
+// MatchString reports whether the string contains a match of the pattern.
+func (b *backtracking) MatchString(s string) bool {
+	ok, err := b.re.MatchString(s)
+
+	return err == nil && ok
+}
+
+// FindAllStringSubmatchIndex returns the byte offsets of each match and of
+// its groups, or nil if there is no match. At most n matches are returned if
+// n is not negative.
+func (b *backtracking) FindAllStringSubmatchIndex(s string, n int) [][]int {
+	runes := []rune(s)
+
+	// offsets[i] is the byte offset of the rune at index i
+	offsets := make([]int, len(runes)+1)
+	for i, r := range runes {
+		offsets[i+1] = offsets[i] + len(string(r))
+	}
+
+	var matches [][]int
+
+	m, err := b.re.FindRunesMatch(runes)
+
+	for err == nil && m != nil && (n < 0 || len(matches) < n) {
+		groups := m.Groups()
+		match := make([]int, 0, 2*len(groups))
+
+		for _, g := range groups {
+			if len(g.Captures) == 0 {
+				match = append(match, -1, -1)
+				continue
+			}
+
+			match = append(match, offsets[g.Index], offsets[g.Index+g.Length])
+		}
+
+		matches = append(matches, match)
+
+		m, err = b.re.FindNextMatch(m)
+	}
+
+	return matches
+}
+
+// FindAllString returns the text of each match. At most n matches are
+// returned if n is not negative.
+func (b *backtracking) FindAllString(s string, n int) []string {
+	var matches []string
+
+	for _, match := range b.FindAllStringSubmatchIndex(s, n) {
+		matches = append(matches, s[match[0]:match[1]])
+	}
+
+	return matches
+}
+
+// ExpandString appends the template to dst with the variables in it replaced
+// by the groups of the match in the same way as regexp.Regexp.ExpandString.
+// A variable is $name or ${name}, where the name is the number or the name of
+// a group, and $$ is a literal dollar sign.
+func (b *backtracking) ExpandString(
+	dst []byte,
+	template, src string,
+	match []int,
+) []byte {
+	for {
+		before, after, found := strings.Cut(template, "$")
+		if !found {
+			break
+		}
+
+		dst = append(dst, before...)
+		template = after
+
+		if strings.HasPrefix(template, "$") {
+			dst = append(dst, '$')
+			template = template[1:]
+
+			continue
+		}
+
+		name, rest, ok := groupName(template)
+		if !ok {
+			dst = append(dst, '$')
+			continue
+		}
+
+		template = rest
+
+		num, err := strconv.Atoi(name)
+		if err != nil {
+			num, ok = b.names[name]
+			if !ok {
+				continue
+			}
+		}
+
+		slot, ok := b.slots[num]
+		if ok && 2*slot+1 < len(match) && match[2*slot] >= 0 {
+			dst = append(dst, src[match[2*slot]:match[2*slot+1]]...)
+		}
+	}
+
+	return append(dst, template...)
+}
+
+// groupName returns the name of the group at the start of the template,
+// which follows a dollar sign, along with the rest of the template.
+func groupName(template string) (name, rest string, ok bool) {
+	if strings.HasPrefix(template, "{") {
+		name, rest, ok = strings.Cut(template[1:], "}")
+		if !ok || name == "" {
+			return "", "", false
+		}
+
+		return name, rest, true
+	}
+
+	end := strings.IndexFunc(template, func(r rune) bool {
+		return r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if end < 0 {
+		end = len(template)
+	}
+
+	if end == 0 {
+		return "", "", false
+	}
+
+	return template[:end], template[end:], true
+}
+
+// ReplaceAllString replaces every match in the string with the replacement
+// after expanding its variables.
+func (b *backtracking) ReplaceAllString(src, repl string) string {
+	var dst []byte
+
+	last := 0
+
+	for _, match := range b.FindAllStringSubmatchIndex(src, -1) {
+		dst = append(dst, src[last:match[0]]...)
+		dst = b.ExpandString(dst, repl, src, match)
+		last = match[1]
+	}
+
+	return string(append(dst, src[last:]...))
+}
+
+// String returns the expression that the pattern was compiled from.
+func (b *backtracking) String() string {
+	return b.re.String()
+}
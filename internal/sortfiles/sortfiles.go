@@ -8,7 +8,6 @@ import (
 	"os"
 	"path/filepath"
 	"slices"
-	"sort"
 	"strings"
 
 	"gopkg.in/djherbis/times.v1"
@@ -21,27 +20,57 @@ import (
 	"github.com/ayoisaiah/f2/v2/internal/pathutil"
 )
 
-func isPair(prev, curr *file.Change) bool {
-	return pathutil.StripExtension(
-		prev.SourcePath,
-	) == pathutil.StripExtension(
-		curr.SourcePath,
-	)
+// pairKeys returns the key used to group each change into pairs. The key is
+// the source path without its extension. Sidecar files with a double
+// extension (such as IMG_1.CR2.xmp or movie.en.srt) use the key of the file
+// they accompany if it exists.
+func pairKeys(changes file.Changes) map[*file.Change]string {
+	stems := make(map[string]bool, len(changes))
+
+	for _, ch := range changes {
+		stems[pathutil.StripExtension(ch.SourcePath)] = true
+	}
+
+	keys := make(map[*file.Change]string, len(changes))
+
+	for _, ch := range changes {
+		key := pathutil.StripExtension(ch.SourcePath)
+
+		if base := pathutil.StripExtension(key); base != key && stems[base] {
+			key = base
+		}
+
+		keys[ch] = key
+	}
+
+	return keys
 }
 
 // Pairs sorts the given file changes based on a custom pairing order.
 // Files with extensions matching earlier entries in pairOrder are sorted
-// before those matching later entries.
+// before those matching later entries. Sidecar files are always sorted after
+// the files they accompany.
 func Pairs(changes file.Changes, pairOrder []string) {
+	keys := pairKeys(changes)
+
+	isSidecar := func(ch *file.Change) bool {
+		return keys[ch] != pathutil.StripExtension(ch.SourcePath)
+	}
+
 	slices.SortStableFunc(changes, func(a, b *file.Change) int {
 		// Compare stripped paths
-		if result := strings.Compare(
-			pathutil.StripExtension(a.SourcePath),
-			pathutil.StripExtension(b.SourcePath),
-		); result != 0 {
+		if result := strings.Compare(keys[a], keys[b]); result != 0 {
 			return result
 		}
 
+		if aSidecar, bSidecar := isSidecar(a), isSidecar(b); aSidecar != bSidecar {
+			if aSidecar {
+				return 1
+			}
+
+			return -1
+		}
+
 		// Compare extensions based on pairOrder
 		aExt, bExt := filepath.Ext(a.Source), filepath.Ext(b.Source)
 
@@ -63,7 +92,7 @@ func Pairs(changes file.Changes, pairOrder []string) {
 		if i > 0 && i < len(changes) {
 			prev := changes[i-1]
 
-			if isPair(prev, v) {
+			if keys[prev] == keys[v] {
 				if prev.PrimaryPair != nil {
 					v.PrimaryPair = prev.PrimaryPair
 				} else {
@@ -107,6 +136,60 @@ func Hierarchically(changes file.Changes) {
 	})
 }
 
+// comparePaths compares two paths component by component so that the
+// contents of a directory are grouped together. If one path contains the
+// other, the parent is sorted first unless childrenFirst is set.
+func comparePaths(a, b string, childrenFirst bool) int {
+	partsA := strings.Split(a, string(filepath.Separator))
+	partsB := strings.Split(b, string(filepath.Separator))
+
+	for i := 0; i < len(partsA) && i < len(partsB); i++ {
+		if result := strings.Compare(partsA[i], partsB[i]); result != 0 {
+			return result
+		}
+	}
+
+	if childrenFirst {
+		return cmp.Compare(len(partsB), len(partsA))
+	}
+
+	return cmp.Compare(len(partsA), len(partsB))
+}
+
+// DepthFirst sorts the changes so that the contents of each directory are
+// processed right after the directory itself, or right before it if
+// childrenFirst is set. Entries in the same directory are sorted in lexical
+// order, and secondary files in a pair stay after their primary pair.
+func DepthFirst(changes file.Changes, childrenFirst bool) {
+	sourcePath := func(ch *file.Change) string {
+		if ch.PrimaryPair != nil {
+			return ch.PrimaryPair.SourcePath
+		}
+
+		return ch.SourcePath
+	}
+
+	slices.SortStableFunc(changes, func(a, b *file.Change) int {
+		return comparePaths(
+			filepath.Clean(sourcePath(a)),
+			filepath.Clean(sourcePath(b)),
+			childrenFirst,
+		)
+	})
+}
+
+// ByTraversal sorts the changes according to the configured traversal order.
+func ByTraversal(changes file.Changes, traversal config.Traversal) {
+	switch traversal {
+	case config.TraversalDefault, config.TraversalBreadth:
+		Hierarchically(changes)
+	case config.TraversalDepth:
+		DepthFirst(changes, false)
+	case config.TraversalDepthPost:
+		DepthFirst(changes, true)
+	}
+}
+
 // ByTime sorts the changes by the specified file timing attribute
 // (modified time, access time, change time, or birth time).
 func ByTime(
@@ -216,24 +299,37 @@ func BySize(changes file.Changes, conf *config.Config) {
 // Natural sorts the changes according to natural order (meaning numbers are
 // interpreted naturally). However, non-numeric characters are remain sorted in
 // ASCII order.
-func Natural(changes file.Changes, reverseSort bool) {
-	sort.SliceStable(changes, func(i, j int) bool {
-		sourcePathA := changes[i].SourcePath
-		sourcePathB := changes[j].SourcePath
+func Natural(changes file.Changes, conf *config.Config) {
+	slices.SortStableFunc(changes, func(a, b *file.Change) int {
+		sourcePathA, sourcePathB := a.SourcePath, b.SourcePath
 
-		if changes[i].PrimaryPair != nil {
-			sourcePathA = changes[i].PrimaryPair.SourcePath
+		if a.PrimaryPair != nil {
+			sourcePathA = a.PrimaryPair.SourcePath
 		}
 
-		if changes[j].PrimaryPair != nil {
-			sourcePathB = changes[j].PrimaryPair.SourcePath
+		if b.PrimaryPair != nil {
+			sourcePathB = b.PrimaryPair.SourcePath
 		}
 
-		if reverseSort {
-			return !natsort.Compare(sourcePathA, sourcePathB)
+		// Don't sort files in different directories relative to each other
+		if conf.SortPerDir && a.BaseDir != b.BaseDir {
+			return 0
+		}
+
+		var result int
+
+		switch {
+		case natsort.Compare(sourcePathA, sourcePathB):
+			result = -1
+		case natsort.Compare(sourcePathB, sourcePathA):
+			result = 1
+		}
+
+		if conf.ReverseSort {
+			return -result
 		}
 
-		return natsort.Compare(sourcePathA, sourcePathB)
+		return result
 	})
 }
 
@@ -249,7 +345,7 @@ func Changes(
 	//nolint:exhaustive // default sort not needed
 	switch conf.Sort {
 	case config.SortNatural:
-		Natural(changes, conf.ReverseSort)
+		Natural(changes, conf)
 	case config.SortSize:
 		BySize(changes, conf)
 	case config.SortMtime,
@@ -15,15 +15,16 @@ import (
 )
 
 type sortTestCase struct {
-	SortVar     string
-	Name        string
-	Unsorted    []string
-	Sorted      []string
-	Order       []string
-	SortValue   config.Sort
-	ReverseSort bool
-	SortPerDir  bool
-	Revert      bool
+	SortVar       string
+	Name          string
+	Unsorted      []string
+	Sorted        []string
+	Order         []string
+	SortValue     config.Sort
+	ReverseSort   bool
+	SortPerDir    bool
+	Revert        bool
+	ChildrenFirst bool
 }
 
 func sortTest(t *testing.T, unsorted []string) file.Changes {
@@ -111,6 +112,58 @@ func TestSortFiles_EnforceHierarchicalOrder(t *testing.T) {
 	}
 }
 
+func TestSortFiles_DepthFirst(t *testing.T) {
+	testCases := []sortTestCase{
+		{
+			Name: "sort directory contents after the directory",
+			Unsorted: []string{
+				"testdata/dir1/folder/15k.txt",
+				"testdata/dir1",
+				"testdata/20k.txt",
+				"testdata/dir1/folder",
+				"testdata/dir1/10k.txt",
+			},
+			Sorted: []string{
+				"testdata/20k.txt",
+				"testdata/dir1",
+				"testdata/dir1/10k.txt",
+				"testdata/dir1/folder",
+				"testdata/dir1/folder/15k.txt",
+			},
+		},
+		{
+			Name: "sort directory contents before the directory",
+			Unsorted: []string{
+				"testdata/dir1/folder/15k.txt",
+				"testdata/dir1",
+				"testdata/20k.txt",
+				"testdata/dir1/folder",
+				"testdata/dir1/10k.txt",
+			},
+			Sorted: []string{
+				"testdata/20k.txt",
+				"testdata/dir1/10k.txt",
+				"testdata/dir1/folder/15k.txt",
+				"testdata/dir1/folder",
+				"testdata/dir1",
+			},
+			ChildrenFirst: true,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.Name, func(t *testing.T) {
+			unsorted := sortTest(t, tc.Unsorted)
+
+			sortfiles.DepthFirst(unsorted, tc.ChildrenFirst)
+
+			testutil.CompareSourcePath(t, tc.Sorted, unsorted)
+		})
+	}
+}
+
 func TestSortFiles_BySize(t *testing.T) {
 	testCases := []sortTestCase{
 		{
@@ -394,6 +447,26 @@ func TestSortFiles_Natural(t *testing.T) {
 				"file_1.txt",
 			},
 		},
+		{
+			Name: "sort files numerically with --sort-per-dir",
+			Unsorted: []string{
+				"dir1/track10.mp3",
+				"track2.mp3",
+				"dir1/track9.mp3",
+				"track10.mp3",
+				"dir1/track1.mp3",
+				"track1.mp3",
+			},
+			Sorted: []string{
+				"track1.mp3",
+				"track2.mp3",
+				"track10.mp3",
+				"dir1/track1.mp3",
+				"dir1/track9.mp3",
+				"dir1/track10.mp3",
+			},
+			SortPerDir: true,
+		},
 		{
 			Name: "sort files with mixed case",
 			Unsorted: []string{
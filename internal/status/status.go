@@ -7,12 +7,15 @@ const (
 	Unchanged              Status = "unchanged"
 	Overwriting            Status = "overwriting"
 	EmptyFilename          Status = "empty filename"
-	TrailingPeriod         Status = "trailing periods present"
+	TrailingPeriod         Status = "trailing periods or spaces present"
 	PathExists             Status = "target exists"
 	OverwritingNewPath     Status = "overwriting new path"
 	ForbiddenCharacters    Status = "forbidden characters present"
+	ReservedName           Status = "reserved name"
 	FilenameLengthExceeded Status = "filename too long"
+	PathLengthExceeded     Status = "path too long"
 	TargetFileChanging     Status = "target file is changing"
 	SourceNotFound         Status = "source not found"
+	PermissionDenied       Status = "permission denied"
 	Ignored                Status = "ignored"
 )
@@ -1,7 +1,10 @@
 package testutil
 
 import (
+	"bytes"
+	"context"
 	"errors"
+	"io"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -25,10 +28,12 @@ type TestCase struct {
 	Error            error                                                `json:"error"`
 	SetEnv           map[string]string                                    `json:"env"`
 	SetupFunc        func(t *testing.T, testDir string) (teardown func()) `json:"-"`
+	WantContents     map[string]string                                    `json:"want_contents"`
 	StdoutGoldenFile string                                               `json:"stdout_golden_file"`
 	DefaultOpts      string                                               `json:"default_opts"`
 	Name             string                                               `json:"name"`
 	StderrGoldenFile string                                               `json:"stderr_golden_file"`
+	Stdin            string                                               `json:"stdin"`
 	SnapShot         struct {
 		Stdout []byte
 		Stderr []byte
@@ -37,10 +42,16 @@ type TestCase struct {
 	PathArgs         []string     `json:"path_args"`
 	Changes          file.Changes `json:"changes"`
 	Want             []string     `json:"want"`
+	WantMissing      []string     `json:"want_missing"`
+	ExitCode         int          `json:"exit_code"`
 	ConflictDetected bool         `json:"conflict_detected"`
 	PipeOutput       bool         `json:"pipe_output"`
 }
 
+// NewApp creates the application that RunApp runs. The f2 package cannot be
+// imported here since it imports the packages whose tests use this package.
+type NewApp func(reader io.Reader, writer io.Writer) (*cli.App, error)
+
 // SetupFileSystem creates all required files and folders for
 // the tests and returns the absolute path to the root directory.
 func SetupFileSystem(
@@ -95,6 +106,81 @@ func SetupFileSystem(
 	return testDir
 }
 
+// SetupWorkingDir changes the working directory to a new temporary directory
+// for the duration of the test, and creates the files in it (see WriteFiles).
+// The history and lock files of the test are kept in another temporary
+// directory.
+func SetupWorkingDir(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	t.Setenv(config.EnvStateHome, t.TempDir())
+
+	workingDir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testDir := t.TempDir()
+
+	err = os.Chdir(testDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stderr := config.Stderr
+
+	t.Cleanup(func() {
+		config.Stderr = stderr
+
+		_ = os.Chdir(workingDir)
+	})
+
+	WriteFiles(t, files)
+
+	return testDir
+}
+
+// WriteFiles creates the files with their contents, along with their parent
+// directories. The paths that end with a slash are created as directories.
+func WriteFiles(t *testing.T, files map[string]string) {
+	t.Helper()
+
+	for path, contents := range files {
+		path = filepath.FromSlash(path)
+
+		err := os.MkdirAll(filepath.Dir(path), 0o750)
+		if err == nil && !strings.HasSuffix(path, string(filepath.Separator)) {
+			err = os.WriteFile(path, []byte(contents), 0o600)
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// RunApp runs the application with the arguments of the test case as if they
+// were passed on the command line, and records what it writes to stdout and
+// stderr in the snapshot of the test case. The test case's Stdin is provided
+// as the standard input.
+func RunApp(ctx context.Context, newApp NewApp, tc *TestCase) error {
+	var stdout, stderr bytes.Buffer
+
+	app, err := newApp(strings.NewReader(tc.Stdin), &stdout)
+	if err != nil {
+		return err
+	}
+
+	config.Stderr = &stderr
+
+	err = app.RunContext(ctx, append([]string{"f2_test"}, tc.Args...))
+
+	tc.SnapShot.Stdout = stdout.Bytes()
+	tc.SnapShot.Stderr = stderr.Bytes()
+
+	return err
+}
+
 // CompareChanges compares the expected file changes to the ones received.
 func CompareChanges(t *testing.T, want, got file.Changes) {
 	t.Helper()
@@ -284,6 +370,7 @@ func GetConfig(t *testing.T, tc *TestCase, testDir string) *config.Config {
 	f2App.Action = func(ctx *cli.Context) error {
 		// Reset pterm to default state
 		pterm.EnableStyling()
+		pterm.EnableOutput()
 		// Re-initialize config with pipe output value set per test
 		_, _ = config.Init(ctx, tc.PipeOutput)
 
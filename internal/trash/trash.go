@@ -0,0 +1,76 @@
+// Package trash moves the files that are overwritten by a renaming operation
+// to the trash of the operating system so that they can be restored later
+package trash
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ayoisaiah/f2/v2/internal/osutil"
+)
+
+// EnvDataHome is the environment variable that sets the directory of the home
+// trash on Linux and other Unix systems according to the XDG Base Directory
+// Specification.
+const EnvDataHome = "XDG_DATA_HOME"
+
+var errRestoreExists = errors.New("a file already exists at the path")
+
+// Move moves the file or directory at the path to the trash and returns its
+// path in the trash, which is used to restore it.
+func Move(path string) (string, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+
+	return moveToTrash(path)
+}
+
+// Restore moves the file at the path in the trash back to the path and
+// removes the details that the trash keeps about it. An existing file at the
+// path is never replaced.
+func Restore(trashPath, path string) error {
+	if _, err := os.Lstat(path); err == nil {
+		return fmt.Errorf("%s: %w", path, errRestoreExists)
+	}
+
+	err := move(trashPath, path)
+	if err != nil {
+		return err
+	}
+
+	return forget(trashPath)
+}
+
+// move renames the source to the target, or copies it and removes the source
+// if they are on different filesystems.
+func move(source, target string) error {
+	err := os.Rename(source, target)
+	if err != nil && osutil.IsCrossDevice(err) {
+		return osutil.Move(source, target, osutil.CopyOptions{
+			Times: true,
+			Owner: true,
+		})
+	}
+
+	return err
+}
+
+// numberedName returns the name with a number added before its extension,
+// which is used when a file with the same name is already in the trash.
+func numberedName(name string, n int) string {
+	if n == 1 {
+		return name
+	}
+
+	ext := filepath.Ext(name)
+	if ext == name {
+		ext = ""
+	}
+
+	return fmt.Sprintf("%s.%d%s", strings.TrimSuffix(name, ext), n, ext)
+}
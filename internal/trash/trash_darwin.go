@@ -0,0 +1,61 @@
+//go:build darwin
+// +build darwin
+
+package trash
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+var errNotTrashed = errors.New("the path of the file in the trash is unknown")
+
+// trashScript moves the file at the path in its argument to the trash with
+// NSFileManager, which chooses the trash of the volume and a free name in it
+// like Finder does, and prints the path that the file was moved to.
+const trashScript = `function run(argv) {
+	ObjC.import("Foundation");
+
+	const url = $.NSURL.fileURLWithPath(argv[0]);
+	const trashed = Ref();
+	const error = Ref();
+
+	if (!$.NSFileManager.defaultManager.trashItemAtURLResultingItemURLError(url, trashed, error)) {
+		throw new Error(ObjC.unwrap(error[0].localizedDescription));
+	}
+
+	return ObjC.unwrap(trashed[0].path);
+}`
+
+// moveToTrash moves the file to the trash through osascript and returns its
+// path in the trash.
+func moveToTrash(path string) (string, error) {
+	var stderr strings.Builder
+
+	cmd := exec.Command("osascript", "-l", "JavaScript", "-e", trashScript, path)
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s: %w: %s", path, err, msg)
+		}
+
+		return "", fmt.Errorf("%s: %w", path, err)
+	}
+
+	trashPath := strings.TrimSuffix(string(out), "\n")
+	if trashPath == "" {
+		return "", fmt.Errorf("%s: %w", path, errNotTrashed)
+	}
+
+	return trashPath, nil
+}
+
+// forget does nothing on macOS where the trash has no record of the files
+// that is kept apart from them.
+func forget(_ string) error {
+	return nil
+}
@@ -0,0 +1,217 @@
+//go:build !windows && !darwin
+// +build !windows,!darwin
+
+package trash
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// trashPermission is the permission of the trash directories, which are only
+// accessible to their owner.
+const trashPermission = 0o700
+
+// homeTrash returns the trash directory of the current user, which is
+// $XDG_DATA_HOME/Trash or ~/.local/share/Trash if it is not set.
+func homeTrash() (string, error) {
+	if dataHome := os.Getenv(EnvDataHome); filepath.IsAbs(dataHome) {
+		return filepath.Join(dataHome, "Trash"), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(home, ".local", "share", "Trash"), nil
+}
+
+// device returns the ID of the filesystem that contains the path.
+func device(path string) (uint64, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return 0, err
+	}
+
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, errors.New("unable to determine the filesystem of " + path)
+	}
+
+	return uint64(stat.Dev), nil //nolint:unconvert // the type differs by OS
+}
+
+// topDir returns the mount point of the filesystem that contains the path.
+func topDir(path string) (string, error) {
+	dev, err := device(path)
+	if err != nil {
+		return "", err
+	}
+
+	for {
+		parent := filepath.Dir(path)
+		if parent == path {
+			return path, nil
+		}
+
+		parentDev, err := device(parent)
+		if err != nil {
+			return "", err
+		}
+
+		if parentDev != dev {
+			return path, nil
+		}
+
+		path = parent
+	}
+}
+
+// trashDir returns the trash directory for the path along with the directory
+// that the paths recorded in it are relative to. The home trash is used for
+// paths on the same filesystem as the home directory, while the trash at the
+// top of the filesystem is used for other paths so that the file does not
+// need to be copied.
+func trashDir(path string) (dir, base string, err error) {
+	home, err := homeTrash()
+	if err != nil {
+		return "", "", err
+	}
+
+	err = os.MkdirAll(home, trashPermission)
+	if err != nil {
+		return "", "", err
+	}
+
+	homeDev, err := device(home)
+	if err != nil {
+		return "", "", err
+	}
+
+	dev, err := device(path)
+	if err != nil {
+		return "", "", err
+	}
+
+	if dev == homeDev {
+		return home, "", nil
+	}
+
+	top, err := topDir(path)
+	if err != nil {
+		return "", "", err
+	}
+
+	uid := strconv.Itoa(os.Getuid())
+
+	// The shared trash must be a sticky directory that is not a link
+	shared := filepath.Join(top, ".Trash")
+
+	info, err := os.Lstat(shared)
+	if err == nil && info.IsDir() && info.Mode()&os.ModeSticky != 0 {
+		dir = filepath.Join(shared, uid)
+	} else {
+		dir = filepath.Join(top, ".Trash-"+uid)
+	}
+
+	err = os.MkdirAll(dir, trashPermission)
+	if err != nil {
+		return "", "", err
+	}
+
+	return dir, top, nil
+}
+
+// moveToTrash moves the file to the trash according to the FreeDesktop.org
+// Trash specification. The details of the file are written to an info file
+// which also reserves its name in the trash.
+func moveToTrash(path string) (string, error) {
+	dir, base, err := trashDir(path)
+	if err != nil {
+		return "", err
+	}
+
+	filesDir := filepath.Join(dir, "files")
+	infoDir := filepath.Join(dir, "info")
+
+	for _, d := range []string{filesDir, infoDir} {
+		err = os.MkdirAll(d, trashPermission)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	recordedPath := path
+
+	if base != "" {
+		recordedPath, err = filepath.Rel(base, path)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	info := fmt.Sprintf(
+		"[Trash Info]\nPath=%s\nDeletionDate=%s\n",
+		(&url.URL{Path: recordedPath}).EscapedPath(),
+		time.Now().Format("2006-01-02T15:04:05"),
+	)
+
+	for n := 1; ; n++ {
+		name := numberedName(filepath.Base(path), n)
+		infoPath := filepath.Join(infoDir, name+".trashinfo")
+		trashPath := filepath.Join(filesDir, name)
+
+		f, err := os.OpenFile(infoPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+		if errors.Is(err, os.ErrExist) {
+			continue
+		}
+
+		if err != nil {
+			return "", err
+		}
+
+		_, err = f.WriteString(info)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+
+		if err == nil {
+			if _, statErr := os.Lstat(trashPath); statErr == nil {
+				_ = os.Remove(infoPath)
+				continue
+			}
+
+			err = move(path, trashPath)
+		}
+
+		if err != nil {
+			_ = os.Remove(infoPath)
+			return "", err
+		}
+
+		return trashPath, nil
+	}
+}
+
+// forget removes the info file of the file that was restored from the trash.
+func forget(trashPath string) error {
+	infoPath := filepath.Join(
+		filepath.Dir(filepath.Dir(trashPath)),
+		"info",
+		filepath.Base(trashPath)+".trashinfo",
+	)
+
+	err := os.Remove(infoPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	return err
+}
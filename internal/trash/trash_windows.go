@@ -0,0 +1,219 @@
+//go:build windows
+// +build windows
+
+package trash
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	errNoRecycleBin = errors.New("the path is not on a drive with a Recycle Bin")
+
+	errNotRecycled = errors.New(
+		"the file could not be found in the Recycle Bin after it was deleted",
+	)
+
+	procSHFileOperationW = windows.NewLazySystemDLL("shell32.dll").
+				NewProc("SHFileOperationW")
+)
+
+// The operation and flags of SHFileOperationW that delete a file to the
+// Recycle Bin without showing any progress or error dialogs.
+const (
+	foDelete           = 0x0003
+	fofSilent          = 0x0004
+	fofNoConfirmation  = 0x0010
+	fofAllowUndo       = 0x0040
+	fofNoErrorUI       = 0x0400
+	fofWantNukeWarning = 0x4000
+)
+
+// The versions of the format of the $I files that record the details of the
+// files in the Recycle Bin. The original path has a fixed length before
+// Windows 10.
+const (
+	recycleInfoV1 = 1
+	recycleInfoV2 = 2
+
+	recycleInfoV1PathLen = 260
+)
+
+// recycleBin returns the Recycle Bin of the current user on the drive of the
+// path, which is $Recycle.Bin\<SID> at the root of the drive.
+func recycleBin(path string) (string, error) {
+	volume := filepath.VolumeName(path)
+	if len(volume) != 2 || volume[1] != ':' {
+		return "", errNoRecycleBin
+	}
+
+	token, err := windows.OpenCurrentProcessToken()
+	if err != nil {
+		return "", err
+	}
+
+	defer token.Close()
+
+	user, err := token.GetTokenUser()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(volume+`\`, "$Recycle.Bin", user.User.Sid.String()), nil
+}
+
+// recycleInfo returns the original path and the deletion time of the file in
+// the Recycle Bin from the contents of its $I file.
+func recycleInfo(b []byte) (path string, deleted int64, err error) {
+	var header struct {
+		Version int64
+		Size    int64
+		Deleted windows.Filetime
+	}
+
+	r := bytes.NewReader(b)
+
+	err = binary.Read(r, binary.LittleEndian, &header)
+	if err != nil {
+		return "", 0, err
+	}
+
+	var name []uint16
+
+	switch header.Version {
+	case recycleInfoV1:
+		name = make([]uint16, recycleInfoV1PathLen)
+	case recycleInfoV2:
+		var n uint32
+
+		err = binary.Read(r, binary.LittleEndian, &n)
+		if err != nil {
+			return "", 0, err
+		}
+
+		name = make([]uint16, min(int(n), r.Len()/2))
+	default:
+		return "", 0, fmt.Errorf("unknown version %d", header.Version)
+	}
+
+	err = binary.Read(r, binary.LittleEndian, name)
+	if err != nil {
+		return "", 0, err
+	}
+
+	return windows.UTF16ToString(name), header.Deleted.Nanoseconds(), nil
+}
+
+// findRecycled returns the path in the Recycle Bin of the file that was most
+// recently deleted from the path. Windows names it $R<random><ext> and records
+// its details in the matching $I<random><ext> file.
+func findRecycled(dir, path string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+
+	var (
+		found  string
+		latest int64
+	)
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasPrefix(name, "$I") {
+			continue
+		}
+
+		b, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+
+		original, deleted, err := recycleInfo(b)
+		if err != nil || !strings.EqualFold(original, path) {
+			continue
+		}
+
+		if found == "" || deleted > latest {
+			found = filepath.Join(dir, "$R"+name[2:])
+			latest = deleted
+		}
+	}
+
+	if found == "" {
+		return "", errNotRecycled
+	}
+
+	return found, nil
+}
+
+// moveToTrash deletes the file to the Recycle Bin through the shell so that
+// it can also be restored from there, and returns the path that Windows moved
+// it to. The user is warned before a file that does not fit in the Recycle
+// Bin is deleted permanently.
+func moveToTrash(path string) (string, error) {
+	dir, err := recycleBin(path)
+	if err != nil {
+		return "", err
+	}
+
+	from, err := windows.UTF16FromString(path)
+	if err != nil {
+		return "", err
+	}
+
+	// The list of paths to delete ends with an empty string
+	from = append(from, 0)
+
+	op := shFileOpStruct{
+		wFunc: foDelete,
+		pFrom: &from[0],
+		fFlags: fofAllowUndo | fofNoConfirmation | fofNoErrorUI | fofSilent |
+			fofWantNukeWarning,
+	}
+
+	ret, _, _ := procSHFileOperationW.Call(uintptr(unsafe.Pointer(&op)))
+	if ret != 0 {
+		return "", &os.PathError{
+			Op:   "trash",
+			Path: path,
+			Err:  windows.Errno(ret),
+		}
+	}
+
+	// The file is left in place when the operation is aborted
+	if _, err = os.Lstat(path); err == nil {
+		return "", &os.PathError{
+			Op:   "trash",
+			Path: path,
+			Err:  windows.ERROR_CANCELLED,
+		}
+	}
+
+	return findRecycled(dir, path)
+}
+
+// forget removes the $I file of the file that was restored from the Recycle
+// Bin.
+func forget(trashPath string) error {
+	name := filepath.Base(trashPath)
+	if !strings.HasPrefix(name, "$R") {
+		return nil
+	}
+
+	err := os.Remove(filepath.Join(filepath.Dir(trashPath), "$I"+name[2:]))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	return err
+}
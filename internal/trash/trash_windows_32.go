@@ -0,0 +1,17 @@
+//go:build windows && (386 || arm)
+// +build windows
+// +build 386 arm
+
+package trash
+
+// shFileOpStruct is the SHFILEOPSTRUCTW structure of SHFileOperationW, which
+// is packed on 32-bit Windows. The blank fields are hwnd, pTo, and the
+// unaligned fields after fFlags, which are not used.
+type shFileOpStruct struct {
+	_      uintptr
+	wFunc  uint32
+	pFrom  *uint16
+	_      *uint16
+	fFlags uint16
+	_      [12]byte
+}
@@ -0,0 +1,18 @@
+//go:build windows && !386 && !arm
+// +build windows,!386,!arm
+
+package trash
+
+// shFileOpStruct is the SHFILEOPSTRUCTW structure of SHFileOperationW. The
+// blank fields are hwnd, pTo, fAnyOperationsAborted, hNameMappings, and
+// lpszProgressTitle, which are not used.
+type shFileOpStruct struct {
+	_      uintptr
+	wFunc  uint32
+	pFrom  *uint16
+	_      *uint16
+	fFlags uint16
+	_      int32
+	_      uintptr
+	_      *uint16
+}
@@ -5,21 +5,25 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"slices"
 
 	"github.com/ayoisaiah/f2/v2/internal/config"
 	"github.com/ayoisaiah/f2/v2/internal/file"
 	"github.com/ayoisaiah/f2/v2/internal/osutil"
+	"github.com/ayoisaiah/f2/v2/internal/status"
 )
 
+// createBackupFile creates the backup file of a renaming operation in the
+// history directory.
 func createBackupFile(fileName string) (io.Writer, error) {
-	backupFilePath := filepath.Join(
-		os.TempDir(),
-		"f2",
-		"backups",
-		fileName,
-	)
-
-	err := os.MkdirAll(filepath.Dir(backupFilePath), osutil.DirPermission)
+	historyDir, err := config.HistoryDir()
+	if err != nil {
+		return nil, err
+	}
+
+	backupFilePath := filepath.Join(historyDir, fileName)
+
+	err = os.MkdirAll(historyDir, osutil.DirPermission)
 	if err != nil {
 		return nil, err
 	}
@@ -35,25 +39,84 @@ func createBackupFile(fileName string) (io.Writer, error) {
 
 // backupChanges records the details of a renaming operation to the specified
 // writer so that it may be reverted if necessary. If a writer is not specified
-// it records the changes to the filesystem.
+// it records the changes to the filesystem. Changes whose source and target
+// are the same are left out since there is nothing to revert, along with the
+// changes that were not made because they failed or were skipped. Nothing is
+// recorded if no other changes remain.
 func backupChanges(
+	conf *config.Config,
 	changes file.Changes,
 	cleanedDirs []string,
-	fileName string,
-	w io.Writer,
 ) error {
 	var err error
 
+	w := conf.BackupLocation
+
+	changes = slices.DeleteFunc(slices.Clone(changes), func(ch *file.Change) bool {
+		return ch.SourcePath == ch.TargetPath || ch.Error != nil ||
+			ch.Status == status.Ignored
+	})
+
+	if len(changes) == 0 {
+		return nil
+	}
+
 	if w == nil {
-		w, err = createBackupFile(fileName)
+		w, err = createBackupFile(conf.BackupFilename)
 		if err != nil {
 			return err
 		}
 	}
 
+	var overwritten []string
+
+	files := make(map[string]config.FileState)
+
+	linked := conf.Operation == config.OperationSymlink ||
+		conf.Operation == config.OperationHardlink
+
+	for _, ch := range changes {
+		if ch.Error != nil {
+			continue
+		}
+
+		// Files that were moved to the trash or backed up are restored on
+		// undo
+		if ch.WillOverwrite && ch.Trashed == "" && ch.Backup == "" {
+			overwritten = append(overwritten, ch.TargetPath)
+		}
+
+		// Links do not hold contents of their own
+		if ch.IsDir || ch.IsSymlink || linked {
+			continue
+		}
+
+		info, err := os.Stat(ch.TargetPath)
+		if err != nil {
+			continue
+		}
+
+		checksum, err := osutil.Checksum(ch.TargetPath)
+		if err != nil {
+			continue
+		}
+
+		files[ch.TargetPath] = config.FileState{
+			Checksum: checksum,
+			Size:     info.Size(),
+		}
+	}
+
 	b := config.Backup{
 		Changes:     changes,
 		CleanedDirs: cleanedDirs,
+		Overwritten: overwritten,
+		Files:       files,
+		Operation:   conf.Operation,
+		WorkingDir:  conf.WorkingDir,
+		CSV:         conf.CSVFilename,
+		Find:        conf.FindSlice,
+		Replace:     conf.ReplacementSlice,
 	}
 
 	err = b.RenderJSON(w)
@@ -67,3 +130,97 @@ func backupChanges(
 
 	return nil
 }
+
+// recordFixedTargets updates the backups of the undone operations when files
+// could not be restored to their original names and were renamed to different
+// ones to resolve conflicts (-F). Each file is followed through the operations
+// from the most recent one, and the oldest operation that renamed it is
+// updated to start from the name that the file has after the undo so that
+// redoing the operations does not rename an unrelated file that now has its
+// original name.
+func recordFixedTargets(
+	conf *config.Config,
+	backupFilePaths []string,
+	fileChanges file.Changes,
+) error {
+	backups := make([]*config.Backup, len(backupFilePaths))
+
+	for i, backupFilePath := range backupFilePaths {
+		backup, err := config.ReadBackup(backupFilePath)
+		if err != nil {
+			return err
+		}
+
+		backups[i] = backup
+	}
+
+	// Paths are relative to the directory where each operation was performed
+	resolve := func(backup *config.Backup, path string) string {
+		if backup.WorkingDir == "" || backup.WorkingDir == conf.WorkingDir ||
+			filepath.IsAbs(path) {
+			return path
+		}
+
+		return filepath.Join(backup.WorkingDir, path)
+	}
+
+	updated := make([]bool, len(backups))
+
+	for _, ch := range fileChanges {
+		if ch.Error != nil || ch.Status == status.Ignored {
+			continue
+		}
+
+		var (
+			origin      *file.Change
+			originIndex int
+		)
+
+		path := ch.SourcePath
+
+		for i, backup := range backups {
+			for _, bc := range backup.Changes {
+				if resolve(backup, filepath.Join(bc.TargetDir, bc.Target)) != path {
+					continue
+				}
+
+				origin, originIndex = bc, i
+				path = resolve(backup, filepath.Join(bc.BaseDir, bc.Source))
+
+				break
+			}
+		}
+
+		if origin == nil || path == ch.TargetPath {
+			continue
+		}
+
+		dir := filepath.Dir(ch.TargetPath)
+
+		if backup := backups[originIndex]; resolve(backup, dir) != dir {
+			rel, err := filepath.Rel(backup.WorkingDir, dir)
+			if err != nil {
+				return err
+			}
+
+			dir = rel
+		}
+
+		origin.Source = filepath.Base(ch.TargetPath)
+		origin.BaseDir = dir
+		updated[originIndex] = true
+	}
+
+	for i, backup := range backups {
+		if !updated[i] {
+			continue
+		}
+
+		err := config.WriteBackup(backupFilePaths[i], backup)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package rename
+
+import (
+	"bufio"
+	"strings"
+
+	"github.com/ayoisaiah/f2/v2/internal/config"
+	"github.com/ayoisaiah/f2/v2/internal/file"
+	"github.com/ayoisaiah/f2/v2/report"
+)
+
+// confirmer asks whether to commit each change before it is committed
+// (--interactive) until the remaining changes are all approved or all
+// skipped.
+type confirmer struct {
+	reader *bufio.Reader
+	all    bool
+	quit   bool
+}
+
+// newConfirmer returns a confirmer that reads the answers from the standard
+// input, or nil if the changes are committed without asking.
+func newConfirmer(conf *config.Config) *confirmer {
+	if !conf.Interactive {
+		return nil
+	}
+
+	return &confirmer{
+		reader: bufio.NewReader(config.Stdin),
+	}
+}
+
+// confirm reports whether the change should be committed. The question is
+// asked again until a valid answer is given, and the change is skipped if the
+// answer is empty. Reaching the end of the input skips the remaining changes.
+func (cf *confirmer) confirm(conf *config.Config, ch *file.Change) bool {
+	if cf == nil || cf.all {
+		return true
+	}
+
+	for !cf.quit {
+		report.Confirm(conf, ch)
+
+		line, err := cf.reader.ReadString('\n')
+		if err != nil && line == "" {
+			cf.quit = true
+			break
+		}
+
+		switch strings.ToLower(strings.TrimSpace(line)) {
+		case "y", "yes":
+			return true
+		case "n", "no", "":
+			return false
+		case "a", "all":
+			cf.all = true
+			return true
+		case "q", "quit":
+			cf.quit = true
+		}
+	}
+
+	return false
+}
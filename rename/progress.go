@@ -0,0 +1,123 @@
+package rename
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/ayoisaiah/f2/v2/internal/config"
+	"github.com/ayoisaiah/f2/v2/report"
+)
+
+// The progress of a renaming operation is shown once it has taken longer than
+// progressDelay. It is updated at progressInterval on a terminal, and at
+// plainProgressInterval otherwise so that logs are not flooded.
+const (
+	progressDelay         = time.Second
+	progressInterval      = 100 * time.Millisecond
+	plainProgressInterval = 5 * time.Second
+)
+
+// showingProgress is set while the progress of the operation is shown so that
+// the progress of moving a large file does not overwrite it.
+var showingProgress atomic.Bool
+
+// progress reports how many of the changes have been committed while a
+// renaming operation runs.
+type progress struct {
+	start   time.Time
+	stop    chan struct{}
+	stopped chan struct{}
+	done    atomic.Int64
+	total   int
+}
+
+// startProgress starts reporting the progress of committing the number of
+// changes. It returns nil in quiet mode, in debug mode where each change is
+// logged instead, and in interactive mode where each change is confirmed.
+func startProgress(conf *config.Config, total int) *progress {
+	if conf.Quiet || conf.Debug || conf.Interactive || total == 0 {
+		return nil
+	}
+
+	p := &progress{
+		start:   time.Now(),
+		stop:    make(chan struct{}),
+		stopped: make(chan struct{}),
+		total:   total,
+	}
+
+	go p.run(config.StderrIsTerminal())
+
+	return p
+}
+
+// add records that a change was committed.
+func (p *progress) add() {
+	if p != nil {
+		p.done.Add(1)
+	}
+}
+
+// finish stops reporting the progress once all the changes are committed.
+func (p *progress) finish() {
+	if p == nil {
+		return
+	}
+
+	close(p.stop)
+	<-p.stopped
+}
+
+// run reports the progress until it is stopped. Nothing is reported if the
+// operation finishes before the delay.
+func (p *progress) run(terminal bool) {
+	defer close(p.stopped)
+
+	delay := time.NewTimer(progressDelay)
+	defer delay.Stop()
+
+	select {
+	case <-p.stop:
+		return
+	case <-delay.C:
+	}
+
+	showingProgress.Store(true)
+	defer showingProgress.Store(false)
+
+	interval := plainProgressInterval
+	if terminal {
+		interval = progressInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		p.report(terminal)
+
+		select {
+		case <-p.stop:
+			if terminal {
+				report.ProgressDone()
+			}
+
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// report prints the number of changes committed so far along with an
+// estimate of the time left based on the rate so far.
+func (p *progress) report(terminal bool) {
+	done := int(p.done.Load())
+
+	var eta time.Duration
+	if done > 0 {
+		eta = time.Since(p.start) * time.Duration(p.total-done) /
+			time.Duration(done)
+	}
+
+	report.Progress(done, p.total, eta, terminal)
+}
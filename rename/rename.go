@@ -3,101 +3,964 @@
 package rename
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
-	"runtime"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/ayoisaiah/f2/v2/internal/apperr"
 	"github.com/ayoisaiah/f2/v2/internal/config"
 	"github.com/ayoisaiah/f2/v2/internal/file"
+	"github.com/ayoisaiah/f2/v2/internal/gitutil"
 	"github.com/ayoisaiah/f2/v2/internal/osutil"
 	"github.com/ayoisaiah/f2/v2/internal/status"
+	"github.com/ayoisaiah/f2/v2/internal/trash"
 	"github.com/ayoisaiah/f2/v2/report"
 )
 
-var errRenameFailed = &apperr.Error{
-	Message: "some files could not be renamed",
+var (
+	errRenameFailed = &apperr.Error{
+		Message:  "some files could not be renamed",
+		ExitCode: int(osutil.ExitPartialFailure),
+	}
+
+	errRenameAborted = &apperr.Error{
+		Message:  "renaming was aborted because a file could not be renamed",
+		ExitCode: int(osutil.ExitPartialFailure),
+	}
+
+	errRenameRolledBack = &apperr.Error{
+		Message:  "renaming was rolled back because a file could not be renamed",
+		ExitCode: int(osutil.ExitPartialFailure),
+	}
+
+	errUndoIncomplete = &apperr.Error{
+		Message:  "some files were not restored since they were modified after they were renamed",
+		ExitCode: int(osutil.ExitPartialFailure),
+	}
+
+	errSourceModified = errors.New(
+		"the file was modified after it was matched (use --allow-modified to rename it)",
+	)
+
+	errTargetExists = errors.New(
+		"the target already exists",
+	)
+)
+
+// step is a change to the filesystem that was made while committing the
+// changes. It is undone by moving the path back, or by removing the path if
+// it was created. Files that were moved to the trash are restored from it.
+type step struct {
+	from, to string
+	// index is the change that the step belongs to, or -1 for directories
+	// that were created for the targets
+	index   int
+	created bool
+	trashed bool
 }
 
 // traversedDirs records the directories that were traversed during a renaming
 // operation.
 var traversedDirs = make(map[string]string)
 
-// commit iterates over all the matches and renames them on the filesystem.
-// Directories are auto-created if necessary, and errors are aggregated.
-func commit(fileChanges file.Changes) []int {
-	var errIndices []int
+// tempPath returns a temporary path in the same directory as the provided
+// path by prefixing and suffixing its name with __<time>__.
+func tempPath(path string) string {
+	timeStr := fmt.Sprintf("%d", time.Now().UnixNano())
+
+	return filepath.Join(
+		filepath.Dir(path),
+		"__"+timeStr+"__"+filepath.Base(path)+"__"+timeStr+"__",
+	)
+}
+
+// commitOrder returns the order in which the changes are committed along
+// with the temporary path for each change that is routed through one (see
+// file.Changes.CommitOrder). Copies and links are committed in order since
+// their sources stay in place.
+func commitOrder(
+	fileChanges file.Changes,
+	operation string,
+	twoPhase bool,
+) ([]int, map[int]string) {
+	if config.KeepsSources(operation) {
+		order := make([]int, len(fileChanges))
+		for i := range order {
+			order[i] = i
+		}
+
+		return order, nil
+	}
+
+	order := fileChanges.CommitOrder()
+
+	tempPaths := make(map[int]string)
+
+	seen := make(map[int]bool, len(order))
+
+	for _, i := range order {
+		if seen[i] {
+			tempPaths[i] = tempPath(fileChanges[i].SourcePath)
+		}
+
+		seen[i] = true
+	}
+
+	if twoPhase {
+		return stageFiles(fileChanges, order, tempPaths)
+	}
+
+	return order, tempPaths
+}
+
+// stageFiles returns a commit order in which every file is moved to a
+// temporary path before any of them is moved to its target, so that no
+// target is taken by a file that has yet to be renamed (--two-phase).
+// Directories keep their place in the order after the files since moving a
+// directory also moves the temporary paths of the files under it.
+func stageFiles(
+	fileChanges file.Changes,
+	order []int,
+	tempPaths map[int]string,
+) ([]int, map[int]string) {
+	var staged, rest []int
 
-	for i := range fileChanges {
+	seen := make(map[int]bool, len(order))
+
+	for _, i := range order {
 		ch := fileChanges[i]
 
-		if ch.Status == status.Ignored {
+		if ch.IsDir || ch.Remove || ch.SourcePath == ch.TargetPath {
+			rest = append(rest, i)
 			continue
 		}
 
-		targetPath := ch.TargetPath
+		if !seen[i] {
+			staged = append(staged, i)
+			tempPaths[i] = tempPath(ch.SourcePath)
+		}
+
+		seen[i] = true
+	}
+
+	return slices.Concat(staged, staged, rest), tempPaths
+}
+
+// copyOptions returns the attributes that are kept when files are copied
+// (--no-preserve and --preserve-owner).
+func copyOptions(conf *config.Config) osutil.CopyOptions {
+	return osutil.CopyOptions{
+		Times: !conf.NoPreserve,
+		Owner: conf.PreserveOwner,
+	}
+}
+
+// copyOrLink copies the source to the target or creates a link to it
+// according to the operation. Links are created at a temporary path first and
+// moved to the target so that an existing target is replaced in one step when
+// overwriting is allowed. Symbolic links point to the source relative to the
+// target so that the tree of links can be moved along with the sources.
+func copyOrLink(conf *config.Config, sourcePath, targetPath string) error {
+	var err error
+
+	tmp := tempPath(targetPath)
+
+	switch conf.Operation {
+	case config.OperationSymlink:
+		err = os.Symlink(linkPath(sourcePath, targetPath), tmp)
+	case config.OperationHardlink:
+		err = os.Link(sourcePath, tmp)
+	default:
+		return osutil.CopyFile(sourcePath, targetPath, copyOptions(conf))
+	}
+
+	if err != nil {
+		return err
+	}
+
+	err = os.Rename(tmp, targetPath)
+	if err != nil {
+		_ = os.Remove(tmp)
+	}
+
+	return err
+}
+
+// linkPath returns the path that a symbolic link at the target uses to point
+// to the source. It is relative to the directory of the target when possible.
+func linkPath(sourcePath, targetPath string) string {
+	source, err := filepath.Abs(sourcePath)
+	if err != nil {
+		return sourcePath
+	}
+
+	targetDir, err := filepath.Abs(filepath.Dir(targetPath))
+	if err != nil {
+		return source
+	}
+
+	rel, err := filepath.Rel(targetDir, source)
+	if err != nil {
+		return source
+	}
+
+	return rel
+}
+
+// largeFileSize is the size from which the progress of moving a file to
+// another filesystem is reported.
+const largeFileSize = 64 << 20
+
+// moveProgress returns a function that reports the progress of moving the
+// file at the path to another filesystem if it is large enough.
+func moveProgress(
+	conf *config.Config,
+	path string,
+) func(copied, total int64) {
+	if conf.Quiet || showingProgress.Load() {
+		return nil
+	}
+
+	last := -1
+
+	return func(copied, total int64) {
+		if total < largeFileSize {
+			return
+		}
+
+		percent := int(copied * 100 / total)
+		if percent != last {
+			last = percent
+			report.MoveProgress(path, percent)
+		}
+	}
+}
+
+// The delay before the first retry of an operation that failed due to a
+// transient error, which doubles after each attempt up to the maximum
+// (--retries).
+const (
+	retryDelay    = 100 * time.Millisecond
+	maxRetryDelay = 10 * time.Second
+)
+
+// withRetries calls fn until it succeeds, fails with an error that is not
+// transient, or the number of retries is used up (--retries). The path is the
+// file that fn operates on, which is reported before each retry.
+func withRetries(conf *config.Config, path string, fn func() error) error {
+	delay := retryDelay
+
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt > conf.Retries || !osutil.IsTransient(err) {
+			return err
+		}
+
+		report.Retrying(conf, path, delay, err)
+
+		time.Sleep(delay)
+
+		delay = min(delay*2, maxRetryDelay)
+	}
+}
+
+// move renames the source to the target. Files that are tracked in a git
+// repository are renamed with git if --git is set. When the source and target
+// are on different filesystems, the source is copied to the target and
+// removed instead. Transient failures are retried (--retries).
+func move(conf *config.Config, sourcePath, targetPath string) error {
+	return withRetries(conf, sourcePath, func() error {
+		if conf.Git {
+			moved, err := gitutil.Move(sourcePath, targetPath)
+			if moved || err != nil {
+				return err
+			}
+		}
+
+		err := os.Rename(sourcePath, targetPath)
+		if err != nil && osutil.IsCrossDevice(err) {
+			opts := copyOptions(conf)
+			opts.Progress = moveProgress(conf, sourcePath)
+
+			return osutil.Move(sourcePath, targetPath, opts)
+		}
+
+		return err
+	})
+}
+
+// missingDirs returns the directories that need to be created for the path to
+// exist, starting with the outermost one.
+func missingDirs(path string) []string {
+	var dirs []string
+
+	for {
+		if _, err := os.Lstat(path); err == nil {
+			break
+		}
+
+		dirs = append([]string{path}, dirs...)
+
+		parent := filepath.Dir(path)
+		if parent == path {
+			break
+		}
+
+		path = parent
+	}
+
+	return dirs
+}
+
+// rollback undoes the steps in reverse order so that the tree is left as it
+// was before the changes were committed (--on-error=rollback). The changes
+// that were undone are marked as ignored, while the steps that could not be
+// undone are reported and their changes are kept so that they are recorded in
+// the history.
+func rollback(conf *config.Config, fileChanges file.Changes, steps []step) {
+	failed := make(map[int]bool)
+
+	for i := len(steps) - 1; i >= 0; i-- {
+		s := steps[i]
+
+		var err error
+
+		switch {
+		case s.created:
+			err = os.Remove(s.to)
+		case s.trashed:
+			err = trash.Restore(s.to, s.from)
+		default:
+			err = move(conf, s.to, s.from)
+		}
+
+		if err != nil {
+			report.RollbackFailed(s.to, err)
+
+			if s.index >= 0 {
+				failed[s.index] = true
+			}
+		}
+	}
+
+	for _, s := range steps {
+		if s.index >= 0 && !failed[s.index] &&
+			fileChanges[s.index].Error == nil {
+			fileChanges[s.index].Status = status.Ignored
+		}
+	}
+}
+
+// committer commits the changes to the filesystem and records the outcome of
+// each one. The changes in a batch of independent changes are committed
+// concurrently, so the shared state is guarded by the mutex.
+type committer struct {
+	conf        *config.Config
+	fileChanges file.Changes
+	tempPaths   map[int]string
+	movedToTemp map[int]bool
+	// the steps taken so far, which are undone if rolling back on error
+	steps      []step
+	errIndices []int
+	// the number of files that are waiting at a temporary path
+	inTemp int
+	// when the next change may be committed (--throttle)
+	next time.Time
+	// reports the progress of long operations
+	progress *progress
+	// asks before each change is committed (--interactive)
+	confirm *confirmer
+	mu      sync.Mutex
+}
+
+// wait blocks until the next change may be committed so that the changes are
+// spread out by the interval that limits their rate (--throttle). The workers
+// share the limit.
+func (c *committer) wait() {
+	if c.conf.Throttle <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+
+	at := c.next
+
+	now := time.Now()
+	if at.Before(now) {
+		at = now
+	}
+
+	c.next = at.Add(c.conf.Throttle)
+
+	c.mu.Unlock()
+
+	time.Sleep(time.Until(at))
+}
+
+// fail records that the change could not be committed.
+func (c *committer) fail(i int, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.errIndices = append(c.errIndices, i)
+	c.fileChanges[i].Error = err
+
+	report.Debug(
+		c.conf,
+		"failed to rename '%s' (%v)",
+		c.fileChanges[i].SourcePath,
+		err,
+	)
+}
+
+// record adds the steps to the ones taken so far.
+func (c *committer) record(steps ...step) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.steps = append(c.steps, steps...)
+}
+
+// failed reports whether a change could not be committed.
+func (c *committer) failed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-		// skip paths that are unchanged in every aspect
-		if ch.SourcePath == targetPath {
+	return len(c.errIndices) > 0
+}
+
+// independent reports which changes can be committed concurrently with the
+// others. These are the files that are not part of a rename cycle or a chain
+// in which one change frees the target of another, and whose name is not
+// only changing case. Directories are excluded since the changes to the paths
+// under them must be committed first.
+func (c *committer) independent() map[int]bool {
+	sources := make(map[string]bool, len(c.fileChanges))
+	targets := make(map[string]bool, len(c.fileChanges))
+
+	// Paths are compared in lowercase in case the filesystem is case
+	// insensitive
+	for _, ch := range c.fileChanges {
+		sources[strings.ToLower(ch.SourcePath)] = true
+		targets[strings.ToLower(ch.TargetPath)] = true
+	}
+
+	independent := make(map[int]bool)
+
+	for i, ch := range c.fileChanges {
+		source := strings.ToLower(ch.SourcePath)
+		target := strings.ToLower(ch.TargetPath)
+
+		if _, ok := c.tempPaths[i]; ok || ch.IsDir || source == target ||
+			targets[source] || sources[target] {
 			continue
 		}
 
-		// Workaround for case insensitive filesystems where renaming a filename to
-		// its upper or lowercase equivalent doesn't work. Fixing this involves the
-		// following steps:
-		// 1. Prefix and suffix <target> with __<time>__
-		// 2. Rename <source> to <target>
-		// 3. Rename __<time>__<target>__<time>__ to <target>
-		var isCaseChangeOnly bool // only the target case is changing
-		if strings.EqualFold(ch.SourcePath, targetPath) {
-			isCaseChangeOnly = true
-			timeStr := fmt.Sprintf("%d", time.Now().UnixNano())
-			targetPath = filepath.Join(
-				ch.TargetDir,
-				"__"+timeStr+"__"+ch.Target+"__"+timeStr+"__", // step 1
-			)
-		}
-
-		// If target contains a slash, create all missing
-		// directories before renaming the file
-		if strings.Contains(ch.Target, "/") ||
-			strings.Contains(ch.Target, `\`) &&
-				runtime.GOOS == osutil.Windows {
-			// No need to check if the `dir` exists or if there are several
-			// consecutive slashes since `os.MkdirAll` handles that
-			dir := filepath.Dir(ch.Target)
-
-			err := os.MkdirAll(
-				filepath.Join(ch.TargetDir, dir),
-				osutil.DirPermission,
-			)
+		independent[i] = true
+	}
+
+	return independent
+}
+
+// commitBatch commits the changes concurrently using the configured number
+// of workers. The changes that have not started when a change fails are
+// marked as ignored if renaming stops at the first failure.
+func (c *committer) commitBatch(batch []int) {
+	indices := make(chan int)
+
+	var wg sync.WaitGroup
+
+	for range min(c.conf.Workers, len(batch)) {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := range indices {
+				if c.conf.AbortOnError && c.failed() {
+					c.fileChanges[i].Status = status.Ignored
+					continue
+				}
+
+				c.commitChange(i)
+				c.progress.add()
+			}
+		}()
+	}
+
+	for _, i := range batch {
+		indices <- i
+	}
+
+	close(indices)
+
+	wg.Wait()
+}
+
+// commitChange renames, copies, or links the source of the change to its
+// target, or deletes the source if the change removes it.
+func (c *committer) commitChange(i int) {
+	ch := c.fileChanges[i]
+	operation := c.conf.Operation
+
+	if ch.Status == status.Ignored {
+		return
+	}
+
+	sourcePath, targetPath := ch.SourcePath, ch.TargetPath
+
+	// skip paths that are unchanged in every aspect
+	if sourcePath == targetPath {
+		return
+	}
+
+	// A file that is routed through a temporary path was confirmed before it
+	// was moved there
+	if !c.movedToTemp[i] && !c.confirm.confirm(c.conf, ch) {
+		ch.Status = status.Ignored
+		report.Debug(c.conf, "skipping '%s' (not confirmed)", sourcePath)
+
+		return
+	}
+
+	c.wait()
+
+	// The source may have been removed, renamed, or modified since it was
+	// matched
+	if !c.movedToTemp[i] {
+		info, err := os.Lstat(sourcePath)
+		if errors.Is(err, os.ErrNotExist) {
+			ch.Status = status.SourceNotFound
+			c.fail(i, err)
+
+			return
+		}
+
+		if err == nil && !c.conf.AllowModified && ch.SourceModified(info) {
+			ch.Status = status.TargetFileChanging
+			c.fail(i, fmt.Errorf("%s: %w", sourcePath, errSourceModified))
+
+			return
+		}
+	}
+
+	if ch.Remove {
+		err := os.Remove(sourcePath)
+		if err != nil {
+			c.fail(i, err)
+		} else {
+			report.Debug(c.conf, "removed '%s'", sourcePath)
+			restoreOverwritten(c.conf, ch)
+		}
+
+		return
+	}
+
+	// Changes in a rename cycle are routed through a temporary path
+	if tmp, ok := c.tempPaths[i]; ok {
+		if ch.Error != nil {
+			return // moving to the temporary path failed
+		}
+
+		if !c.movedToTemp[i] {
+			c.movedToTemp[i] = true
+
+			err := move(c.conf, sourcePath, tmp)
 			if err != nil {
-				errIndices = append(errIndices, i)
-				ch.Error = err
+				c.fail(i, err)
+			} else {
+				c.inTemp++
 
-				continue
+				c.record(step{index: i, from: sourcePath, to: tmp})
+				report.Debug(c.conf, "moved '%s' to '%s'", sourcePath, tmp)
 			}
+
+			return
 		}
 
-		traversedDirs[ch.BaseDir] = ch.BaseDir
+		c.inTemp--
+		sourcePath = tmp
 
-		err := os.Rename(ch.SourcePath, targetPath) // step 2
-		// if the intermediate rename is successful,
-		// proceed with the original renaming operation
-		if err == nil && isCaseChangeOnly {
-			err = os.Rename(targetPath, ch.TargetPath) // step 3
+		defer func() {
+			if ch.Error != nil {
+				c.unstage(i, tmp)
+			}
+		}()
+	}
+
+	// Workaround for case insensitive filesystems where renaming a filename to
+	// its upper or lowercase equivalent doesn't work. Fixing this involves the
+	// following steps:
+	// 1. Prefix and suffix <target> with __<time>__
+	// 2. Rename <source> to <target>
+	// 3. Rename __<time>__<target>__<time>__ to <target>
+	var isCaseChangeOnly bool // only the target case is changing
+	if strings.EqualFold(sourcePath, targetPath) &&
+		!config.KeepsSources(operation) {
+		isCaseChangeOnly = true
+		timeStr := fmt.Sprintf("%d", time.Now().UnixNano())
+		targetPath = filepath.Join(
+			ch.TargetDir,
+			"__"+timeStr+"__"+ch.Target+"__"+timeStr+"__", // step 1
+		)
+	}
+
+	// The target of a change is freed by another change that is committed
+	// first, so it must not be overwritten if that change failed
+	if !isCaseChangeOnly && !ch.WillOverwrite {
+		if _, err := os.Lstat(targetPath); err == nil {
+			ch.Status = status.PathExists
+			c.fail(i, fmt.Errorf("%s: %w", targetPath, errTargetExists))
+
+			return
+		}
+	}
+
+	// If the target is in a subdirectory, create all missing directories
+	// before renaming the file. Forward slashes separate directories on every
+	// OS, while backslashes also do so on Windows.
+	targetSubdir := filepath.Dir(filepath.FromSlash(ch.Target))
+	if targetSubdir != "." {
+		// No need to check if the `dir` exists or if there are several
+		// consecutive slashes since `os.MkdirAll` handles that
+		dir := filepath.Join(ch.TargetDir, targetSubdir)
+
+		// The directories are created by one change at a time so that each
+		// one is recorded once
+		c.mu.Lock()
+
+		created := missingDirs(dir)
+
+		err := os.MkdirAll(dir, osutil.DirPermission)
+		if err == nil {
+			for _, d := range created {
+				c.steps = append(c.steps, step{index: -1, to: d, created: true})
+			}
 		}
 
+		c.mu.Unlock()
+
 		if err != nil {
-			errIndices = append(errIndices, i)
-			ch.Error = err
+			c.fail(i, err)
+			return
 		}
 	}
 
-	return errIndices
+	// The file that is overwritten is kept in the trash or as a backup so
+	// that it is put back when the operation is undone. It is recorded along
+	// with the change so that rolling back restores it afterwards.
+	var steps []step
+
+	if ch.WillOverwrite && !isCaseChangeOnly {
+		kept, err := c.keepOverwritten(i, targetPath)
+		if err != nil {
+			c.fail(i, err)
+			return
+		}
+
+		steps = append(steps, kept...)
+	}
+
+	if config.KeepsSources(operation) {
+		err := withRetries(c.conf, sourcePath, func() error {
+			return copyOrLink(c.conf, sourcePath, targetPath)
+		})
+		if err != nil {
+			c.fail(i, err)
+			putBack(c.conf, ch)
+		} else {
+			c.record(append(steps, step{index: i, to: targetPath, created: true})...)
+			report.Debug(c.conf, "created '%s' from '%s'", targetPath, sourcePath)
+		}
+
+		return
+	}
+
+	c.mu.Lock()
+	traversedDirs[ch.BaseDir] = ch.BaseDir
+	c.mu.Unlock()
+
+	err := move(c.conf, sourcePath, targetPath) // step 2
+	if err == nil {
+		c.record(append(steps, step{index: i, from: sourcePath, to: targetPath})...)
+	} else {
+		putBack(c.conf, ch)
+	}
+
+	// if the intermediate rename is successful,
+	// proceed with the original renaming operation
+	if err == nil && isCaseChangeOnly {
+		err = move(c.conf, targetPath, ch.TargetPath) // step 3
+		if err == nil {
+			c.record(step{index: i, from: targetPath, to: ch.TargetPath})
+		}
+	}
+
+	if err != nil {
+		c.fail(i, err)
+		return
+	}
+
+	report.Debug(c.conf, "renamed '%s' to '%s'", sourcePath, ch.TargetPath)
+
+	restoreOverwritten(c.conf, ch)
+}
+
+// unstage moves the file of a change that could not be moved from its
+// temporary path to its target back to its source, unless another file has
+// taken the source in the meantime.
+func (c *committer) unstage(i int, tmp string) {
+	source := c.fileChanges[i].SourcePath
+
+	if _, err := os.Lstat(source); err == nil {
+		return
+	}
+
+	if move(c.conf, tmp, source) == nil {
+		c.record(step{index: i, from: tmp, to: source})
+	}
+}
+
+// keepOverwritten moves the file at the target of a change out of the way
+// before it is overwritten, either to the trash (--trash) or to a backup path
+// (--backup), and returns the step that puts it back when rolling back. The
+// backups are named one at a time so that numbered backups do not clash.
+func (c *committer) keepOverwritten(i int, targetPath string) ([]step, error) {
+	ch := c.fileChanges[i]
+
+	if !c.conf.Trash && c.conf.BackupMode == "" {
+		return nil, nil
+	}
+
+	if _, err := os.Lstat(targetPath); err != nil {
+		return nil, nil //nolint:nilerr // the target was freed by another change
+	}
+
+	if c.conf.Trash {
+		trashed, err := trash.Move(targetPath)
+		if err != nil {
+			return nil, err
+		}
+
+		ch.Trashed = trashed
+
+		return []step{
+			{index: i, from: targetPath, to: trashed, trashed: true},
+		}, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	backup, err := backupPath(c.conf, targetPath)
+	if err != nil {
+		return nil, err
+	}
+
+	err = move(c.conf, targetPath, backup)
+	if err != nil {
+		return nil, err
+	}
+
+	ch.Backup = backup
+
+	return []step{{index: i, from: targetPath, to: backup}}, nil
+}
+
+// backupPath returns the path that the file at the path is renamed to before
+// it is overwritten (--backup). Backups are kept next to the file unless a
+// backup directory is set (--backup-dir). Simple backups add a suffix to the
+// name and replace an earlier backup, while numbered backups are named
+// <name>.~<n>~ after the highest existing number like GNU mv.
+func backupPath(conf *config.Config, path string) (string, error) {
+	dir := filepath.Dir(path)
+
+	if conf.BackupDir != "" {
+		dir = conf.BackupDir
+
+		err := os.MkdirAll(dir, osutil.DirPermission)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	name := filepath.Base(path)
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+
+	var highest int
+
+	for _, entry := range entries {
+		n, ok := strings.CutPrefix(entry.Name(), name+".~")
+		if !ok {
+			continue
+		}
+
+		n, ok = strings.CutSuffix(n, "~")
+		if !ok {
+			continue
+		}
+
+		if num, err := strconv.Atoi(n); err == nil && num > highest {
+			highest = num
+		}
+	}
+
+	if conf.BackupMode == config.BackupNumbered ||
+		(conf.BackupMode == config.BackupExisting && highest > 0) {
+		return filepath.Join(dir, fmt.Sprintf("%s.~%d~", name, highest+1)), nil
+	}
+
+	return filepath.Join(dir, name+conf.BackupSuffix), nil
+}
+
+// restoreKept moves a file that was overwritten by a change back to its path
+// from the trash or its backup path. An existing file at the path is never
+// replaced.
+func restoreKept(conf *config.Config, kept file.Kept, path string) error {
+	if kept.Trashed {
+		return trash.Restore(kept.Path, path)
+	}
+
+	if _, err := os.Lstat(path); err == nil {
+		return fmt.Errorf("%s: %w", path, errTargetExists)
+	}
+
+	return move(conf, kept.Path, path)
+}
+
+// putBack restores the file that was moved out of the way to make way for the
+// target of a change when the change fails.
+func putBack(conf *config.Config, ch *file.Change) {
+	kept := file.Kept{Path: ch.Backup}
+	if ch.Trashed != "" {
+		kept = file.Kept{Path: ch.Trashed, Trashed: true}
+	}
+
+	if kept.Path == "" {
+		return
+	}
+
+	err := restoreKept(conf, kept, ch.TargetPath)
+	if err != nil {
+		report.RestoreFailed(kept.Path, err)
+	}
+
+	ch.Trashed, ch.Backup = "", ""
+}
+
+// restoreOverwritten restores the files that were overwritten by a change
+// from the trash or their backups once the change is undone. A file that
+// cannot be restored is reported and left where it was kept.
+func restoreOverwritten(conf *config.Config, ch *file.Change) {
+	for path, kept := range ch.Restore {
+		err := restoreKept(conf, kept, path)
+		if err != nil {
+			report.RestoreFailed(kept.Path, err)
+		}
+	}
+}
+
+// commit iterates over all the matches and renames, copies, or links them on
+// the filesystem according to the operation. The copies and links made by an
+// operation that is undone are deleted. Directories are auto-created if
+// necessary, and errors are aggregated. If --on-error=abort is set, renaming
+// stops at the first error and the remaining changes are marked as ignored. A
+// rename cycle that is in progress is always completed so that no file is left
+// at its temporary path. Files that were modified after they were matched are
+// skipped unless --allow-modified is set. With --on-error=rollback, the
+// changes that were committed before the failure are undone. With
+// --interactive, the changes that are not confirmed are marked as ignored.
+//
+// With more than one worker (--workers), consecutive changes that do not
+// depend on each other are committed concurrently, while the rest are
+// committed one at a time so that the order of the changes is kept.
+func commit(conf *config.Config, fileChanges file.Changes) []int {
+	order, tempPaths := commitOrder(fileChanges, conf.Operation, conf.TwoPhase)
+
+	c := &committer{
+		conf:        conf,
+		fileChanges: fileChanges,
+		tempPaths:   tempPaths,
+		movedToTemp: make(map[int]bool),
+		progress:    startProgress(conf, len(order)),
+		confirm:     newConfirmer(conf),
+	}
+
+	var independent map[int]bool
+
+	// git cannot update the index from several processes at the same time,
+	// and the changes are confirmed one at a time
+	if conf.Workers > 1 && !conf.Git && !conf.Interactive {
+		independent = c.independent()
+	}
+
+	var batch []int
+
+	flush := func() {
+		if len(batch) > 0 {
+			c.commitBatch(batch)
+			batch = nil
+		}
+	}
+
+	done := make(map[int]bool)
+
+	for _, i := range order {
+		if !independent[i] {
+			flush()
+		}
+
+		if conf.AbortOnError && len(c.errIndices) > 0 {
+			if c.inTemp == 0 {
+				break
+			}
+
+			// Only the files that are already staged are moved to their
+			// targets
+			if conf.TwoPhase && !fileChanges[i].IsDir && !c.movedToTemp[i] {
+				continue
+			}
+		}
+
+		done[i] = true
+
+		if independent[i] {
+			batch = append(batch, i)
+			continue
+		}
+
+		c.commitChange(i)
+		c.progress.add()
+	}
+
+	flush()
+
+	c.progress.finish()
+
+	for j := range fileChanges {
+		if !done[j] {
+			fileChanges[j].Status = status.Ignored
+		}
+	}
+
+	// Batches finish in any order
+	slices.Sort(c.errIndices)
+
+	if conf.RollbackOnError && len(c.errIndices) > 0 {
+		rollback(conf, fileChanges, c.steps)
+	}
+
+	return c.errIndices
 }
 
 // Rename renames files according to the provided changes and configuration
@@ -113,14 +976,168 @@ func Rename(
 		}
 	}
 
-	renameErrs := commit(fileChanges)
+	renameErrs := commit(conf, fileChanges)
 	if len(renameErrs) > 0 {
+		if conf.RollbackOnError {
+			return errRenameRolledBack.WithCtx(renameErrs)
+		}
+
+		if conf.AbortOnError {
+			return errRenameAborted.WithCtx(renameErrs)
+		}
+
 		return errRenameFailed.WithCtx(renameErrs)
 	}
 
+	// The files that were modified after they were renamed are skipped with
+	// an error before committing when undoing, which leaves the operation
+	// partly undone
+	if conf.Revert && slices.ContainsFunc(fileChanges, func(ch *file.Change) bool {
+		return ch.Error != nil
+	}) {
+		return errUndoIncomplete
+	}
+
 	return nil
 }
 
+// deepestFirst sorts the directories so that subdirectories come before the
+// directories that contain them.
+func deepestFirst(dirs []string) {
+	slices.SortFunc(dirs, func(a, b string) int {
+		depthA := strings.Count(a, string(filepath.Separator))
+		depthB := strings.Count(b, string(filepath.Separator))
+
+		if depthA != depthB {
+			return depthB - depthA
+		}
+
+		return strings.Compare(a, b)
+	})
+}
+
+// cleanParent returns the parent of the directory if it may be removed when
+// it is left empty, which is the case for the directories inside the searched
+// paths. The working directory is never removed.
+func cleanParent(conf *config.Config, dir string) (string, bool) {
+	parent := filepath.Dir(dir)
+	if parent == "." || parent == dir {
+		return "", false
+	}
+
+	for _, root := range conf.FilesAndDirPaths {
+		rel, err := filepath.Rel(filepath.Clean(root), parent)
+		if err == nil && rel != "." && rel != ".." &&
+			!strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return parent, true
+		}
+	}
+
+	return "", false
+}
+
+// cleanDirs removes the traversed directories that were left empty by the
+// renaming operation along with the parents that are left empty in turn
+// (--clean). It returns the removed directories.
+func cleanDirs(conf *config.Config) []string {
+	dirs := make([]string, 0, len(traversedDirs))
+	for dir := range traversedDirs {
+		dirs = append(dirs, dir)
+	}
+
+	deepestFirst(dirs)
+
+	var cleanedDirs []string
+
+	for _, dir := range dirs {
+		for ok := dir != "."; ok; dir, ok = cleanParent(conf, dir) {
+			// This will fail if the directory is not empty so no need
+			// to check before hand
+			if os.Remove(dir) != nil {
+				break
+			}
+
+			cleanedDirs = append(cleanedDirs, dir)
+		}
+	}
+
+	return cleanedDirs
+}
+
+// EmptyDirs returns the directories that will be removed after the changes
+// are committed because they are left empty (--clean). The contents of the
+// directories are checked against the changes so that the removals can be
+// shown before renaming.
+func EmptyDirs(conf *config.Config, fileChanges file.Changes) []string {
+	if config.KeepsSources(conf.Operation) {
+		return nil
+	}
+
+	movedOut := make(map[string]bool)
+	occupied := make(map[string]bool)
+
+	var dirs []string
+
+	for _, ch := range fileChanges {
+		source := filepath.Clean(ch.SourcePath)
+		target := filepath.Clean(ch.TargetPath)
+
+		if ch.Status == status.Ignored || source == target {
+			continue
+		}
+
+		movedOut[source] = true
+
+		// the directories that the target is moved into are not empty
+		for dir := filepath.Dir(target); !occupied[dir]; dir = filepath.Dir(dir) {
+			occupied[dir] = true
+		}
+
+		dir := filepath.Clean(ch.BaseDir)
+		if !slices.Contains(dirs, dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+
+	deepestFirst(dirs)
+
+	emptied := make(map[string]bool)
+
+	var emptyDirs []string
+
+	for _, dir := range dirs {
+		for ok := dir != "."; ok; dir, ok = cleanParent(conf, dir) {
+			if occupied[dir] || emptied[dir] {
+				break
+			}
+
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				break
+			}
+
+			isEmpty := true
+
+			for _, entry := range entries {
+				path := filepath.Join(dir, entry.Name())
+				if !movedOut[path] && !emptied[path] {
+					isEmpty = false
+					break
+				}
+			}
+
+			if !isEmpty {
+				break
+			}
+
+			emptied[dir] = true
+			emptyDirs = append(emptyDirs, dir)
+		}
+	}
+
+	return emptyDirs
+}
+
 // PostRename handles actions after a renaming operation, such as printing
 // results, cleaning empty directories, and creating a backup file if applicable.
 func PostRename(
@@ -133,43 +1150,73 @@ func PostRename(
 	var cleanedDirs []string
 
 	if conf.Clean && !conf.Revert {
-		for _, dir := range traversedDirs {
-			if dir == "." { // don't try to clean the working directory
-				continue
-			}
+		cleanedDirs = cleanDirs(conf)
+	}
 
-			// This will fail if the directory is not empty so no need
-			// to check before hand
-			err := os.Remove(dir)
-			if err == nil {
-				cleanedDirs = append(cleanedDirs, dir)
-			}
+	if len(fileChanges) != 0 && !conf.Revert && !conf.Redo {
+		err := backupChanges(conf, fileChanges, cleanedDirs)
+
+		// The undone operations cannot be redone after a new operation
+		if err == nil && conf.BackupLocation == nil {
+			err = config.ClearUndone(conf.WorkingDir)
+		}
+
+		if err == nil && conf.BackupLocation == nil {
+			_, err = config.PruneHistory(conf)
 		}
-	}
 
-	if len(fileChanges) != 0 && !conf.Revert {
-		err := backupChanges(
-			fileChanges,
-			cleanedDirs,
-			conf.BackupFilename,
-			conf.BackupLocation,
-		)
 		if err != nil {
 			report.BackupFailed(err)
 		}
 	}
 
-	if conf.Revert && renameErr == nil {
-		backupFilePath := filepath.Join(
-			os.TempDir(),
-			"f2",
-			"backups",
-			conf.BackupFilename,
-		)
-
-		if err := os.Remove(backupFilePath); err != nil {
+	if renameErr == nil && (conf.Revert || conf.Redo) {
+		err := updateHistory(conf, fileChanges)
+		if err != nil {
 			report.BackupFileRemovalFailed(err)
-			return
 		}
 	}
 }
+
+// updateHistory records that the operations were undone so that the next undo
+// reverts the operation before them, or that the last undone operation was
+// redone so that it can be undone again. An operation that was partly undone
+// because some files were skipped stays in the history so that those files
+// can still be restored.
+func updateHistory(conf *config.Config, fileChanges file.Changes) error {
+	if conf.Revert {
+		if slices.ContainsFunc(fileChanges, func(ch *file.Change) bool {
+			return ch.Status == status.Ignored
+		}) {
+			return nil
+		}
+
+		backupFilePaths, err := config.BackupsToUndo(conf)
+		if err != nil {
+			return err
+		}
+
+		if conf.AutoFixConflicts {
+			err = recordFixedTargets(conf, backupFilePaths, fileChanges)
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, backupFilePath := range backupFilePaths {
+			err = config.MarkUndone(conf.WorkingDir, backupFilePath)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	backupFilePath, err := config.LastUndoneBackup(conf.WorkingDir)
+	if err != nil || backupFilePath == "" {
+		return err
+	}
+
+	return config.MarkRedone(backupFilePath)
+}
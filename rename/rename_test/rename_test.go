@@ -4,10 +4,12 @@ import (
 	"bytes"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/ayoisaiah/f2/v2/internal/config"
 	"github.com/ayoisaiah/f2/v2/internal/file"
+	"github.com/ayoisaiah/f2/v2/internal/status"
 	"github.com/ayoisaiah/f2/v2/internal/testutil"
 	"github.com/ayoisaiah/f2/v2/rename"
 )
@@ -47,12 +49,9 @@ func renameTest(t *testing.T, cases []testutil.TestCase) {
 				ch.Target,
 			)
 
-			f, err := os.Create(ch.Source)
-			if err != nil {
-				t.Fatal(err)
-			}
-
-			err = f.Close()
+			// Each file contains its original name so that the renamed files
+			// can be told apart
+			err := os.WriteFile(ch.Source, []byte(ch.Source), 0o600)
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -67,9 +66,19 @@ func renameTest(t *testing.T, cases []testutil.TestCase) {
 			for j := range tc.Changes {
 				ch := tc.Changes[j]
 
-				if _, err := os.Stat(ch.TargetPath); err != nil {
+				content, err := os.ReadFile(ch.TargetPath)
+				if err != nil {
 					t.Fatal(err)
 				}
+
+				if string(content) != ch.Source {
+					t.Fatalf(
+						"expected %s to be renamed to %s, but got %s",
+						ch.Source,
+						ch.TargetPath,
+						content,
+					)
+				}
 			}
 		})
 
@@ -138,6 +147,68 @@ func TestRename(t *testing.T) {
 			},
 			Args: []string{"-f", "", "--target-dir", "one/two"},
 		},
+		{
+			Name: "rename into new directories under the target directory",
+			Changes: file.Changes{
+				{
+					Source:    "File.txt",
+					Target:    "2024/05/myFile.txt",
+					TargetDir: "one/two",
+				},
+			},
+			Args: []string{"-f", "", "--target-dir", "one/two"},
+		},
+		{
+			Name: "swap file names",
+			Changes: file.Changes{
+				{
+					Source: "a.txt",
+					Target: "b.txt",
+				},
+				{
+					Source: "b.txt",
+					Target: "a.txt",
+				},
+			},
+		},
+		{
+			Name: "rotate file names",
+			Changes: file.Changes{
+				{
+					Source: "2.txt",
+					Target: "3.txt",
+				},
+				{
+					Source: "other.txt",
+					Target: "other_file.txt",
+				},
+				{
+					Source: "1.txt",
+					Target: "2.txt",
+				},
+				{
+					Source: "3.txt",
+					Target: "1.txt",
+				},
+			},
+		},
+		{
+			Name: "shift file names",
+			Changes: file.Changes{
+				{
+					Source: "1.txt",
+					Target: "2.txt",
+				},
+				{
+					Source: "2.txt",
+					Target: "3.txt",
+				},
+				{
+					Source: "3.txt",
+					Target: "4.txt",
+				},
+			},
+		},
 	}
 
 	renameTest(t, testCases)
@@ -161,6 +232,8 @@ func postRename(t *testing.T, cases []testutil.TestCase) {
 			conf := testutil.GetConfig(t, &tc, ".")
 
 			conf.BackupLocation = &backup
+			// keep the recorded working directory stable across machines
+			conf.WorkingDir = "."
 
 			rename.PostRename(conf, tc.Changes, tc.Error)
 
@@ -186,7 +259,277 @@ func TestPostRename(t *testing.T) {
 			StderrGoldenFile: "rename_a_file_backup_stderr",
 			Args:             []string{"-r", "", "-V"},
 		},
+		{
+			Name: "record overwritten files in the backup",
+			Changes: file.Changes{
+				{
+					Source:        "File.txt",
+					Target:        "existing.txt",
+					WillOverwrite: true,
+				},
+			},
+			StdoutGoldenFile: "record_overwritten_files_backup",
+			Args:             []string{"-r", "", "--allow-overwrites"},
+		},
+		{
+			Name: "leave unchanged files out of the backup",
+			Changes: file.Changes{
+				{
+					Source: "File.txt",
+					Target: "myFile.txt",
+				},
+				{
+					Source: "notes.txt",
+					Target: "notes.txt",
+					Status: status.Unchanged,
+				},
+			},
+			StdoutGoldenFile: "leave_unchanged_files_out_of_the_backup",
+			Args:             []string{"-r", ""},
+		},
 	}
 
 	postRename(t, testCases)
 }
+
+// removeSource returns a setup function that deletes the source of a change
+// after it was matched so that it is missing when renaming.
+func removeSource(source string) func(t *testing.T, testDir string) func() {
+	return func(t *testing.T, testDir string) func() {
+		t.Helper()
+
+		err := os.Remove(filepath.Join(testDir, source))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return func() {}
+	}
+}
+
+// modifySource returns a setup function that appends to the source of a
+// change after it was matched so that its size differs when renaming.
+func modifySource(source string) func(t *testing.T, testDir string) func() {
+	return func(t *testing.T, testDir string) func() {
+		t.Helper()
+
+		f, err := os.OpenFile(
+			filepath.Join(testDir, source),
+			os.O_APPEND|os.O_WRONLY,
+			0o600,
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		defer f.Close()
+
+		_, err = f.WriteString("more data")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return func() {}
+	}
+}
+
+// renameChangedSources renames the changes in each test case after its setup
+// function alters the second source. It checks that the second change has
+// the expected status and that the wanted paths exist afterwards.
+func renameChangedSources(
+	t *testing.T,
+	cases []testutil.TestCase,
+	wantStatus []status.Status,
+) {
+	t.Helper()
+
+	for i := range cases {
+		tc := cases[i]
+
+		t.Run(tc.Name, func(t *testing.T) {
+			testDir := t.TempDir()
+
+			for j := range tc.Changes {
+				ch := tc.Changes[j]
+				ch.BaseDir, ch.TargetDir = testDir, testDir
+				ch.SourcePath = filepath.Join(testDir, ch.Source)
+				ch.TargetPath = filepath.Join(testDir, ch.Target)
+				ch.Status = status.OK
+
+				err := os.WriteFile(ch.SourcePath, []byte(ch.Source), 0o600)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				info, err := os.Stat(ch.SourcePath)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				ch.Size, ch.ModTime = info.Size(), info.ModTime()
+			}
+
+			tc.SetupFunc(t, testDir)
+
+			conf := testutil.GetConfig(t, &tc, testDir)
+
+			err := rename.Rename(conf, tc.Changes)
+			if (err != nil) != (wantStatus[i] != status.OK) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tc.Changes[1].Status != wantStatus[i] {
+				t.Fatalf(
+					"expected status '%s', but got '%s'",
+					wantStatus[i],
+					tc.Changes[1].Status,
+				)
+			}
+
+			for _, want := range tc.Want {
+				_, err := os.Stat(filepath.Join(testDir, want))
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestRenameMissingSource(t *testing.T) {
+	testCases := []testutil.TestCase{
+		{
+			Name: "continue renaming after a missing source",
+			Changes: file.Changes{
+				{Source: "a.txt", Target: "a1.txt"},
+				{Source: "b.txt", Target: "b1.txt"},
+				{Source: "c.txt", Target: "c1.txt"},
+			},
+			Want:      []string{"a1.txt", "c1.txt"},
+			SetupFunc: removeSource("b.txt"),
+		},
+		{
+			Name: "abort renaming at a missing source",
+			Changes: file.Changes{
+				{Source: "a.txt", Target: "a1.txt"},
+				{Source: "b.txt", Target: "b1.txt"},
+				{Source: "c.txt", Target: "c1.txt"},
+			},
+			Want:      []string{"a1.txt", "c.txt"},
+			Args:      []string{"-f", "", "-r", "", "--on-error", "abort"},
+			SetupFunc: removeSource("b.txt"),
+		},
+	}
+
+	renameChangedSources(t, testCases, []status.Status{
+		status.SourceNotFound,
+		status.SourceNotFound,
+	})
+}
+
+func TestRenameModifiedSource(t *testing.T) {
+	testCases := []testutil.TestCase{
+		{
+			Name: "skip a source modified after matching",
+			Changes: file.Changes{
+				{Source: "a.txt", Target: "a1.txt"},
+				{Source: "b.txt", Target: "b1.txt"},
+				{Source: "c.txt", Target: "c1.txt"},
+			},
+			Want:      []string{"a1.txt", "b.txt", "c1.txt"},
+			SetupFunc: modifySource("b.txt"),
+		},
+		{
+			Name: "rename a modified source with --allow-modified",
+			Changes: file.Changes{
+				{Source: "a.txt", Target: "a1.txt"},
+				{Source: "b.txt", Target: "b1.txt"},
+				{Source: "c.txt", Target: "c1.txt"},
+			},
+			Want:      []string{"a1.txt", "b1.txt", "c1.txt"},
+			Args:      []string{"-f", "", "-r", "", "--allow-modified"},
+			SetupFunc: modifySource("b.txt"),
+		},
+		{
+			Name: "keep a target whose file was not renamed away",
+			Changes: file.Changes{
+				{Source: "a.txt", Target: "b.txt"},
+				{Source: "b.txt", Target: "c.txt"},
+			},
+			Want:      []string{"a.txt", "b.txt"},
+			SetupFunc: modifySource("b.txt"),
+		},
+	}
+
+	renameChangedSources(t, testCases, []status.Status{
+		status.TargetFileChanging,
+		status.OK,
+		status.TargetFileChanging,
+	})
+}
+
+func TestVerify(t *testing.T) {
+	testDir := t.TempDir()
+
+	changes := file.Changes{
+		{Source: "a.txt", Target: "a1.txt"},
+		{Source: "b.txt", Target: "b1.txt"},
+		{Source: "c.txt", Target: "c1.txt"},
+	}
+
+	for _, ch := range changes {
+		ch.BaseDir, ch.TargetDir = testDir, testDir
+		ch.SourcePath = filepath.Join(testDir, ch.Source)
+		ch.TargetPath = filepath.Join(testDir, ch.Target)
+		ch.Status = status.OK
+
+		err := os.WriteFile(ch.SourcePath, []byte(ch.Source), 0o600)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tc := testutil.TestCase{
+		Args: []string{"-f", "", "-r", "", "--verify"},
+	}
+
+	conf := testutil.GetConfig(t, &tc, testDir)
+
+	config.Stderr = &bytes.Buffer{}
+
+	err := rename.Rename(conf, changes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = rename.Verify(conf, changes); err != nil {
+		t.Fatalf("expected the renamed files to pass verification, got %v", err)
+	}
+
+	// Simulate a filesystem that lost a rename and one that kept the source
+	err = os.Remove(changes[1].TargetPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = os.WriteFile(changes[2].SourcePath, nil, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var stderr bytes.Buffer
+
+	config.Stderr = &stderr
+
+	if err = rename.Verify(conf, changes); err == nil {
+		t.Fatal("expected the verification to fail")
+	}
+
+	output := stderr.String()
+
+	if !strings.Contains(output, "2 file(s) were not found as expected") ||
+		!strings.Contains(output, "the target does not exist") ||
+		!strings.Contains(output, "the source still exists") {
+		t.Fatalf("expected the problems to be reported, got %q", output)
+	}
+}
@@ -21,6 +21,17 @@ func TestRenameWindows(t *testing.T) {
 				},
 			},
 		},
+		{
+			Name: "rename with new directories (mixed separators)",
+			Changes: file.Changes{
+				{
+					Source:    "File.txt",
+					Target:    `2024/05\myFile.txt`,
+					TargetDir: "one/two",
+				},
+			},
+			Args: []string{"-f", "", "--target-dir", "one/two"},
+		},
 	}
 
 	renameTest(t, testCases)
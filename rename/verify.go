@@ -0,0 +1,187 @@
+package rename
+
+import (
+	"errors"
+	"os"
+	"strings"
+
+	"github.com/ayoisaiah/f2/v2/internal/apperr"
+	"github.com/ayoisaiah/f2/v2/internal/config"
+	"github.com/ayoisaiah/f2/v2/internal/file"
+	"github.com/ayoisaiah/f2/v2/internal/osutil"
+	"github.com/ayoisaiah/f2/v2/internal/status"
+	"github.com/ayoisaiah/f2/v2/report"
+)
+
+var (
+	errVerifyFailed = &apperr.Error{
+		Message:  "some files were not found as expected after renaming",
+		ExitCode: int(osutil.ExitPartialFailure),
+	}
+
+	errTargetMissing = errors.New("the target does not exist")
+
+	errSourceRemains = errors.New("the source still exists")
+
+	errSizeMismatch = errors.New("the size of the copy differs from the source")
+
+	errChecksumMismatch = errors.New(
+		"the contents of the copy differ from the source",
+	)
+
+	errNotLinked = errors.New("the target is not a link to the source")
+)
+
+// verifyCopy checks that the copy at the target has the same size as the
+// source, and the same contents if checksums are compared.
+func verifyCopy(conf *config.Config, ch *file.Change) error {
+	sourceInfo, err := os.Lstat(ch.SourcePath)
+	if err != nil {
+		return err
+	}
+
+	targetInfo, err := os.Lstat(ch.TargetPath)
+	if err != nil {
+		return err
+	}
+
+	if !sourceInfo.Mode().IsRegular() {
+		return nil
+	}
+
+	if sourceInfo.Size() != targetInfo.Size() {
+		return errSizeMismatch
+	}
+
+	if !conf.VerifyChecksum {
+		return nil
+	}
+
+	sourceSum, err := osutil.Checksum(ch.SourcePath)
+	if err != nil {
+		return err
+	}
+
+	targetSum, err := osutil.Checksum(ch.TargetPath)
+	if err != nil {
+		return err
+	}
+
+	if sourceSum != targetSum {
+		return errChecksumMismatch
+	}
+
+	return nil
+}
+
+// verifyLink checks that the link at the target refers to the source.
+func verifyLink(conf *config.Config, ch *file.Change) error {
+	targetInfo, err := os.Lstat(ch.TargetPath)
+	if err != nil {
+		return err
+	}
+
+	sourceInfo, err := os.Lstat(ch.SourcePath)
+	if err != nil {
+		return err
+	}
+
+	if conf.Operation == config.OperationSymlink {
+		if targetInfo.Mode()&os.ModeSymlink == 0 {
+			return errNotLinked
+		}
+
+		targetInfo, err = os.Stat(ch.TargetPath)
+		if err != nil {
+			return errNotLinked
+		}
+	}
+
+	if !os.SameFile(sourceInfo, targetInfo) {
+		return errNotLinked
+	}
+
+	return nil
+}
+
+// verifyChange returns an error if the outcome of the change is not found on
+// the filesystem. The targets are the paths that the changes moved files to,
+// which are not expected to be free after renaming.
+func verifyChange(
+	conf *config.Config,
+	ch *file.Change,
+	targets map[string]bool,
+) error {
+	if ch.Remove {
+		if _, err := os.Lstat(ch.SourcePath); err == nil {
+			return errSourceRemains
+		}
+
+		return nil
+	}
+
+	if _, err := os.Lstat(ch.TargetPath); err != nil {
+		return errTargetMissing
+	}
+
+	switch conf.Operation {
+	case config.OperationCopy:
+		return verifyCopy(conf, ch)
+	case config.OperationSymlink, config.OperationHardlink:
+		return verifyLink(conf, ch)
+	}
+
+	// The source refers to the target itself after a change of case on a
+	// case-insensitive filesystem
+	if targets[ch.SourcePath] || strings.EqualFold(ch.SourcePath, ch.TargetPath) {
+		return nil
+	}
+
+	if _, err := os.Lstat(ch.SourcePath); err == nil {
+		return errSourceRemains
+	}
+
+	return nil
+}
+
+// Verify checks that the committed changes are reflected on the filesystem
+// (--verify) to catch the failures that some filesystems do not report. The
+// target of each change must exist and its source must be gone after
+// renaming, while copies must match the size of the source (and its contents
+// with --verify-checksum) and links must refer to the source. The changes
+// that do not pass are reported and an error is returned.
+func Verify(conf *config.Config, fileChanges file.Changes) error {
+	targets := make(map[string]bool)
+
+	for _, ch := range fileChanges {
+		if ch.Error == nil && ch.Status != status.Ignored && !ch.Remove {
+			targets[ch.TargetPath] = true
+		}
+	}
+
+	var (
+		unverified file.Changes
+		errs       []error
+	)
+
+	for _, ch := range fileChanges {
+		if ch.Error != nil || ch.Status == status.Ignored ||
+			ch.SourcePath == ch.TargetPath {
+			continue
+		}
+
+		err := verifyChange(conf, ch, targets)
+		if err != nil {
+			unverified = append(unverified, ch)
+			errs = append(errs, err)
+		}
+	}
+
+	if len(unverified) == 0 {
+		return nil
+	}
+
+	report.Unverified(conf, unverified, errs)
+
+	return errVerifyFailed
+}
@@ -5,21 +5,246 @@ package replace
 
 import (
 	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 
+	"golang.org/x/text/unicode/norm"
+
 	"github.com/ayoisaiah/f2/v2/internal/config"
 	"github.com/ayoisaiah/f2/v2/internal/file"
+	"github.com/ayoisaiah/f2/v2/internal/filetype"
+	"github.com/ayoisaiah/f2/v2/internal/fuzzy"
 	"github.com/ayoisaiah/f2/v2/internal/pathutil"
 	"github.com/ayoisaiah/f2/v2/internal/sortfiles"
 	"github.com/ayoisaiah/f2/v2/internal/status"
 	"github.com/ayoisaiah/f2/v2/replace/variables"
+	"github.com/ayoisaiah/f2/v2/report"
+)
+
+var (
+	numberRegex     = regexp.MustCompile(`\d+`)
+	lastNumberRegex = regexp.MustCompile(`(\d+)(\D*)$`)
 )
 
+// padNumbers pads each number in the target file name with leading zeros
+// according to the --pad-num option. The file extension is not modified.
+func padNumbers(conf *config.Config, change *file.Change) {
+	stem, ext := change.Target, ""
+	if !change.IsDir {
+		ext = filepath.Ext(stem)
+		stem = strings.TrimSuffix(stem, ext)
+	}
+
+	stem = numberRegex.ReplaceAllStringFunc(stem, func(num string) string {
+		if len(num) >= conf.PadNum {
+			return num
+		}
+
+		return strings.Repeat("0", conf.PadNum-len(num)) + num
+	})
+
+	change.Target = stem + ext
+}
+
+// renumber rewrites the last number in the target name of each change so that
+// the numbers in each directory form a contiguous sequence starting from the
+// lowest existing number. The relative order of the numbers is preserved, and
+// zero padded numbers retain their width.
+func renumber(conf *config.Config, changes file.Changes) {
+	type numbered struct {
+		change *file.Change
+		stem   string
+		ext    string
+		width  int
+		num    int
+	}
+
+	groups := make(map[string][]numbered)
+
+	var dirs []string
+
+	for i := range changes {
+		change := changes[i]
+
+		if change.PrimaryPair != nil {
+			continue
+		}
+
+		stem, ext := change.Target, ""
+		if !change.IsDir {
+			ext = filepath.Ext(stem)
+			stem = strings.TrimSuffix(stem, ext)
+		}
+
+		submatch := lastNumberRegex.FindStringSubmatch(stem)
+		if submatch == nil {
+			continue
+		}
+
+		num, err := strconv.Atoi(submatch[1])
+		if err != nil {
+			continue
+		}
+
+		n := numbered{
+			change: change,
+			stem:   stem,
+			ext:    ext,
+			num:    num,
+		}
+
+		if strings.HasPrefix(submatch[1], "0") {
+			n.width = len(submatch[1])
+		}
+
+		if _, ok := groups[change.BaseDir]; !ok {
+			dirs = append(dirs, change.BaseDir)
+		}
+
+		groups[change.BaseDir] = append(groups[change.BaseDir], n)
+	}
+
+	for _, dir := range dirs {
+		group := groups[dir]
+
+		slices.SortStableFunc(group, func(a, b numbered) int {
+			return a.num - b.num
+		})
+
+		start := group[0].num
+
+		for i, n := range group {
+			loc := lastNumberRegex.FindStringSubmatchIndex(n.stem)
+			num := strconv.Itoa(start + i)
+
+			if len(num) < n.width {
+				num = strings.Repeat("0", n.width-len(num)) + num
+			}
+
+			n.change.Target = n.stem[:loc[2]] + num + n.stem[loc[3]:] + n.ext
+
+			if conf.PadNum > 0 {
+				padNumbers(conf, n.change)
+			}
+
+			n.change.TargetPath = filepath.Join(
+				n.change.TargetDir,
+				n.change.Target,
+			)
+		}
+	}
+
+	// Secondary files in a pair inherit the new name of their primary pair
+	for i := range changes {
+		change := changes[i]
+
+		if change.PrimaryPair == nil {
+			continue
+		}
+
+		common := pathutil.StripExtension(change.PrimaryPair.Target)
+		change.Target = common + pairExt(change)
+		change.TargetPath = filepath.Join(change.TargetDir, change.Target)
+	}
+}
+
+// pairExt returns the extension of a secondary file in a pair. For sidecar
+// files such as IMG_1.CR2.xmp, this includes every part of the name after the
+// name shared with the primary file (.CR2.xmp).
+func pairExt(change *file.Change) string {
+	stem := pathutil.StripExtension(change.PrimaryPair.Source)
+
+	if len(change.Source) > len(stem) &&
+		strings.HasPrefix(change.Source, stem) {
+		return change.Source[len(stem):]
+	}
+
+	return filepath.Ext(change.Source)
+}
+
+// fixExtensions replaces the extension in the target name of each file whose
+// signature does not match its current extension with the preferred
+// extension for the detected file type. Files whose type cannot be detected
+// are left unchanged.
+func fixExtensions(changes file.Changes) error {
+	for i := range changes {
+		change := changes[i]
+
+		if change.IsDir {
+			continue
+		}
+
+		typ, ok, err := filetype.Detect(change.SourcePath)
+		if err != nil {
+			return err
+		}
+
+		ext := filepath.Ext(change.Target)
+
+		if !ok || typ.HasExt(ext) {
+			continue
+		}
+
+		change.Target = strings.TrimSuffix(change.Target, ext) + typ.Ext()
+		change.TargetPath = filepath.Join(change.TargetDir, change.Target)
+	}
+
+	return nil
+}
+
+// normalForms maps the values of --normalize to the Unicode normalization
+// forms.
+var normalForms = map[string]norm.Form{
+	"nfc":  norm.NFC,
+	"nfd":  norm.NFD,
+	"nfkc": norm.NFKC,
+	"nfkd": norm.NFKD,
+}
+
+// normalizeTargets converts the target name of each file to the provided
+// Unicode normalization form.
+func normalizeTargets(changes file.Changes, form norm.Form) {
+	for i := range changes {
+		change := changes[i]
+
+		change.Target = form.String(change.Target)
+		change.TargetPath = filepath.Join(change.TargetDir, change.Target)
+	}
+}
+
 // replaceString replaces all matches in the filename
 // with the replacement string.
 func replaceString(conf *config.Config, originalName string) string {
+	re := conf.Search.Regex
+
+	// In fuzzy mode, the closest match to the find string is replaced
+	if conf.Search.Fuzzy != "" {
+		start, end, ok := fuzzy.Find(
+			conf.Search.Fuzzy,
+			originalName,
+			conf.FuzzyDistance,
+			conf.IgnoreCase,
+		)
+		if !ok {
+			return originalName
+		}
+
+		re = regexp.MustCompile(regexp.QuoteMeta(originalName[start:end]))
+	}
+
+	if conf.ReplaceNth != 0 {
+		return variables.RegexReplaceNth(
+			re,
+			originalName,
+			conf.Replacement,
+			conf.ReplaceNth,
+		)
+	}
+
 	return variables.RegexReplace(
-		conf.Search.Regex,
+		re,
 		originalName,
 		conf.Replacement,
 		conf.ReplaceLimit,
@@ -42,24 +267,57 @@ func applyReplacement(
 
 	change.Target = replaceString(conf, originalName)
 
+	replaced := change.Target
+
 	// Replace any variables present with their corresponding values
 	err := variables.Replace(conf, change, vars)
 	if err != nil {
 		return err
 	}
 
+	if change.Target != replaced {
+		report.Debug(
+			conf,
+			"resolved the variables in '%s' to '%s' for '%s'",
+			replaced,
+			change.Target,
+			change.SourcePath,
+		)
+	}
+
 	// Reattach the original extension to the new file name
 	if conf.IgnoreExt && !change.IsDir {
 		change.Target += fileExt
 	}
 
+	if conf.PadNum > 0 {
+		padNumbers(conf, change)
+	}
+
 	change.Target = strings.TrimSpace(filepath.Clean(change.Target))
 	change.Status = status.OK
 	change.TargetPath = filepath.Join(change.TargetDir, change.Target)
 
+	report.Debug(conf, "new path of '%s' is '%s'", change.SourcePath, change.TargetPath)
+
 	return nil
 }
 
+// countCandidates returns the number of changes that will be renamed
+// independently. Secondary files in a pair are not counted since they
+// inherit the name of their primary pair.
+func countCandidates(matches file.Changes) int {
+	var count int
+
+	for i := range matches {
+		if matches[i].PrimaryPair == nil {
+			count++
+		}
+	}
+
+	return count
+}
+
 // replaceMatches handles the replacement of matches in each file with the
 // replacement string.
 func replaceMatches(
@@ -71,12 +329,16 @@ func replaceMatches(
 		return nil, err
 	}
 
-	// If using indexes without an explicit sort, ensure that the files
-	// are arranged hierarchically
-	if vars.IndexMatches() > 0 && conf.Sort == config.SortDefault {
-		sortfiles.Hierarchically(matches)
+	// If using indexes or an explicit traversal order without an explicit
+	// sort, ensure that the files are arranged hierarchically
+	if (vars.IndexMatches() > 0 || vars.PositionMatches() > 0 ||
+		conf.Traversal != config.TraversalDefault) &&
+		conf.Sort == config.SortDefault {
+		sortfiles.ByTraversal(matches, conf.Traversal)
 	}
 
+	vars.SetTotal(countCandidates(matches))
+
 	var pairs int
 
 	for i := range matches {
@@ -84,9 +346,8 @@ func replaceMatches(
 
 		// Detect and rename file pairs
 		if change.PrimaryPair != nil {
-			ext := filepath.Ext(change.Source)
 			common := pathutil.StripExtension(change.PrimaryPair.Target)
-			change.Target = common + ext
+			change.Target = common + pairExt(change)
 			change.TargetPath = filepath.Join(
 				change.TargetDir,
 				change.Target,
@@ -116,6 +377,21 @@ func handleReplacementChain(
 ) (file.Changes, error) {
 	replacementSlice := conf.ReplacementSlice
 
+	// The changes may be reordered by each replacement, so the original
+	// source names are tracked per change
+	sources := make(map[*file.Change]string, len(matches))
+	for i := range matches {
+		sources[matches[i]] = matches[i].Source
+	}
+
+	// Restore the original source names once all the replacements have been
+	// applied so that the report and backup file reflect the actual files
+	defer func() {
+		for i := range matches {
+			matches[i].Source = sources[matches[i]]
+		}
+	}()
+
 	for i, v := range replacementSlice {
 		conf.Replacement = v
 
@@ -174,6 +450,8 @@ func Replace(
 				return nil, err
 			}
 
+			vars.SetTotal(len(changes))
+
 			err = applyReplacement(conf, &vars, ch)
 			if err != nil {
 				return nil, err
@@ -186,6 +464,21 @@ func Replace(
 		return nil, err
 	}
 
+	if conf.Renumber {
+		renumber(conf, changes)
+	}
+
+	if conf.FixExt {
+		err = fixExtensions(changes)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if form, ok := normalForms[conf.Normalize]; ok {
+		normalizeTargets(changes, form)
+	}
+
 	if (conf.IncludeDir || conf.CSVFilename != "") && conf.Exec {
 		sortfiles.ForRenamingAndUndo(changes, conf.Revert)
 	}
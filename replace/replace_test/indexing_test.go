@@ -1,12 +1,31 @@
 package replace_test
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/ayoisaiah/f2/v2/internal/file"
 	"github.com/ayoisaiah/f2/v2/internal/testutil"
 )
 
+// createNumberedFiles creates files with numbered names in a temporary
+// directory and returns the path to the directory.
+func createNumberedFiles(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	for _, v := range []string{"photo_001.jpg", "photo_007.jpg", "photo_x.jpg"} {
+		err := os.WriteFile(filepath.Join(dir, v), nil, 0o600)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
 func TestIndexing(t *testing.T) {
 	testCases := []testutil.TestCase{
 		{
@@ -25,6 +44,33 @@ func TestIndexing(t *testing.T) {
 			Want: []string{"1.txt", "2.txt", "3.txt"},
 			Args: []string{"-f", "a|b|c", "-r", "{%d}"},
 		},
+		{
+			Name: "number the contents of each directory before the directory",
+			Changes: file.Changes{
+				{
+					Source: "docs",
+					IsDir:  true,
+				},
+				{
+					Source: "a.txt",
+				},
+				{
+					BaseDir: "docs",
+					Source:  "b.txt",
+				},
+			},
+			Want: []string{"a_1.txt", "docs/b_2.txt", "docs_3"},
+			Args: []string{
+				"-f",
+				".*",
+				"-r",
+				"${0}_{%d}",
+				"-e",
+				"-d",
+				"--traversal",
+				"depth-post",
+			},
+		},
 		{
 			Name: "replace with multiple incrementing integers",
 			Changes: file.Changes{
@@ -41,6 +87,31 @@ func TestIndexing(t *testing.T) {
 			Want: []string{"1_10_0100.txt", "2_20_0200.txt", "3_30_0300.txt"},
 			Args: []string{"-f", "a|b|c", "-r", "{%d}_{10%02d10}_{100%04d100}"},
 		},
+		{
+			Name: "reuse the number of another counter with a different format",
+			Changes: file.Changes{
+				{
+					Source: "a.txt",
+				},
+				{
+					Source: "b.txt",
+				},
+				{
+					Source: "c.txt",
+				},
+			},
+			Want: []string{
+				"1_10_0010_X.txt",
+				"2_20_0020_XX.txt",
+				"3_30_0030_XXX.txt",
+			},
+			Args: []string{
+				"-f",
+				"a|b|c",
+				"-r",
+				"{%d}_{10%d10}_{%[2]04d}_{%[2]dr}",
+			},
+		},
 		{
 			Name: "replace with non-arabic numerals",
 			Changes: file.Changes{
@@ -57,6 +128,51 @@ func TestIndexing(t *testing.T) {
 			Want: []string{"I_1 1_1.txt", "II_2 2_10.txt", "III_3 3_11.txt"},
 			Args: []string{"-f", "a|b|c", "-r", "{%dr}_{%do} {%dh}_{%db}"},
 		},
+		{
+			Name: "replace with hexadecimal, octal and custom base counters",
+			Changes: file.Changes{
+				{
+					Source: "a.img",
+				},
+				{
+					Source: "b.img",
+				},
+				{
+					Source: "c.img",
+				},
+			},
+			Want: []string{
+				"fw_00FE_376_y_0s.img",
+				"fw_00FF_377_z_0t.img",
+				"fw_0100_400_10_0u.img",
+			},
+			Args: []string{
+				"-f",
+				"a|b|c",
+				"-r",
+				"fw_{254%04X}_{254%o}_{34%d(36)}_{28%02d(36)}",
+			},
+		},
+		{
+			Name: "replace with alphabetic and lowercase roman numerals",
+			Changes: file.Changes{
+				{
+					Source: "a.txt",
+				},
+				{
+					Source: "b.txt",
+				},
+				{
+					Source: "c.txt",
+				},
+			},
+			Want: []string{
+				"appendix_a_Z_i.txt",
+				"appendix_b_AA_ii.txt",
+				"appendix_c_AB_iii.txt",
+			},
+			Args: []string{"-f", "a|b|c", "-r", "appendix_{%da}_{26%dA}_{%di}"},
+		},
 		{
 			Name: "skip some numbers when incrementing",
 			Changes: file.Changes{
@@ -73,6 +189,84 @@ func TestIndexing(t *testing.T) {
 			Want: []string{"16.txt", "17.txt", "18.txt"},
 			Args: []string{"-f", "a|b|c", "-r", "{10%d<10-15>}"},
 		},
+		{
+			Name: "increment indexes with a custom step",
+			Changes: file.Changes{
+				{
+					Source: "a.txt",
+				},
+				{
+					Source: "b.txt",
+				},
+				{
+					Source: "c.txt",
+				},
+			},
+			Want: []string{"10_1.txt", "20_3.txt", "30_5.txt"},
+			Args: []string{"-f", "a|b|c", "-r", "{10%d}_{%d2}", "--step", "10"},
+		},
+		{
+			Name: "decrement indexes with a negative step",
+			Changes: file.Changes{
+				{
+					Source: "a.txt",
+				},
+				{
+					Source: "b.txt",
+				},
+				{
+					Source: "c.txt",
+				},
+			},
+			Want: []string{"03.txt", "02.txt", "01.txt"},
+			Args: []string{"-f", "a|b|c", "-r", "{3%02d}", "--step", "-1"},
+		},
+		{
+			Name: "skip numbers provided through --number-skip",
+			Changes: file.Changes{
+				{
+					Source: "a.txt",
+				},
+				{
+					Source: "b.txt",
+				},
+				{
+					Source: "c.txt",
+				},
+			},
+			Want: []string{"1_01.txt", "3_03.txt", "6_06.txt"},
+			Args: []string{
+				"-f",
+				"a|b|c",
+				"-r",
+				"{%d}_{%02d}",
+				"--number-skip",
+				"2;4-5",
+			},
+		},
+		{
+			Name: "prefer the skip list of the index variable to --number-skip",
+			Changes: file.Changes{
+				{
+					Source: "a.txt",
+				},
+				{
+					Source: "b.txt",
+				},
+				{
+					Source: "c.txt",
+				},
+			},
+			Want: []string{"1_1.txt", "3_2.txt", "4_4.txt"},
+			Args: []string{
+				"-f",
+				"a|b|c",
+				"-r",
+				"{%d}_{%d<3>}",
+				"--number-skip",
+				"2",
+			},
+		},
 		{
 			Name: "use integer capture variables",
 			Changes: file.Changes{
@@ -121,6 +315,39 @@ func TestIndexing(t *testing.T) {
 			Want: []string{"002.txt", "005.txt", "099.txt"},
 			Args: []string{"-f", "doc(\\d+)", "-r", "{$1%03d<1;4>}"},
 		},
+		{
+			Name: "replace with the total number of matches",
+			Changes: file.Changes{
+				{
+					Source: "a.jpg",
+				},
+				{
+					Source: "b.jpg",
+				},
+				{
+					Source: "c.jpg",
+				},
+			},
+			Want: []string{
+				"photo_001_of_3.jpg",
+				"photo_002_of_3.jpg",
+				"photo_003_of_3.jpg",
+			},
+			Args: []string{"-f", "a|b|c", "-r", "photo_{%03d}_of_{total}"},
+		},
+		{
+			Name: "replace with the position of each match",
+			Changes: file.Changes{
+				{
+					Source: "a.jpg",
+				},
+				{
+					Source: "b.jpg",
+				},
+			},
+			Want: []string{"a_1-2.jpg", "b_2-2.jpg"},
+			Args: []string{"-f", "(a|b)", "-r", "${1}_{{n}}-{{total}}"},
+		},
 		{
 			Name: "reset index per directory",
 			Changes: file.Changes{
@@ -165,6 +392,157 @@ func TestIndexing(t *testing.T) {
 				"--reset-index-per-dir",
 			},
 		},
+		{
+			Name: "reset index per directory regardless of sort order",
+			Changes: file.Changes{
+				{
+					BaseDir: "folder1",
+					Source:  "f1.log",
+				},
+				{
+					BaseDir: "folder2",
+					Source:  "f2.log",
+				},
+				{
+					BaseDir: "folder1",
+					Source:  "f3.log",
+				},
+				{
+					BaseDir: "folder2",
+					Source:  "f4.log",
+				},
+			},
+			Want: []string{
+				"folder1/f1_10.log",
+				"folder2/f2_10.log",
+				"folder1/f3_12.log",
+				"folder2/f4_12.log",
+			},
+			Args: []string{
+				"-f",
+				".*",
+				"-r",
+				"{f}_{10%d<11>}{ext}",
+				"--reset-index-per-dir",
+			},
+		},
+	}
+
+	replaceTest(t, testCases)
+}
+
+func TestResumeIndex(t *testing.T) {
+	dir := createNumberedFiles(t)
+
+	testCases := []testutil.TestCase{
+		{
+			Name: "resume indexing from the highest existing number",
+			Changes: file.Changes{
+				{
+					BaseDir: dir,
+					Source:  "IMG_4021.jpg",
+				},
+				{
+					BaseDir: dir,
+					Source:  "IMG_4022.jpg",
+				},
+			},
+			Want: []string{
+				filepath.Join(dir, "photo_008.jpg"),
+				filepath.Join(dir, "photo_009.jpg"),
+			},
+			Args: []string{
+				"-f",
+				`IMG_\d+`,
+				"-r",
+				"photo_{%03d}",
+				"--resume-index",
+			},
+		},
+	}
+
+	replaceTest(t, testCases)
+}
+
+func TestSkipExistingNumbers(t *testing.T) {
+	dir := createNumberedFiles(t)
+
+	testCases := []testutil.TestCase{
+		{
+			Name: "skip the numbers used by existing files",
+			Changes: file.Changes{
+				{
+					BaseDir: dir,
+					Source:  "IMG_4021.jpg",
+				},
+				{
+					BaseDir: dir,
+					Source:  "IMG_4022.jpg",
+				},
+				{
+					BaseDir: dir,
+					Source:  "IMG_4023.jpg",
+				},
+			},
+			Want: []string{
+				filepath.Join(dir, "photo_005.jpg"),
+				filepath.Join(dir, "photo_006.jpg"),
+				filepath.Join(dir, "photo_008.jpg"),
+			},
+			Args: []string{
+				"-f",
+				`IMG_\d+`,
+				"-r",
+				"photo_{5%03d}",
+				"--number-skip",
+				"existing",
+			},
+		},
+		{
+			Name: "skip the numbers used by existing files along with other numbers",
+			Changes: file.Changes{
+				{
+					BaseDir: dir,
+					Source:  "IMG_4021.jpg",
+				},
+				{
+					BaseDir: dir,
+					Source:  "IMG_4022.jpg",
+				},
+			},
+			Want: []string{
+				filepath.Join(dir, "photo_003.jpg"),
+				filepath.Join(dir, "photo_004.jpg"),
+			},
+			Args: []string{
+				"-f",
+				`IMG_\d+`,
+				"-r",
+				"photo_{%03d}",
+				"--number-skip",
+				"existing; 2",
+			},
+		},
+		{
+			Name: "don't skip the numbers of files that match another target",
+			Changes: file.Changes{
+				{
+					BaseDir: dir,
+					Source:  "IMG_4021.jpg",
+				},
+			},
+			Want: []string{
+				filepath.Join(dir, "image_001.jpg"),
+			},
+			Args: []string{
+				"-f",
+				`IMG_\d+`,
+				"-r",
+				"image_{%03d}",
+				"--number-skip",
+				"existing",
+			},
+		},
 	}
 
 	replaceTest(t, testCases)
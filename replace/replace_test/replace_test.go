@@ -2,6 +2,8 @@ package replace_test
 
 import (
 	"errors"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -11,6 +13,39 @@ import (
 	"github.com/ayoisaiah/f2/v2/replace"
 )
 
+// createMislabeledFiles creates files whose extensions may not match their
+// contents.
+func createMislabeledFiles(t *testing.T, _ string) func() {
+	t.Helper()
+
+	dir := filepath.Join("testdata", "mislabeled")
+
+	err := os.MkdirAll(dir, os.ModePerm)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string][]byte{
+		"image.png":    {0xFF, 0xD8, 0xFF, 0xE0, 0x00, 0x10, 'J', 'F', 'I', 'F'},
+		"document.pdf": []byte("%PDF-1.7"),
+		"notes.txt":    []byte("plain text"),
+	}
+
+	for name, content := range files {
+		err = os.WriteFile(filepath.Join(dir, name), content, 0o600)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return func() {
+		err = os.RemoveAll(dir)
+		if err != nil {
+			t.Log(err)
+		}
+	}
+}
+
 func replaceTest(t *testing.T, cases []testutil.TestCase) {
 	t.Helper()
 
@@ -63,6 +98,323 @@ func TestReplace(t *testing.T) {
 			},
 			Args: []string{"-f", "macos", "-r", "darwin"},
 		},
+		{
+			Name: "pad existing numbers with zeros",
+			Changes: file.Changes{
+				{
+					Source: "track1.mp3",
+				},
+				{
+					Source: "disc2_track10.mp3",
+				},
+				{
+					Source: "track100.mp3",
+				},
+			},
+			Want: []string{
+				"track01.mp3",
+				"disc02_track10.mp3",
+				"track100.mp3",
+			},
+			Args: []string{"--pad-num", "2"},
+		},
+		{
+			Name: "pad numbers after replacing",
+			Changes: file.Changes{
+				{
+					Source: "ep1.mkv",
+				},
+			},
+			Want: []string{"episode_001.mkv"},
+			Args: []string{"-f", "ep", "-r", "episode_", "--pad-num", "3"},
+		},
+		{
+			Name: "renumber a sequence to close gaps",
+			Changes: file.Changes{
+				{
+					Source: "img_7.jpg",
+				},
+				{
+					Source: "img_2.jpg",
+				},
+				{
+					Source: "img_4.jpg",
+				},
+				{
+					Source: "img_1.jpg",
+				},
+			},
+			Want: []string{
+				"img_4.jpg",
+				"img_2.jpg",
+				"img_3.jpg",
+				"img_1.jpg",
+			},
+			Args: []string{"--renumber"},
+		},
+		{
+			Name: "renumber zero padded numbers in each directory",
+			Changes: file.Changes{
+				{
+					BaseDir: "season1",
+					Source:  "s01e003.mkv",
+				},
+				{
+					BaseDir: "season1",
+					Source:  "s01e005.mkv",
+				},
+				{
+					BaseDir: "season2",
+					Source:  "s02e010.mkv",
+				},
+				{
+					BaseDir: "season2",
+					Source:  "s02e014.mkv",
+				},
+			},
+			Want: []string{
+				"season1/s01e003.mkv",
+				"season1/s01e004.mkv",
+				"season2/s02e010.mkv",
+				"season2/s02e011.mkv",
+			},
+			Args: []string{"--renumber"},
+		},
+		{
+			Name: "replace using a glob pattern",
+			Changes: file.Changes{
+				{
+					Source: "IMG_2041.jpeg",
+				},
+				{
+					Source: "IMG_2042.JPEG",
+				},
+				{
+					Source: "notes.txt",
+				},
+			},
+			Want: []string{
+				"photo_2041.jpg",
+				"IMG_2042.JPEG",
+				"notes.txt",
+			},
+			Args: []string{"-f", "IMG_*.jpeg", "-r", "photo_$1.jpg", "--glob"},
+		},
+		{
+			Name: "replace using a glob pattern with character classes",
+			Changes: file.Changes{
+				{
+					Source: "a1.txt",
+				},
+				{
+					Source: "b2.txt",
+				},
+				{
+					Source: "c3.txt",
+				},
+			},
+			Want: []string{"file-a1.txt", "file-b2.txt", "c3.txt"},
+			Args: []string{"-f", "[!c]?.txt", "-r", "file-$0", "--glob"},
+		},
+		{
+			Name: "replace approximate matches in fuzzy mode",
+			Changes: file.Changes{
+				{
+					Source: "recieved_invoice.pdf",
+				},
+				{
+					Source: "Receved-receipt.pdf",
+				},
+				{
+					Source: "reserved.pdf",
+				},
+				{
+					Source: "notes.txt",
+				},
+			},
+			Want: []string{
+				"received_invoice.pdf",
+				"received-receipt.pdf",
+				"received.pdf",
+				"notes.txt",
+			},
+			Args: []string{
+				"-f",
+				"received",
+				"-r",
+				"received",
+				"--fuzzy",
+				"2",
+				"-i",
+			},
+		},
+		{
+			Name: "replace fuzzy matches in names that change length when folded",
+			Changes: file.Changes{
+				{
+					Source: "ȺȺȺ.txt",
+				},
+				{
+					Source: "ȺȺȺabc.txt",
+				},
+				{
+					Source: "xⱥbc.txt",
+				},
+			},
+			Want: []string{"ȺȺȺ.txt", "ȺȺȺZ.txt", "xZ.txt"},
+			Args: []string{"-f", "Ⱥbc", "-r", "Z", "--fuzzy", "1", "-i"},
+		},
+		{
+			Name: "replace lookarounds with --pcre",
+			Changes: file.Changes{
+				{
+					Source: "IMG_123.jpg",
+				},
+				{
+					Source: "123.jpg",
+				},
+			},
+			Want: []string{"IMG_x123.jpg", "123.jpg"},
+			Args: []string{"-f", `(?<=IMG_)\d+`, "-r", "x$0", "--pcre"},
+		},
+		{
+			Name: "replace backreferences with --pcre",
+			Changes: file.Changes{
+				{
+					Source: "bookkeeper.txt",
+				},
+			},
+			Want: []string{"bokeper.txt"},
+			Args: []string{"-f", `(\w)\1`, "-r", "$1", "--pcre"},
+		},
+		{
+			Name: "replace named groups with --pcre",
+			Changes: file.Changes{
+				{
+					Source: "2023-05.txt",
+				},
+			},
+			Want: []string{"05-2023.txt"},
+			Args: []string{
+				"-f", `(?P<year>\d{4})-(\d{2})`,
+				"-r", "$2-${year}",
+				"--pcre",
+			},
+		},
+		{
+			Name: "replace named backreferences with --pcre",
+			Changes: file.Changes{
+				{
+					Source: "a-b-b.txt",
+				},
+				{
+					Source: "a-b-c.txt",
+				},
+			},
+			Want: []string{"a-b.txt", "a-b-c.txt"},
+			Args: []string{
+				"-f", `(\w)-(?<c>\w)-\k<c>`,
+				"-r", "$1-$2",
+				"--pcre",
+			},
+		},
+		{
+			Name: "replace only the nth match",
+			Changes: file.Changes{
+				{
+					Source: "2023_report_final_v2.pdf",
+				},
+			},
+			Want: []string{"2023_report-final_v2.pdf"},
+			Args: []string{"-f", "_", "-r", "-", "--replace-nth", "2"},
+		},
+		{
+			Name: "replace only the last match with capture variables",
+			Changes: file.Changes{
+				{
+					Source: "draft-draft-draft.md",
+				},
+			},
+			Want: []string{"draft-draft-[draft].md"},
+			Args: []string{"-f", "(draft)", "-r", "[$1]", "--replace-nth", "-1"},
+		},
+		{
+			Name: "replace the entire name of files in invert mode",
+			Changes: file.Changes{
+				{
+					Source: "beach.jpg",
+				},
+			},
+			Want: []string{"IMG_beach.jpg"},
+			Args: []string{"-f", "^IMG_", "-r", "IMG_{f}{ext}", "--invert"},
+		},
+		{
+			Name: "fix extensions that do not match the file contents",
+			Changes: file.Changes{
+				{
+					BaseDir: "testdata/mislabeled",
+					Source:  "image.png",
+				},
+				{
+					BaseDir: "testdata/mislabeled",
+					Source:  "document.pdf",
+				},
+				{
+					BaseDir: "testdata/mislabeled",
+					Source:  "notes.txt",
+				},
+				{
+					BaseDir: "testdata",
+					Source:  "19. D_1993 F2.flac",
+				},
+			},
+			Want: []string{
+				"testdata/mislabeled/image.jpg",
+				"testdata/mislabeled/document.pdf",
+				"testdata/mislabeled/notes.txt",
+				"testdata/19. D_1993 F2.flac",
+			},
+			Args:      []string{"--fix-ext"},
+			SetupFunc: createMislabeledFiles,
+		},
+		{
+			Name: "fix extensions after replacing the file name",
+			Changes: file.Changes{
+				{
+					BaseDir: "testdata/mislabeled",
+					Source:  "image.png",
+				},
+			},
+			Want: []string{
+				"testdata/mislabeled/photo.jpg",
+			},
+			Args:      []string{"-f", "image", "-r", "photo", "--fix-ext"},
+			SetupFunc: createMislabeledFiles,
+		},
+		{
+			Name: "normalize target names to NFC",
+			Changes: file.Changes{
+				{
+					Source: "cafe\u0301_menu.pdf",
+				},
+			},
+			Want: []string{
+				"caf\u00e9.pdf",
+			},
+			Args: []string{"-f", "_menu", "-r", "", "--normalize", "nfc"},
+		},
+		{
+			Name: "normalize target names to NFD",
+			Changes: file.Changes{
+				{
+					Source: "caf\u00e9_menu.pdf",
+				},
+			},
+			Want: []string{
+				"cafe\u0301.pdf",
+			},
+			Args: []string{"-f", "_menu", "-r", "", "--normalize", "NFD"},
+		},
 		{
 			Name: "replace only the first match",
 			Changes: file.Changes{
@@ -196,6 +548,34 @@ func TestReplace(t *testing.T) {
 			},
 			Args: []string{"-f", ".*", "-r", "picture-{%03d}", "--pair"},
 		},
+		{
+			Name: "rename sidecar files with their pair",
+			Changes: file.Changes{
+				{
+					Source: "IMG_0042.CR2",
+				},
+				{
+					Source: "IMG_0042.CR2.xmp",
+				},
+				{
+					Source: "IMG_0042.JPG",
+				},
+				{
+					Source: "movie.en.srt",
+				},
+				{
+					Source: "movie.mkv",
+				},
+			},
+			Want: []string{
+				"picture-001.CR2",
+				"picture-001.JPG",
+				"picture-001.CR2.xmp",
+				"picture-002.mkv",
+				"picture-002.en.srt",
+			},
+			Args: []string{"-f", ".*", "-r", "picture-{%03d}", "--pair"},
+		},
 		{
 			Name: "multiple file pairs",
 			Changes: file.Changes{
@@ -329,3 +709,43 @@ func TestReplace(t *testing.T) {
 
 	replaceTest(t, testCases)
 }
+
+func TestReplaceChainPreservesSource(t *testing.T) {
+	tc := testutil.TestCase{
+		Name: "keep the original source after a replacement chain",
+		Changes: file.Changes{
+			{
+				Source: "a-b-c.txt",
+			},
+		},
+		Args: []string{
+			"-f",
+			"-",
+			"-r",
+			"_",
+			"-f",
+			"a",
+			"-r",
+			"A",
+			"-f",
+			"c",
+			"-r",
+			"C",
+		},
+	}
+
+	conf := testutil.GetConfig(t, &tc, ".")
+
+	changes, err := replace.Replace(conf, tc.Changes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if changes[0].Source != "a-b-c.txt" || changes[0].Target != "A_b_C.txt" {
+		t.Fatalf(
+			"expected a-b-c.txt -> A_b_C.txt, but got: %s -> %s",
+			changes[0].Source,
+			changes[0].Target,
+		)
+	}
+}
@@ -8,7 +8,17 @@ import (
 	"strings"
 )
 
-var errInvalidSubmatches = errors.New("Invalid number of submatches")
+var (
+	errInvalidSubmatches = errors.New("Invalid number of submatches")
+
+	errInvalidIndexReference = errors.New(
+		"Index variable references a non-existent counter",
+	)
+
+	errInvalidIndexBase = errors.New(
+		"Index variable base must be between 2 and 36",
+	)
+)
 
 // getCSVVars retrieves all the csv variables in the replacement
 // string if any.
@@ -209,6 +219,88 @@ func getExifVars(replacementInput string) (exifVars, error) {
 	return exifMatches, nil
 }
 
+// parseSkipNumbers parses a semicolon separated list of numbers and
+// number ranges (such as `1;4;10-15`) that should be skipped when indexing.
+func parseSkipNumbers(skipNumbers string) ([]numbersToSkip, error) {
+	var skip []numbersToSkip
+
+	numRanges := strings.Split(skipNumbers, ";")
+	for _, val := range numRanges {
+		val = strings.TrimSpace(val)
+
+		if strings.Contains(val, "-") {
+			numRange := strings.Split(val, "-")
+
+			startNum, err := strconv.Atoi(numRange[0])
+			if err != nil {
+				return nil, err
+			}
+
+			endNum, err := strconv.Atoi(numRange[1])
+			if err != nil {
+				return nil, err
+			}
+
+			skip = append(skip, numbersToSkip{
+				max: int(math.Max(float64(startNum), float64(endNum))),
+				min: int(math.Min(float64(startNum), float64(endNum))),
+			})
+
+			continue
+		}
+
+		num, err := strconv.Atoi(val)
+		if err != nil {
+			return nil, err
+		}
+
+		skip = append(skip, numbersToSkip{
+			max: num,
+			min: num,
+		})
+	}
+
+	return skip, nil
+}
+
+// getIndexBase returns the radix of an index variable based on its format verb
+// (%x or %o) or number system (h, o, b, or a custom base such as (36)).
+func getIndexBase(indexFormat, numberSystem string) (int, error) {
+	switch numberSystem {
+	case "h":
+		return 16, nil
+	case "o":
+		return 8, nil
+	case "b":
+		return 2, nil
+	case "":
+	default:
+		if !strings.HasPrefix(numberSystem, "(") {
+			return 0, nil
+		}
+
+		base, err := strconv.Atoi(strings.Trim(numberSystem, "()"))
+		if err != nil {
+			return 0, err
+		}
+
+		if base < 2 || base > 36 {
+			return 0, errInvalidIndexBase
+		}
+
+		return base, nil
+	}
+
+	switch indexFormat[len(indexFormat)-1] {
+	case 'x', 'X':
+		return 16, nil
+	case 'o':
+		return 8, nil
+	}
+
+	return 0, nil
+}
+
 // getIndexingVars retrieves all the index variables in the replacement string
 // if any.
 func getIndexingVars(replacementInput string) (indexVars, error) {
@@ -223,24 +315,30 @@ func getIndexingVars(replacementInput string) (indexVars, error) {
 		return indexMatches, nil
 	}
 
-	expectedLength := 8
+	expectedLength := 9
 
 	for i, submatch := range submatches {
 		if len(submatch) < expectedLength {
 			panic(errInvalidSubmatches)
 		}
 
-		regex, err := regexp.Compile(submatch[0])
+		// The variable is quoted since a counter reference contains brackets
+		regex, err := regexp.Compile(regexp.QuoteMeta(submatch[0]))
 		if err != nil {
 			return indexMatches, err
 		}
 
 		match := indexVarMatch{
-			regex:        regex,
-			submatch:     submatch,
-			startNumber:  1,
-			indexFormat:  submatch[3],
-			numberSystem: submatch[5],
+			regex:       regex,
+			submatch:    submatch,
+			startNumber: 1,
+			indexFormat: strings.Replace(
+				submatch[3],
+				"["+submatch[4]+"]",
+				"",
+				1,
+			),
+			numberSystem: submatch[6],
 		}
 
 		if submatch[1] != "" {
@@ -254,56 +352,47 @@ func getIndexingVars(replacementInput string) (indexVars, error) {
 			}
 		}
 
-		if submatch[6] != "" {
+		if submatch[4] != "" {
+			match.reference, err = strconv.Atoi(submatch[4])
+			if err != nil {
+				return indexMatches, err
+			}
+
+			if match.reference < 1 || match.reference > len(submatches) {
+				return indexMatches, errInvalidIndexReference
+			}
+		}
+
+		match.base, err = getIndexBase(match.indexFormat, match.numberSystem)
+		if err != nil {
+			return indexMatches, err
+		}
+
+		if submatch[7] != "" {
 			match.step.isSet = true
 
-			match.step.value, err = strconv.Atoi(submatch[6])
+			match.step.value, err = strconv.Atoi(submatch[7])
 			if err != nil {
 				return indexMatches, err
 			}
 		}
 
-		skipNumbers := submatch[7]
-		if skipNumbers != "" {
-			numRanges := strings.Split(skipNumbers, ";")
-			for _, val := range numRanges {
-				if strings.Contains(val, "-") {
-					numRange := strings.Split(val, "-")
-
-					startNum, err := strconv.Atoi(numRange[0])
-					if err != nil {
-						return indexMatches, err
-					}
-
-					endNum, err := strconv.Atoi(numRange[1])
-					if err != nil {
-						return indexMatches, err
-					}
-
-					match.skip = append(match.skip, numbersToSkip{
-						max: int(math.Max(float64(startNum), float64(endNum))),
-						min: int(math.Min(float64(startNum), float64(endNum))),
-					})
-
-					continue
-				}
-
-				num, err := strconv.Atoi(val)
-				if err != nil {
-					return indexMatches, err
-				}
-
-				match.skip = append(match.skip, numbersToSkip{
-					max: num,
-					min: num,
-				})
+		if submatch[8] != "" {
+			match.skip, err = parseSkipNumbers(submatch[8])
+			if err != nil {
+				return indexMatches, err
 			}
 		}
 
 		indexMatches.matches = append(indexMatches.matches, match)
 	}
 
-	for range indexMatches.matches {
+	for i := range indexMatches.matches {
+		ref := indexMatches.matches[i].reference
+		if ref != 0 && indexMatches.matches[ref-1].reference != 0 {
+			return indexMatches, errInvalidIndexReference
+		}
+
 		indexMatches.offset = append(indexMatches.offset, 0)
 	}
 
@@ -464,6 +553,38 @@ func getParentDirVars(replacementInput string) (parentDirVars, error) {
 	return pvMatches, nil
 }
 
+// getPositionVars retrieves all the {n} and {total} variables in the
+// replacement string if any.
+func getPositionVars(replacementInput string) (positionVars, error) {
+	var posMatches positionVars
+
+	if !positionVarRegex.MatchString(replacementInput) {
+		return posMatches, nil
+	}
+
+	submatches := positionVarRegex.FindAllStringSubmatch(replacementInput, -1)
+
+	expectedLength := 2
+
+	for _, submatch := range submatches {
+		if len(submatch) < expectedLength {
+			return posMatches, errInvalidSubmatches
+		}
+
+		regex, err := regexp.Compile(submatch[0])
+		if err != nil {
+			return posMatches, err
+		}
+
+		posMatches.matches = append(posMatches.matches, positionVarMatch{
+			regex: regex,
+			name:  submatch[1],
+		})
+	}
+
+	return posMatches, nil
+}
+
 func getFilenameVars(replacementInput string) (filenameVars, error) {
 	var fvMatches filenameVars
 
@@ -529,6 +650,11 @@ func Extract(replacement string) (Variables, error) {
 		return vars, err
 	}
 
+	vars.position, err = getPositionVars(replacement)
+	if err != nil {
+		return vars, err
+	}
+
 	vars.id3, err = getID3Vars(replacement)
 	if err != nil {
 		return vars, err
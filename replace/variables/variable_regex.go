@@ -15,6 +15,7 @@ var (
 	extensionVarRegex *regexp.Regexp
 	parentDirVarRegex *regexp.Regexp
 	indexVarRegex     *regexp.Regexp
+	positionVarRegex  *regexp.Regexp
 	hashVarRegex      *regexp.Regexp
 	transformVarRegex *regexp.Regexp
 	csvVarRegex       *regexp.Regexp
@@ -69,8 +70,9 @@ func init() {
 		fmt.Sprintf("{+(\\d+)?p(?:\\.%s)?}+", transformTokens),
 	)
 	indexVarRegex = regexp.MustCompile(
-		`{+(\$\d+)?(\d+)?(%(\d?)+d)([borh])?(-?\d+)?(?:<(\d+(?:-\d+)?(?:;\s*\d+(?:-\d+)?)*)>)?}+`,
+		`{+(\$\d+)?(\d+)?(%(?:\[(\d+)\])?(\d?)+[dxXo])([borhiaA]|\(\d+\))?(-?\d+)?(?:<(\d+(?:-\d+)?(?:;\s*\d+(?:-\d+)?)*)>)?}+`,
 	)
+	positionVarRegex = regexp.MustCompile(`{+(n|total)}+`)
 	hashVarRegex = regexp.MustCompile(
 		fmt.Sprintf(
 			"{+hash.(sha1|sha256|sha512|md5)(?:\\.%s)?}+",
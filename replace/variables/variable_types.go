@@ -12,7 +12,7 @@ type numbersToSkip struct {
 type indexVarMatch struct {
 	regex        *regexp.Regexp
 	indexFormat  string
-	numberSystem string // Binary, Octal, Roman, Decimal
+	numberSystem string // Binary, Octal, Hex, Roman, Alphabetic, Decimal
 	skip         []numbersToSkip
 	submatch     []string
 	step         struct {
@@ -20,14 +20,43 @@ type indexVarMatch struct {
 		value int
 	}
 	startNumber int
+	// base is the radix used for hexadecimal, octal, binary, and custom base
+	// counters (0 for other number systems)
+	base int
+	// reference is the 1-based position of another index variable whose
+	// number is reused by this one (0 if not a reference)
+	reference int
 }
 
 type indexVars struct {
-	currentBaseDir string
+	// dirPositions tracks the number of changes seen so far in each directory
+	dirPositions map[string]int
+	// dirOffsets tracks the skipped numbers for each directory
+	dirOffsets map[string][]int
+	// existingCache records the numbers used by existing files for each target
+	existingCache  map[string][][]int
 	capturVarIndex []int
 	offset         []int
 	matches        []indexVarMatch
-	newDirIndex    int
+	// defaultSkip is used for index variables without a skip list
+	defaultSkip []numbersToSkip
+	// resumeOffset continues indexing from the highest existing number
+	resumeOffset []int
+	// existingSkip holds the numbers used by existing files that each index
+	// variable skips
+	existingSkip [][]int
+	// defaultStep is used for index variables without an explicit step
+	defaultStep int
+}
+
+type positionVarMatch struct {
+	regex *regexp.Regexp
+	name  string // n or total
+}
+
+type positionVars struct {
+	matches []positionVarMatch
+	total   int
 }
 
 type transformVarMatch struct {
@@ -152,8 +181,19 @@ type Variables struct {
 	ext       extVars
 	parentDir parentDirVars
 	index     indexVars
+	position  positionVars
 }
 
 func (v *Variables) IndexMatches() int {
 	return len(v.index.matches)
 }
+
+func (v *Variables) PositionMatches() int {
+	return len(v.position.matches)
+}
+
+// SetTotal records the total number of renaming candidates so that it can be
+// used to replace the {total} variable.
+func (v *Variables) SetTotal(total int) {
+	v.position.total = total
+}
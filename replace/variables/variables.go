@@ -31,6 +31,7 @@ import (
 
 	"github.com/ayoisaiah/f2/v2/internal/osutil"
 	"github.com/ayoisaiah/f2/v2/internal/pathutil"
+	"github.com/ayoisaiah/f2/v2/internal/pattern"
 	"github.com/ayoisaiah/f2/v2/internal/timeutil"
 
 	"github.com/ayoisaiah/f2/v2/internal/config"
@@ -140,53 +141,112 @@ func integerToRoman(integer int) string {
 	return roman.String()
 }
 
+// integerToAlphabet converts an integer to its alphabetic equivalent where
+// 1 is a, 26 is z, 27 is aa, and so on. For integers less than 1, it returns
+// the stringified integer.
+func integerToAlphabet(integer int) string {
+	if integer < 1 {
+		return strconv.Itoa(integer)
+	}
+
+	lettersInAlphabet := 26
+
+	var letters []byte
+
+	for integer > 0 {
+		integer--
+		letters = append([]byte{byte('a' + integer%lettersInAlphabet)}, letters...)
+		integer /= lettersInAlphabet
+	}
+
+	return string(letters)
+}
+
+// regexReplaceFunc replaces the matches in the input for which shouldReplace
+// returns true. The position of each match and the total number of matches
+// are passed to shouldReplace. Capture variables in the replacement are
+// expanded in the context of the full input.
+func regexReplaceFunc(
+	regex pattern.Regexp,
+	input, replacement string,
+	shouldReplace func(pos, total int) bool,
+) string {
+	matches := regex.FindAllStringSubmatchIndex(input, -1)
+	if matches == nil {
+		return input
+	}
+
+	var output []byte
+
+	lastIndex := 0
+
+	for i, match := range matches {
+		if !shouldReplace(i, len(matches)) {
+			continue
+		}
+
+		output = append(output, input[lastIndex:match[0]]...)
+		output = regex.ExpandString(output, replacement, input, match)
+		lastIndex = match[1]
+	}
+
+	output = append(output, input[lastIndex:]...)
+
+	return string(output)
+}
+
 // RegexReplace replaces matched substrings in the input with the replacement.
 // It respects the specified replacement limit. A negative limit indicates that
 // replacement should start from the end of the fileName.
 func RegexReplace(
-	regex *regexp.Regexp,
+	regex pattern.Regexp,
 	input, replacement string,
 	replaceLimit int,
 ) string {
-	var output string
-
 	switch limit := replaceLimit; {
 	case limit > 0:
-		counter := 0
-		output = regex.ReplaceAllStringFunc(
+		return regexReplaceFunc(
+			regex,
 			input,
-			func(val string) string {
-				if counter == replaceLimit {
-					return val
-				}
-
-				counter++
-
-				return regex.ReplaceAllString(val, replacement)
+			replacement,
+			func(pos, _ int) bool {
+				return pos < limit
 			},
 		)
 	case limit < 0:
-		matches := regex.FindAllString(input, -1)
-
-		l := len(matches) + limit
-		counter := 0
-		output = regex.ReplaceAllStringFunc(
+		return regexReplaceFunc(
+			regex,
 			input,
-			func(val string) string {
-				if counter >= l {
-					return regex.ReplaceAllString(val, replacement)
-				}
-
-				counter++
-
-				return val
+			replacement,
+			func(pos, total int) bool {
+				return pos >= total+limit
 			},
 		)
 	default:
-		output = regex.ReplaceAllString(input, replacement)
+		return regex.ReplaceAllString(input, replacement)
 	}
+}
+
+// RegexReplaceNth replaces only the nth match (1-based) in the input. A
+// negative value counts from the end of the input so that -1 replaces the
+// last match.
+func RegexReplaceNth(
+	regex pattern.Regexp,
+	input, replacement string,
+	nth int,
+) string {
+	return regexReplaceFunc(
+		regex,
+		input,
+		replacement,
+		func(pos, total int) bool {
+			if nth < 0 {
+				return pos == total+nth
+			}
 
-	return output
+			return pos == nth-1
+		},
+	)
 }
 
 // getHash retrieves the appropriate hash value for the specified file.
@@ -690,85 +750,310 @@ func replaceExifToolVars(
 	return target, nil
 }
 
-// replaceIndex replaces indexing variables in the target with their
-// corresponding values. The `changeIndex` argument is used in conjunction with
-// other values to increment the current index.
-func replaceIndex(
-	target string,
+// nextIndex computes the number for the index variable at position `i` in the
+// replacement string. The `changeIndex` argument is used in conjunction with
+// other values to increment the current index. The default step and skip
+// values are used for index variables that do not specify their own.
+func nextIndex(
+	i int,
 	changeIndex int, // position of change in the entire renaming operation
 	indexing *indexVars,
-) string {
-	for i := range indexing.matches {
-		current := indexing.matches[i]
+) int {
+	current := indexing.matches[i]
 
-		// This means that the `startNumber` was derived from a captureVariable
-		isCaptureVar := slices.Contains(indexing.capturVarIndex, i)
+	// This means that the `startNumber` was derived from a captureVariable
+	isCaptureVar := slices.Contains(indexing.capturVarIndex, i)
 
-		if !current.step.isSet && !isCaptureVar {
-			current.step.value = 1
-		}
+	if !current.step.isSet && !isCaptureVar {
+		current.step.value = indexing.defaultStep
+	}
+
+	if len(current.skip) == 0 {
+		current.skip = indexing.defaultSkip
+	}
 
-		startNumber := current.startNumber
-		currentIndex := startNumber + (changeIndex * current.step.value) + indexing.offset[i]
+	if len(indexing.existingSkip) > i {
+		current.skip = slices.Clone(current.skip)
 
-		if isCaptureVar {
-			currentIndex = startNumber + current.step.value + indexing.offset[i]
+		for _, num := range indexing.existingSkip[i] {
+			current.skip = append(current.skip, numbersToSkip{min: num, max: num})
 		}
+	}
+
+	startNumber := current.startNumber
+	currentIndex := startNumber + (changeIndex * current.step.value) + indexing.offset[i]
 
-		if len(current.skip) != 0 {
-		outer:
-			for {
-				for _, v := range current.skip {
-					//nolint:gocritic // nesting is manageable
-					if currentIndex >= v.min && currentIndex <= v.max {
-						// Prevent infinite loops when skipping a captured variable
-						step := current.step.value
-						if step == 0 {
-							step = 1
-						}
-
-						currentIndex += step
-
-						if !isCaptureVar {
-							indexing.offset[i] += step
-						}
-
-						continue outer
+	if isCaptureVar {
+		currentIndex = startNumber + current.step.value + indexing.offset[i]
+	}
+
+	if len(indexing.resumeOffset) > i {
+		currentIndex += indexing.resumeOffset[i]
+	}
+
+	if len(current.skip) != 0 {
+	outer:
+		for {
+			for _, v := range current.skip {
+				//nolint:gocritic // nesting is manageable
+				if currentIndex >= v.min && currentIndex <= v.max {
+					// Prevent infinite loops when skipping a captured variable
+					step := current.step.value
+					if step == 0 {
+						step = 1
+					}
+
+					currentIndex += step
+
+					if !isCaptureVar {
+						indexing.offset[i] += step
 					}
+
+					continue outer
 				}
-				break
 			}
+			break
 		}
+	}
 
-		numInt64 := int64(currentIndex)
-
-		var formattedNum string
-
-		switch current.numberSystem {
-		case "r":
-			formattedNum = integerToRoman(currentIndex)
-		case "h":
-			base16 := 16
-			formattedNum = strconv.FormatInt(numInt64, base16)
-		case "o":
-			base8 := 8
-			formattedNum = strconv.FormatInt(numInt64, base8)
-		case "b":
-			base2 := 2
-			formattedNum = strconv.FormatInt(numInt64, base2)
-		default:
-			if currentIndex < 0 {
-				currentIndex *= -1
-				formattedNum = "-" + fmt.Sprintf(
-					current.indexFormat,
-					currentIndex,
-				)
-			} else {
-				formattedNum = fmt.Sprintf(current.indexFormat, currentIndex)
+	return currentIndex
+}
+
+// getExistingNumbers scans the target directory for existing files whose
+// names match the target and returns the numbers that they use in place of
+// each decimal index variable.
+func getExistingNumbers(
+	conf *config.Config,
+	change *file.Change,
+	indexing *indexVars,
+) ([][]int, error) {
+	target := change.Target
+
+	// Only index variables in the file name are considered
+	if strings.ContainsAny(target, `/\`) &&
+		indexVarRegex.MatchString(filepath.Dir(target)) {
+		return nil, nil
+	}
+
+	dir := filepath.Join(change.TargetDir, filepath.Dir(target))
+	base := filepath.Base(target)
+
+	cacheKey := filepath.Join(dir, base)
+	if numbers, ok := indexing.existingCache[cacheKey]; ok {
+		return numbers, nil
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	numbers := make([][]int, len(indexing.matches))
+
+	for i := range indexing.matches {
+		current := indexing.matches[i]
+
+		if current.numberSystem != "" || current.base != 0 ||
+			current.reference != 0 ||
+			slices.Contains(indexing.capturVarIndex, i) {
+			continue
+		}
+
+		pattern := regexp.QuoteMeta(base)
+
+		for j := range indexing.matches {
+			replacement := `[0-9A-Za-z]+`
+			if i == j {
+				replacement = `(-?\d+)`
 			}
+
+			pattern = strings.ReplaceAll(
+				pattern,
+				regexp.QuoteMeta(indexing.matches[j].submatch[0]),
+				replacement,
+			)
+		}
+
+		regex, err := regexp.Compile("^" + pattern + "$")
+		if err != nil {
+			return nil, err
 		}
 
-		target = current.regex.ReplaceAllString(target, formattedNum)
+		for _, entry := range entries {
+			name := entry.Name()
+			if conf.IgnoreExt && !entry.IsDir() {
+				name = pathutil.StripExtension(name)
+			}
+
+			match := regex.FindStringSubmatch(name)
+			if match == nil {
+				continue
+			}
+
+			num, err := strconv.Atoi(match[1])
+			if err != nil {
+				continue
+			}
+
+			numbers[i] = append(numbers[i], num)
+		}
+	}
+
+	if indexing.existingCache == nil {
+		indexing.existingCache = make(map[string][][]int)
+	}
+
+	indexing.existingCache[cacheKey] = numbers
+
+	return numbers, nil
+}
+
+// getResumeOffsets returns the offsets that allow each decimal index variable
+// to continue from the highest number used by the existing files in the
+// target directory.
+func getResumeOffsets(
+	conf *config.Config,
+	change *file.Change,
+	indexing *indexVars,
+) ([]int, error) {
+	numbers, err := getExistingNumbers(conf, change, indexing)
+	if err != nil || numbers == nil {
+		return nil, err
+	}
+
+	offsets := make([]int, len(indexing.matches))
+
+	for i, current := range indexing.matches {
+		if len(numbers[i]) == 0 {
+			continue
+		}
+
+		highest := slices.Max(numbers[i])
+
+		step := current.step.value
+		if !current.step.isSet {
+			step = indexing.defaultStep
+		}
+
+		if step <= 0 || highest < current.startNumber {
+			continue
+		}
+
+		offsets[i] = highest + step - current.startNumber
+	}
+
+	return offsets, nil
+}
+
+// formatIndex formats the number according to the number system and format
+// of the index variable.
+func formatIndex(current indexVarMatch, currentIndex int) string {
+	var formattedNum string
+
+	if current.base != 0 {
+		return formatIndexBase(current, currentIndex)
+	}
+
+	switch current.numberSystem {
+	case "r":
+		formattedNum = integerToRoman(currentIndex)
+	case "i":
+		formattedNum = strings.ToLower(integerToRoman(currentIndex))
+	case "a":
+		formattedNum = integerToAlphabet(currentIndex)
+	case "A":
+		formattedNum = strings.ToUpper(integerToAlphabet(currentIndex))
+	default:
+		if currentIndex < 0 {
+			currentIndex *= -1
+			formattedNum = "-" + fmt.Sprintf(
+				current.indexFormat,
+				currentIndex,
+			)
+		} else {
+			formattedNum = fmt.Sprintf(current.indexFormat, currentIndex)
+		}
+	}
+
+	return formattedNum
+}
+
+// formatIndexBase formats the index in the base of the index variable and
+// zero pads it to the width specified in the index format (e.g. {%04x}).
+func formatIndexBase(current indexVarMatch, currentIndex int) string {
+	formattedNum := strconv.FormatInt(int64(currentIndex), current.base)
+
+	if strings.HasSuffix(current.indexFormat, "X") {
+		formattedNum = strings.ToUpper(formattedNum)
+	}
+
+	width, _ := strconv.Atoi(current.indexFormat[1 : len(current.indexFormat)-1])
+
+	sign := ""
+	if strings.HasPrefix(formattedNum, "-") {
+		sign, formattedNum = "-", formattedNum[1:]
+	}
+
+	if len(formattedNum) < width {
+		formattedNum = strings.Repeat("0", width-len(formattedNum)) + formattedNum
+	}
+
+	return sign + formattedNum
+}
+
+// replaceIndex replaces indexing variables in the target with their
+// corresponding values. Index variables that reference another counter (such
+// as {%[2]03d}) reuse the number of the referenced counter but apply their own
+// formatting.
+func replaceIndex(
+	target string,
+	changeIndex int,
+	indexing *indexVars,
+) string {
+	numbers := make([]int, len(indexing.matches))
+
+	for i := range indexing.matches {
+		if indexing.matches[i].reference != 0 {
+			continue
+		}
+
+		numbers[i] = nextIndex(i, changeIndex, indexing)
+	}
+
+	for i := range indexing.matches {
+		current := indexing.matches[i]
+
+		currentIndex := numbers[i]
+		if current.reference != 0 {
+			currentIndex = numbers[current.reference-1]
+		}
+
+		target = current.regex.ReplaceAllString(
+			target,
+			formatIndex(current, currentIndex),
+		)
+	}
+
+	return target
+}
+
+// replacePositionVars replaces the {n} and {total} variables with the position
+// of the change in the renaming operation and the total number of changes
+// respectively.
+func replacePositionVars(
+	target string,
+	changeIndex int,
+	pv positionVars,
+) string {
+	for i := range pv.matches {
+		current := pv.matches[i]
+
+		value := strconv.Itoa(changeIndex + 1)
+		if current.name == "total" {
+			value = strconv.Itoa(pv.total)
+		}
+
+		target = current.regex.ReplaceAllString(target, value)
 	}
 
 	return target
@@ -1107,19 +1392,36 @@ func Replace(
 		change.Target = out
 	}
 
+	changeIndex := change.Position
+
+	// Restart the index for each directory when the `ResetIndexPerDir` option
+	// is set. The position of each change is tracked per directory so that the
+	// index is correct regardless of how the changes are ordered
 	if conf.ResetIndexPerDir {
-		// Detect when a new directory is entered
-		if change.BaseDir != vars.index.currentBaseDir {
-			// track the position at which the base directory changed
-			vars.index.newDirIndex = change.Position
+		if vars.index.dirPositions == nil {
+			vars.index.dirPositions = make(map[string]int)
+			vars.index.dirOffsets = make(map[string][]int)
 		}
-	}
 
-	vars.index.currentBaseDir = change.BaseDir
+		changeIndex = vars.index.dirPositions[change.BaseDir]
+		vars.index.dirPositions[change.BaseDir]++
+
+		offset, ok := vars.index.dirOffsets[change.BaseDir]
+		if !ok {
+			offset = make([]int, len(vars.index.offset))
+			vars.index.dirOffsets[change.BaseDir] = offset
+		}
 
-	// This has the effect of resetting the index for a new directory when the
-	// `ResetIndexPerDir` option is set
-	changeIndex := change.Position - vars.index.newDirIndex
+		vars.index.offset = offset
+	}
+
+	if len(vars.position.matches) > 0 {
+		change.Target = replacePositionVars(
+			change.Target,
+			change.Position,
+			vars.position,
+		)
+	}
 
 	if indexVarRegex.MatchString(change.Target) {
 		if len(vars.index.capturVarIndex) > 0 {
@@ -1134,6 +1436,35 @@ func Replace(
 			vars.index.matches = numVar.matches
 		}
 
+		vars.index.defaultStep = conf.IndexStep
+
+		if vars.index.defaultSkip == nil {
+			for _, r := range conf.SkipNumbers {
+				vars.index.defaultSkip = append(
+					vars.index.defaultSkip,
+					numbersToSkip{min: r.Min, max: r.Max},
+				)
+			}
+		}
+
+		if conf.SkipExistingNumbers {
+			numbers, err := getExistingNumbers(conf, change, &vars.index)
+			if err != nil {
+				return err
+			}
+
+			vars.index.existingSkip = numbers
+		}
+
+		if conf.ResumeIndex {
+			offsets, err := getResumeOffsets(conf, change, &vars.index)
+			if err != nil {
+				return err
+			}
+
+			vars.index.resumeOffset = offsets
+		}
+
 		change.Target = replaceIndex(change.Target, changeIndex, &vars.index)
 	}
 
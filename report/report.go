@@ -3,8 +3,13 @@
 package report
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/pterm/pterm"
 
@@ -12,11 +17,25 @@ import (
 	"github.com/ayoisaiah/f2/v2/internal/config"
 	"github.com/ayoisaiah/f2/v2/internal/file"
 	"github.com/ayoisaiah/f2/v2/internal/osutil"
+	"github.com/ayoisaiah/f2/v2/internal/status"
 )
 
+// ExitWithErr prints the error and exits with the exit code of the error if it
+// has one. Errors without a message are not printed since they have already
+// been reported.
 func ExitWithErr(err error) {
 	pterm.EnableOutput()
 
+	// The error may have occurred before the configuration was loaded
+	if config.ColorDisabled() {
+		pterm.DisableStyling()
+	}
+
+	code := int(osutil.ExitError)
+	if c := apperr.ExitCode(err); c != 0 {
+		code = c
+	}
+
 	errPrefix := "error:"
 	errMessage := err.Error()
 
@@ -26,11 +45,14 @@ func ExitWithErr(err error) {
 		errMessage = strings.TrimSpace(s[1])
 	}
 
-	pterm.Fprintln(
-		config.Stderr,
-		pterm.Sprintf("%s %v", pterm.Red(errPrefix), errMessage),
-	)
-	os.Exit(int(osutil.ExitError))
+	if errMessage != "" {
+		pterm.Fprintln(
+			config.Stderr,
+			pterm.Sprintf("%s %v", pterm.Red(errPrefix), errMessage),
+		)
+	}
+
+	os.Exit(code)
 }
 
 func BackupFailed(err error) {
@@ -47,6 +69,48 @@ func BackupFileRemovalFailed(err error) {
 	)
 }
 
+// HistoryPruned prints the number of operations that were removed from the
+// history (--prune-history).
+func HistoryPruned(conf *config.Config, pruned int) {
+	if conf.Quiet {
+		return
+	}
+
+	pterm.Fprintln(
+		config.Stderr,
+		pterm.Sprintf(
+			"%s removed %d operation(s) from the history",
+			pterm.Green("pruned:"),
+			pruned,
+		),
+	)
+}
+
+// Watching prints the directories that are watched for new files (--watch).
+func Watching(conf *config.Config, dirs []string) {
+	if conf.Quiet {
+		return
+	}
+
+	pterm.Fprintln(
+		config.Stderr,
+		pterm.Sprintf(
+			"%s %d director(ies) for new files (press Ctrl+C to stop)",
+			pterm.Green("watching:"),
+			len(dirs),
+		),
+	)
+}
+
+// WatchFailed prints an error that occurred while renaming new files in watch
+// mode, which continues with the next files.
+func WatchFailed(err error) {
+	pterm.Fprintln(
+		config.Stderr,
+		pterm.Sprintf("%s %v", pterm.Red("error:"), err),
+	)
+}
+
 func ShortHelp(helpText string) {
 	pterm.Fprintln(config.Stderr, helpText)
 }
@@ -62,6 +126,229 @@ func DefaultOpt(opt, val string) {
 	)
 }
 
+// OverwrittenFiles warns that the files which were overwritten in the renaming
+// operation being undone cannot be restored.
+func OverwrittenFiles(paths []string) {
+	pterm.Fprintln(
+		config.Stderr,
+		pterm.Sprintf(
+			"%s the following files were overwritten and cannot be restored:",
+			pterm.Yellow("warning:"),
+		),
+	)
+
+	for _, path := range paths {
+		pterm.Fprintln(config.Stderr, pterm.Sprintf("  %s", path))
+	}
+}
+
+// EmptyFilenameSkipped warns that a file is skipped because its new name is
+// empty (--fix-empty skip).
+func EmptyFilenameSkipped(path string) {
+	pterm.Fprintln(
+		config.Stderr,
+		pterm.Sprintf(
+			"%s skipping '%s' since its new name is empty",
+			pterm.Yellow("warning:"),
+			path,
+		),
+	)
+}
+
+// ModifiedSinceRename warns that a file is not restored when undoing a
+// renaming operation because its contents changed after it was renamed.
+func ModifiedSinceRename(path string) {
+	pterm.Fprintln(
+		config.Stderr,
+		pterm.Sprintf(
+			"%s skipping '%s' since it was modified after it was renamed (use --allow-modified to restore it)",
+			pterm.Yellow("warning:"),
+			path,
+		),
+	)
+}
+
+func NotALink(path, original string) {
+	pterm.Fprintln(
+		config.Stderr,
+		pterm.Sprintf(
+			"%s skipping '%s' since it is no longer a link to '%s'",
+			pterm.Yellow("warning:"),
+			path,
+			original,
+		),
+	)
+}
+
+// MoveProgress prints the percentage of a large file that was copied while it
+// is moved to another filesystem. The line is overwritten on each update and
+// ended once the file is fully copied.
+func MoveProgress(path string, percent int) {
+	pterm.Fprint(
+		config.Stderr,
+		pterm.Sprintf(
+			"\r%s copying '%s' to another device: %3d%%",
+			pterm.Yellow("moving:"),
+			path,
+			percent,
+		),
+	)
+
+	if percent == 100 {
+		pterm.Fprintln(config.Stderr)
+	}
+}
+
+// Confirm asks whether to commit the change (--interactive). The prompt is
+// written even in quiet mode since an answer is required.
+func Confirm(conf *config.Config, change *file.Change) {
+	prompt := fmt.Sprintf(
+		"%s '%s' to '%s'?",
+		conf.Operation,
+		change.SourcePath,
+		change.TargetPath,
+	)
+
+	switch {
+	case change.Remove:
+		prompt = fmt.Sprintf("remove '%s'?", change.SourcePath)
+	case conf.Operation == config.OperationSymlink ||
+		conf.Operation == config.OperationHardlink:
+		prompt = fmt.Sprintf(
+			"link '%s' to '%s'?",
+			change.TargetPath,
+			change.SourcePath,
+		)
+	}
+
+	fmt.Fprint(
+		config.Stderr,
+		pterm.Sprintf("%s %s [y/N/a/q] ", pterm.Yellow("confirm:"), prompt),
+	)
+}
+
+// progressBarWidth is the number of characters in the progress bar.
+const progressBarWidth = 30
+
+// Progress prints how many of the changes have been committed so far along
+// with the estimated time until all of them are. On a terminal, a bar is
+// drawn over the previous one, while a line is printed on each update
+// otherwise so that logs stay readable.
+func Progress(done, total int, eta time.Duration, terminal bool) {
+	percent := done * 100 / total
+
+	left := "--"
+	if done > 0 {
+		left = eta.Round(time.Second).String()
+	}
+
+	if !terminal {
+		msg := pterm.Sprintf(
+			"%s %d of %d changes committed (%d%%",
+			pterm.Yellow("progress:"),
+			done,
+			total,
+			percent,
+		)
+
+		if done > 0 {
+			msg += ", " + left + " left"
+		}
+
+		pterm.Fprintln(config.Stderr, msg+")")
+
+		return
+	}
+
+	filled := progressBarWidth * done / total
+
+	pterm.Fprint(
+		config.Stderr,
+		pterm.Sprintf(
+			"\r%s [%s%s] %d/%d %3d%% ETA %s\033[K",
+			pterm.Yellow("renaming:"),
+			strings.Repeat("=", filled),
+			strings.Repeat(" ", progressBarWidth-filled),
+			done,
+			total,
+			percent,
+			left,
+		),
+	)
+}
+
+// ProgressDone removes the progress bar from the terminal once all the
+// changes have been committed.
+func ProgressDone() {
+	pterm.Fprint(config.Stderr, "\r\033[K")
+}
+
+// Retrying warns that an operation on the file failed due to a transient
+// error and is retried after the delay (--retries).
+func Retrying(conf *config.Config, path string, delay time.Duration, err error) {
+	if conf.Quiet {
+		return
+	}
+
+	pterm.Fprintln(
+		config.Stderr,
+		pterm.Sprintf(
+			"%s retrying '%s' in %v: %v",
+			pterm.Yellow("warning:"),
+			path,
+			delay,
+			err,
+		),
+	)
+}
+
+// RollbackFailed warns that a change could not be undone when rolling back a
+// renaming operation that failed (--on-error=rollback).
+func RollbackFailed(path string, err error) {
+	pterm.Fprintln(
+		config.Stderr,
+		pterm.Sprintf(
+			"%s unable to roll back '%s': %v",
+			pterm.Yellow("warning:"),
+			path,
+			err,
+		),
+	)
+}
+
+// RestoreFailed warns that a file which was overwritten by a renaming
+// operation could not be restored from the trash (--trash) or its backup
+// (--backup) after the operation was undone or failed.
+func RestoreFailed(path string, err error) {
+	pterm.Fprintln(
+		config.Stderr,
+		pterm.Sprintf(
+			"%s unable to restore '%s': %v",
+			pterm.Yellow("warning:"),
+			path,
+			err,
+		),
+	)
+}
+
+// Debug prints a message about a step of the operation along with the time
+// at which it was taken (--debug).
+func Debug(conf *config.Config, format string, a ...any) {
+	if !conf.Debug {
+		return
+	}
+
+	pterm.Fprintln(
+		config.Stderr,
+		pterm.Sprintf(
+			"%s %s %s",
+			time.Now().Format("15:04:05.000"),
+			pterm.Gray("debug:"),
+			fmt.Sprintf(format, a...),
+		),
+	)
+}
+
 func NonExistentFile(name string, row int) {
 	pterm.Fprintln(
 		config.Stderr,
@@ -77,7 +364,7 @@ func NonExistentFile(name string, row int) {
 // to match any files.
 func NoMatches(conf *config.Config) {
 	if conf.Quiet {
-		os.Exit(int(osutil.ExitError))
+		return
 	}
 
 	msg := "the search criteria didn't match any files"
@@ -89,9 +376,99 @@ func NoMatches(conf *config.Config) {
 		msg = "nothing to undo"
 	}
 
+	if conf.Redo {
+		msg = "nothing to redo"
+	}
+
 	pterm.Fprintln(config.Stderr, pterm.Sprint(msg))
 }
 
+// Check prints the changes that would be made in JSON format when --json is
+// set. Nothing is printed otherwise since the outcome of a check is conveyed
+// through the exit code.
+func Check(conf *config.Config, pending file.Changes) {
+	if !conf.JSON {
+		return
+	}
+
+	// an empty list is rendered instead of null
+	if pending == nil {
+		pending = file.Changes{}
+	}
+
+	err := pending.RenderJSON(config.Stdout)
+	if err != nil {
+		pterm.Fprintln(
+			config.Stderr,
+			pterm.Sprintf("%s %v", pterm.Red("error:"), err),
+		)
+	}
+}
+
+// History prints the renaming operations in the history (--history).
+func History(conf *config.Config, history []config.HistoryEntry) {
+	if conf.JSON {
+		// an empty list is rendered instead of null
+		if history == nil {
+			history = []config.HistoryEntry{}
+		}
+
+		err := json.NewEncoder(config.Stdout).Encode(history)
+		if err != nil {
+			pterm.Fprintln(
+				config.Stderr,
+				pterm.Sprintf("%s %v", pterm.Red("error:"), err),
+			)
+		}
+
+		return
+	}
+
+	if len(history) == 0 {
+		if !conf.Quiet {
+			pterm.Fprintln(config.Stderr, "no operations in the history")
+		}
+
+		return
+	}
+
+	data := make([][]string, len(history))
+
+	for i := range history {
+		entry := &history[i]
+
+		entryStatus := pterm.Green("done")
+		if entry.Undone {
+			entryStatus = pterm.Yellow("undone")
+		}
+
+		find := strings.Join(entry.Find, ", ")
+		if entry.CSV != "" {
+			find = "csv: " + entry.CSV
+		}
+
+		data[i] = []string{
+			strconv.Itoa(entry.Index),
+			entry.ID,
+			entry.Date.Format(time.DateTime),
+			entry.WorkingDir,
+			strconv.Itoa(entry.Files),
+			find,
+			strings.Join(entry.Replace, ", "),
+			entryStatus,
+		}
+	}
+
+	file.PrintTable(
+		config.Stdout,
+		[]string{
+			"#", "ID", "DATE", "DIRECTORY", "FILES", "FIND", "REPLACE", "STATUS",
+		},
+		data,
+		conf.NoColor,
+	)
+}
+
 // Report prints a report of the renaming changes to be made.
 func Report(
 	conf *config.Config,
@@ -110,6 +487,32 @@ func Report(
 		return
 	}
 
+	if conf.CSVOutput {
+		err := fileChanges.RenderCSV(config.Stdout)
+		if err != nil {
+			pterm.Fprintln(
+				config.Stderr,
+				pterm.Sprintf("%s %v", pterm.Red("error:"), err),
+			)
+		}
+
+		return
+	}
+
+	if conf.Print0 {
+		for _, change := range fileChanges.Pending() {
+			fmt.Fprint(config.Stdout, change.SourcePath+"\x00"+change.TargetPath+"\x00")
+		}
+
+		return
+	}
+
+	// Quiet mode suppresses the table, unlike the machine-readable output
+	// above which is still printed
+	if conf.Quiet {
+		return
+	}
+
 	fileChanges.RenderTable(config.Stdout, conf.NoColor)
 
 	if conflictDetected || conf.JSON {
@@ -125,45 +528,259 @@ func Report(
 	)
 }
 
-// PrintResults prints the results of a renaming operation, including any errors
-// encountered. It displays successful renames to stderr if verbose mode is
-// enabled, and prints renamed paths to stdout if output is piped. Errors are
-// always printed to stderr.
+// Summary prints the number of changes by outcome along with how long the
+// operation took (--summary). The changes that would be renamed are counted
+// as such in a dry run or when conflicts prevent the renaming.
+func Summary(conf *config.Config, fileChanges file.Changes, elapsed time.Duration) {
+	if !conf.Summary || conf.Quiet {
+		return
+	}
+
+	var renamed, skipped, conflicts, failed int
+
+	for _, change := range fileChanges {
+		//nolint:exhaustive // default case covers the conflicts
+		switch change.Status {
+		case status.OK, status.Unchanged, status.Overwriting, status.Ignored:
+		default:
+			conflicts++
+			continue
+		}
+
+		switch {
+		case change.Error != nil:
+			failed++
+		case change.Status == status.Ignored ||
+			change.SourcePath == change.TargetPath:
+			skipped++
+		default:
+			renamed++
+		}
+	}
+
+	renamedText := "renamed"
+	if !conf.Exec || conflicts > 0 {
+		renamedText = "to rename"
+	}
+
+	pterm.Fprintln(
+		config.Stderr,
+		pterm.Sprintf(
+			"%s %d matched, %d %s, %d skipped, %d conflicts, %d failed in %s",
+			pterm.Green("summary:"),
+			len(fileChanges),
+			renamed,
+			renamedText,
+			skipped,
+			conflicts,
+			failed,
+			elapsed.Round(time.Millisecond),
+		),
+	)
+}
+
+// EmptyDirs prints the directories that will be removed after renaming
+// because they are left empty (--clean).
+func EmptyDirs(conf *config.Config, dirs []string) {
+	if len(dirs) == 0 || conf.JSON || conf.Quiet {
+		return
+	}
+
+	pterm.Fprintln(
+		config.Stderr,
+		pterm.Sprintf(
+			"%s %d empty director(ies) will be removed:",
+			pterm.Green("dry run:"),
+			len(dirs),
+		),
+	)
+
+	for _, dir := range dirs {
+		pterm.Fprintln(config.Stderr, "  "+dir)
+	}
+}
+
+// Conflicts prints only the changes that have conflicts (--report-conflicts).
+func Conflicts(conf *config.Config, conflicts file.Changes) {
+	if conf.JSON {
+		// an empty list is rendered instead of null
+		if conflicts == nil {
+			conflicts = file.Changes{}
+		}
+
+		err := conflicts.RenderJSON(config.Stdout)
+		if err != nil {
+			pterm.Fprintln(
+				config.Stderr,
+				pterm.Sprintf("%s %v", pterm.Red("error:"), err),
+			)
+		}
+
+		return
+	}
+
+	if len(conflicts) == 0 {
+		pterm.Fprintln(config.Stderr, pterm.Sprint("no conflicts found"))
+
+		return
+	}
+
+	conflicts.RenderTable(config.Stdout, conf.NoColor)
+}
+
+// Duplicates prints the names that are shared by files in different
+// directories after renaming (--report-duplicates).
+func Duplicates(conf *config.Config, duplicates []file.Duplicate) {
+	if conf.JSON {
+		jsonData, err := json.Marshal(duplicates)
+		if err == nil {
+			_, err = config.Stdout.Write(jsonData)
+		}
+
+		if err != nil {
+			pterm.Fprintln(
+				config.Stderr,
+				pterm.Sprintf("%s %v", pterm.Red("error:"), err),
+			)
+		}
+
+		return
+	}
+
+	if len(duplicates) == 0 {
+		pterm.Fprintln(
+			config.Stderr,
+			pterm.Sprint("no duplicate names found across directories"),
+		)
+
+		return
+	}
+
+	file.RenderDuplicatesTable(config.Stdout, duplicates, conf.NoColor)
+}
+
+// Failures prints a summary of the files that could not be renamed along with
+// the reason for each one.
+func Failures(conf *config.Config, fileChanges file.Changes, errIndices []int) {
+	data := make([][]string, 0, len(errIndices))
+
+	for _, index := range errIndices {
+		change := fileChanges[index]
+
+		// The paths are already in the table so only the cause is shown
+		reason := change.Error
+		if cause := errors.Unwrap(reason); cause != nil {
+			reason = cause
+		}
+
+		data = append(data, []string{
+			change.SourcePath,
+			change.TargetPath,
+			reason.Error(),
+		})
+	}
+
+	pterm.Fprintln(
+		config.Stderr,
+		pterm.Sprintf(
+			"%s %d of %d file(s) could not be renamed:",
+			pterm.Red("failed:"),
+			len(errIndices),
+			len(fileChanges),
+		),
+	)
+
+	file.PrintTable(
+		config.Stderr,
+		[]string{"ORIGINAL", "RENAMED", "ERROR"},
+		data,
+		conf.NoColor,
+	)
+}
+
+// Unverified prints the changes that were not found as expected on the
+// filesystem after renaming along with the problem with each one (--verify).
+func Unverified(conf *config.Config, fileChanges file.Changes, errs []error) {
+	data := make([][]string, 0, len(fileChanges))
+
+	for i, change := range fileChanges {
+		data = append(data, []string{
+			change.SourcePath,
+			change.TargetPath,
+			errs[i].Error(),
+		})
+	}
+
+	pterm.Fprintln(
+		config.Stderr,
+		pterm.Sprintf(
+			"%s %d file(s) were not found as expected after renaming:",
+			pterm.Red("verify:"),
+			len(fileChanges),
+		),
+	)
+
+	file.PrintTable(
+		config.Stderr,
+		[]string{"ORIGINAL", "RENAMED", "PROBLEM"},
+		data,
+		conf.NoColor,
+	)
+}
+
+// PrintResults prints the results of a renaming operation, including a
+// summary of the files that could not be renamed. It displays successful
+// renames to stderr if verbose mode is enabled, and prints renamed paths to
+// stdout if output is piped or NUL-delimited if --print0 is set, or the
+// outcome of every change in JSON format if --json is set. Errors are always
+// printed to stderr.
 func PrintResults(conf *config.Config, fileChanges file.Changes, err error) {
 	if err != nil {
 		//nolint:errorlint // checking if err matches custom interface
 		renameErr, ok := err.(*apperr.Error)
 		if ok {
 			errIndices, ok := renameErr.Context.([]int)
-			if ok {
-				for _, index := range errIndices {
-					change := fileChanges[index]
-
-					pterm.Fprintln(
-						config.Stderr,
-						pterm.Sprintf(
-							"%s %v",
-							pterm.Red("error:"),
-							change.Error,
-						),
-					)
-				}
+			if ok && len(errIndices) > 0 {
+				Failures(conf, fileChanges, errIndices)
 			}
 		}
 	}
 
-	if !conf.Verbose && !conf.PipeOutput {
+	if conf.JSON {
+		err = fileChanges.RenderJSON(config.Stdout)
+		if err != nil {
+			pterm.Fprintln(
+				config.Stderr,
+				pterm.Sprintf("%s %v", pterm.Red("error:"), err),
+			)
+		}
+
+		return
+	}
+
+	if !conf.Verbose && !conf.PipeOutput && !conf.Print0 {
 		return
 	}
 
 	for i := range fileChanges {
 		change := fileChanges[i]
 
-		if conf.PipeOutput && change.Error == nil {
+		// skip the files that could not be renamed or were left untouched
+		if change.Error != nil || change.Status == status.Ignored {
+			continue
+		}
+
+		// The NUL-delimited paths are written directly so that they are not
+		// silenced in quiet mode
+		switch {
+		case conf.Print0:
+			fmt.Fprint(config.Stdout, change.TargetPath+"\x00")
+		case conf.PipeOutput:
 			pterm.Fprintln(config.Stdout, change.TargetPath)
 		}
 
-		if !conf.Verbose {
+		// unchanged paths are not renamed
+		if !conf.Verbose || change.SourcePath == change.TargetPath {
 			continue
 		}
 
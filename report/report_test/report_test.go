@@ -7,10 +7,12 @@ import (
 	"os/exec"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/ayoisaiah/f2/v2/internal/apperr"
 	"github.com/ayoisaiah/f2/v2/internal/config"
 	"github.com/ayoisaiah/f2/v2/internal/file"
+	"github.com/ayoisaiah/f2/v2/internal/osutil"
 	"github.com/ayoisaiah/f2/v2/internal/status"
 	"github.com/ayoisaiah/f2/v2/internal/testutil"
 	"github.com/ayoisaiah/f2/v2/report"
@@ -96,14 +98,15 @@ func reportTest(t *testing.T, cases []testutil.TestCase) {
 				t.Cleanup(tc.SetupFunc(t, ""))
 			}
 
+			var stderr bytes.Buffer
+
+			config.Stderr = &stderr
+
 			conf := testutil.GetConfig(t, &tc, ".")
 
 			var stdout bytes.Buffer
 
-			var stderr bytes.Buffer
-
 			config.Stdout = &stdout
-			config.Stderr = &stderr
 
 			switch strings.Split(t.Name(), "/")[0] {
 			case "TestReport":
@@ -112,6 +115,14 @@ func reportTest(t *testing.T, cases []testutil.TestCase) {
 				report.PrintResults(conf, tc.Changes, tc.Error)
 			case "TestNoMatches":
 				report.NoMatches(conf)
+			case "TestDuplicates":
+				report.Duplicates(conf, tc.Changes.Duplicates())
+			case "TestConflicts":
+				report.Conflicts(conf, tc.Changes.Conflicts())
+			case "TestCheck":
+				report.Check(conf, tc.Changes.Pending())
+			case "TestSummary":
+				report.Summary(conf, tc.Changes, 1234567*time.Microsecond)
 			}
 
 			tc.SnapShot.Stdout = stdout.Bytes()
@@ -128,9 +139,11 @@ func TestPrintResults(t *testing.T) {
 			Name: "print results with errors",
 			Changes: file.Changes{
 				{
-					Source: "a.txt",
-					Target: "b.txt",
-					Status: status.OK,
+					Source:     "a.txt",
+					Target:     "b.txt",
+					SourcePath: "a.txt",
+					TargetPath: "b.txt",
+					Status:     status.OK,
 					Error: errors.New(
 						"rename a.txt b.txt: operation not permitted",
 					),
@@ -175,6 +188,51 @@ func TestPrintResults(t *testing.T) {
 			},
 			Args: []string{"-f", "-r", "-V"},
 		},
+		{
+			Name: "print results in JSON",
+			Changes: file.Changes{
+				{
+					Source:     "a.txt",
+					Target:     "b.txt",
+					SourcePath: "a.txt",
+					TargetPath: "b.txt",
+					Status:     status.OK,
+					Error: errors.New(
+						"rename a.txt b.txt: operation not permitted",
+					),
+				},
+				{
+					Source: "c.txt",
+					Target: "d.txt",
+					Status: status.OK,
+				},
+			},
+			Error: &apperr.Error{
+				Context: []int{0},
+			},
+			Args: []string{"-f", "-r", "--json"},
+		},
+		{
+			Name: "print results with NUL-delimited paths",
+			Changes: file.Changes{
+				{
+					Source: "a.txt",
+					Target: "b c.txt",
+					Status: status.OK,
+				},
+				{
+					Source: "d.txt",
+					Target: "e\nf.txt",
+					Status: status.OK,
+				},
+				{
+					Source: "g.txt",
+					Target: "h.txt",
+					Status: status.Ignored,
+				},
+			},
+			Args: []string{"-f", "-r", "--print0"},
+		},
 	}
 
 	reportTest(t, testCases)
@@ -220,6 +278,14 @@ func TestReport(t *testing.T) {
 				"F2_NO_COLOR": "",
 			},
 		},
+		{
+			Name:    "report file status with dumb TERM",
+			Changes: filesNoConflicts,
+			Args:    []string{"-r"},
+			SetEnv: map[string]string{
+				"TERM": "dumb",
+			},
+		},
 		{
 			Name:             "report file conflicts in JSON",
 			Changes:          filesWithConflicts,
@@ -231,6 +297,33 @@ func TestReport(t *testing.T) {
 			Changes: filesNoConflicts,
 			Args:    []string{"-f", "-r", "--json"},
 		},
+		{
+			Name:             "report file conflicts in quiet mode",
+			Changes:          filesWithConflicts,
+			ConflictDetected: true,
+			Args:             []string{"-f", "-r", "-q"},
+		},
+		{
+			Name:    "report file status in quiet mode",
+			Changes: filesNoConflicts,
+			Args:    []string{"-f", "-r", "-q"},
+		},
+		{
+			Name:             "report file conflicts in CSV",
+			Changes:          filesWithConflicts,
+			ConflictDetected: true,
+			Args:             []string{"-f", "-r", "--csv-output"},
+		},
+		{
+			Name:    "report file status in CSV",
+			Changes: filesNoConflicts,
+			Args:    []string{"-f", "-r", "--csv-output"},
+		},
+		{
+			Name:    "report file status with NUL-delimited paths",
+			Changes: filesNoConflicts,
+			Args:    []string{"-f", "-r", "--print0"},
+		},
 	}
 
 	reportTest(t, testCases)
@@ -255,6 +348,155 @@ func TestNoMatches(t *testing.T) {
 	reportTest(t, testCases)
 }
 
+var filesWithDuplicates = file.Changes{
+	{
+		Source:  "01.jpg",
+		Target:  "cover.jpg",
+		BaseDir: "album1",
+	},
+	{
+		Source:  "02.jpg",
+		Target:  "back.jpg",
+		BaseDir: "album1",
+	},
+	{
+		Source:  "01.jpg",
+		Target:  "cover.jpg",
+		BaseDir: "album2",
+	},
+	{
+		Source:  "front.jpg",
+		Target:  "cover.jpg",
+		BaseDir: "album2",
+	},
+	{
+		Source:  "03.jpg",
+		Target:  "back.jpg",
+		BaseDir: "album1",
+	},
+}
+
+func TestDuplicates(t *testing.T) {
+	testCases := []testutil.TestCase{
+		{
+			Name:    "report duplicate names",
+			Changes: filesWithDuplicates,
+			Args:    []string{"-f", "-r", "--no-color", "--report-duplicates"},
+		},
+		{
+			Name:    "report duplicate names in JSON",
+			Changes: filesWithDuplicates,
+			Args:    []string{"-f", "-r", "--json", "--report-duplicates"},
+		},
+		{
+			Name:    "report no duplicate names",
+			Changes: filesNoConflicts,
+			Args:    []string{"--report-duplicates"},
+		},
+	}
+
+	reportTest(t, testCases)
+}
+
+func TestConflicts(t *testing.T) {
+	testCases := []testutil.TestCase{
+		{
+			Name:    "report only conflicts",
+			Changes: append(filesWithConflicts, filesNoConflicts...),
+			Args:    []string{"-f", "-r", "--no-color", "--report-conflicts"},
+		},
+		{
+			Name:    "report only conflicts in JSON",
+			Changes: append(filesWithConflicts, filesNoConflicts...),
+			Args:    []string{"-f", "-r", "--json", "--report-conflicts"},
+		},
+		{
+			Name:    "report no conflicts",
+			Changes: filesNoConflicts,
+			Args:    []string{"-f", "-r", "--report-conflicts"},
+		},
+		{
+			Name:    "report no conflicts in JSON",
+			Changes: filesNoConflicts,
+			Args:    []string{"-f", "-r", "--json", "--report-conflicts"},
+		},
+	}
+
+	reportTest(t, testCases)
+}
+
+func TestCheck(t *testing.T) {
+	testCases := []testutil.TestCase{
+		{
+			Name:    "check prints nothing without JSON",
+			Changes: filesNoConflicts,
+			Args:    []string{"-f", "-r", "--check"},
+		},
+		{
+			Name:    "check pending changes in JSON",
+			Changes: filesNoConflicts,
+			Args:    []string{"-f", "-r", "--json", "--check"},
+		},
+		{
+			Name: "check no pending changes in JSON",
+			Changes: file.Changes{
+				{
+					Source: "report.pdf",
+					Target: "report.pdf",
+					Status: status.Unchanged,
+				},
+			},
+			Args: []string{"-f", "-r", "--json", "--check"},
+		},
+	}
+
+	reportTest(t, testCases)
+}
+
+func TestSummary(t *testing.T) {
+	testCases := []testutil.TestCase{
+		{
+			Name:    "summary is not printed by default",
+			Changes: filesNoConflicts,
+			Args:    []string{"-f", "-r"},
+		},
+		{
+			Name:    "summarize a dry run",
+			Changes: filesNoConflicts,
+			Args:    []string{"-f", "-r", "--summary"},
+		},
+		{
+			Name:    "summarize conflicts",
+			Changes: filesWithConflicts,
+			Args:    []string{"-f", "-r", "--summary", "-x"},
+		},
+		{
+			Name: "summarize a renaming operation with failures",
+			Changes: file.Changes{
+				{
+					Source: "a.txt",
+					Target: "b.txt",
+					Status: status.OK,
+				},
+				{
+					Source: "c.txt",
+					Target: "d.txt",
+					Status: status.OK,
+					Error:  errors.New("permission denied"),
+				},
+				{
+					Source: "e.txt",
+					Target: "e.txt",
+					Status: status.Unchanged,
+				},
+			},
+			Args: []string{"-f", "-r", "--summary", "-x"},
+		},
+	}
+
+	reportTest(t, testCases)
+}
+
 func TestExitWithErr(t *testing.T) {
 	if os.Getenv("BE_CRASHER") == "1" {
 		report.ExitWithErr(errors.New("something went wrong"))
@@ -305,6 +547,22 @@ func TestBackupRemovalFailed(t *testing.T) {
 	testutil.CompareGoldenFile(t, &tc)
 }
 
+func TestOverwrittenFiles(t *testing.T) {
+	tc := testutil.TestCase{
+		Name: "report overwritten files",
+	}
+
+	var stderr bytes.Buffer
+
+	config.Stderr = &stderr
+
+	report.OverwrittenFiles([]string{"photos/cover.jpg", "notes.txt"})
+
+	tc.SnapShot.Stderr = stderr.Bytes()
+
+	testutil.CompareGoldenFile(t, &tc)
+}
+
 func TestNonExistentFile(t *testing.T) {
 	tc := testutil.TestCase{
 		Name: "report non existent file",
@@ -320,3 +578,60 @@ func TestNonExistentFile(t *testing.T) {
 
 	testutil.CompareGoldenFile(t, &tc)
 }
+
+func TestProgress(t *testing.T) {
+	testCases := []struct {
+		name     string
+		done     int
+		terminal bool
+	}{
+		{name: "report progress before any change", done: 0},
+		{name: "report progress on a terminal", done: 250, terminal: true},
+		{name: "report progress in a log", done: 250},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			tc := testutil.TestCase{
+				Name: v.name,
+			}
+
+			var stderr bytes.Buffer
+
+			config.Stderr = &stderr
+
+			report.Progress(v.done, 1000, 90*time.Second, v.terminal)
+
+			tc.SnapShot.Stderr = stderr.Bytes()
+
+			testutil.CompareGoldenFile(t, &tc)
+		})
+	}
+}
+
+func TestExitWithErrCode(t *testing.T) {
+	if os.Getenv("BE_CRASHER") == "1" {
+		report.ExitWithErr(&apperr.Error{
+			Message:  "conflict: resolve manually",
+			ExitCode: int(osutil.ExitConflicts),
+		})
+
+		return
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=TestExitWithErrCode")
+	cmd.Env = append(os.Environ(), "BE_CRASHER=1")
+
+	err := cmd.Run()
+	//nolint:errorlint // checking if err matches exit error
+	if e, ok := err.(*exec.ExitError); ok &&
+		e.ExitCode() == int(osutil.ExitConflicts) {
+		return
+	}
+
+	t.Fatalf(
+		"process ran with err %v, want exit status %d",
+		err,
+		osutil.ExitConflicts,
+	)
+}
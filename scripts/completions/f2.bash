@@ -4,35 +4,108 @@ f2_opts="
   --find
   --replace
   --undo
+  --allow-modified
   --allow-overwrites
+  --backup
+  --backup-dir
+  --check
+  --ci-fs
   --clean
+  --copy
+  --csv-output
+  --debug
+  --depth
   --exclude
   --exclude-dir
   --exec
+  --ext
+  --filter-time
   --fix-conflicts
   --fix-conflicts-pattern
+  --conflict-suffix
+  --fix-empty
+  --fix-ext
+  --follow-dir-links
+  --from-file
+  --fuzzy
+  --git
+  --glob
   --help
+  --grep
+  --grep-limit
   --hidden
+  --hidden-dirs
+  --hidden-files
+  --history
+  --history-limit
+  --history-max-age
   --include-dir
   --ignore-case
   --ignore-ext
+  --interactive
+  --invert
   --json
+  --link
+  --long-paths
+  --match-path
   --max-depth
+  --max-size
+  --min-size
+  --newer-than
   --no-color
+  --no-lock
+  --no-preserve
+  --normalize
+  --number-skip
+  --older-than
+  --on-error
+  --one-file-system
   --only-dir
+  --owned-by
+  --pad-num
   --pair
   --pair-order
+  --pcre
+  --preserve-owner
+  --print0
+  --prune-empty
+  --prune-history
   --quiet
   --recursive
+  --redo
   --replace-limit
+  --replace-nth
+  --renumber
+  --report-conflicts
+  --report-duplicates
   --reset-index-per-dir
+  --respect-gitignore
+  --resume-index
+  --retries
   --sort
   --sortr
   --sort-per-dir
   --sort-var
+  --step
   --string-mode
+  --summary
+  --symlinks
   --target-dir
+  --target-os
+  --throttle
+  --trash
+  --traversal
+  --tui
+  --two-phase
+  --undo-id
+  --undo-last
   --verbose
+  --verify
+  --verify-checksum
+  --watch
+  --where
+  --workers
+  --writable-only
   --version
 "
 __f2_completions()
@@ -6,10 +6,26 @@ complete --command f2 --long-option replace --short-option r --description "Repl
 
 complete --command f2 --long-option undo --short-option u --description "Undo the last renaming operation in current directory" --no-files
 
+complete --command f2 --long-option allow-modified --description "Rename files modified after matching" --no-files
+
 complete --command f2 --long-option allow-overwrites --description "Allow overwriting existing files" --no-files
 
-complete --command f2 --long-option clean --short-option c --description "Clean
-empty directories after renaming" --no-files
+complete --command f2 --long-option backup --description "Keep a backup of overwritten files" --no-files
+
+complete --command f2 --long-option backup-dir --description "Put the backups of overwritten files in a directory"
+complete --command f2 --long-option check --description "Exit with a status code instead of renaming" --no-files
+
+complete --command f2 --long-option ci-fs --description "Treat paths that differ only in case as the same" --no-files
+
+complete --command f2 --long-option clean --long-option prune-empty --short-option c --description "Clean empty directories after renaming" --no-files
+
+complete --command f2 --long-option copy --description "Copy files to their new names instead of renaming" --no-files
+
+complete --command f2 --long-option csv-output --description "Print the table of changes in CSV format" --no-files
+
+complete --command f2 --long-option debug --description "Log each step of the operation" --no-files
+
+complete --command f2 --long-option depth --description "Match only entries at the specified depth" --no-files
 
 complete --command f2 --long-option exclude --short-option E --description "Exclude files and directories matching pattern" --no-files
 
@@ -19,40 +35,147 @@ complete --command f2 --long-option exiftool-opts --description "Customize Exift
 
 complete --command f2 --long-option exec --short-option x --description "Execute renaming operation" --no-files
 
+complete --command f2 --long-option ext --description "Match only files with the provided extensions" --no-files
+
+complete --command f2 --long-option filter-time --description "Specify the time attribute for date filters" --no-files
+
 complete --command f2 --long-option fix-conflicts --short-option F --description "Auto fix renaming conflicts" --no-files
 
-complete --command f2 --long-option fix-conflicts-pattern --description "Provide a custom pattern for conflict resolution" --no-files
+complete --command f2 --long-option fix-conflicts-pattern --long-option conflict-suffix --description "Provide a custom pattern for conflict resolution" --no-files
+
+complete --command f2 --long-option fix-empty --description "Choose how empty file names are fixed" --no-files
+
+complete --command f2 --long-option fix-ext --description "Correct extensions that do not match the file contents" --no-files
+
+complete --command f2 --long-option follow-dir-links --description "Descend into symbolic links to directories" --no-files
+
+complete --command f2 --long-option from-file --description "Read the paths to operate on from a file" --force-files
+
+complete --command f2 --long-option fuzzy --description "Match names within an edit distance of the find string" --no-files
+
+complete --command f2 --long-option git --description "Rename tracked files with git mv" --no-files
+
+complete --command f2 --long-option glob --description "Treat the search pattern as a glob" --no-files
 
 complete --command f2 --long-option help --short-option h --description "Display help and exit" --no-files
 
+complete --command f2 --long-option grep --description "Match only files whose contents match a pattern" --no-files
+
+complete --command f2 --long-option grep-limit --description "Set how much of each file is searched with --grep" --no-files
+
 complete --command f2 --long-option hidden --short-option H --description "Match hidden files" --no-files
 
+complete --command f2 --long-option hidden-dirs --description "Match hidden directories but not hidden files" --no-files
+
+complete --command f2 --long-option hidden-files --description "Match hidden files but not hidden directories" --no-files
+
+complete --command f2 --long-option history --description "List the renaming operations in the history" --no-files
+
+complete --command f2 --long-option history-limit --description "Set the number of operations kept in the history" --no-files
+
+complete --command f2 --long-option history-max-age --description "Prune operations older than a date or duration from the history" --no-files
+
 complete --command f2 --long-option include-dir --short-option d --description "Match directories" --no-files
 
 complete --command f2 --long-option ignore-case --short-option i --description "Make searches case insensitive" --no-files
 
 complete --command f2 --long-option ignore-ext --short-option e --description "Ignore file extension" --no-files
 
+complete --command f2 --long-option interactive --description "Ask before renaming each file" --no-files
+
+complete --command f2 --long-option invert --description "Match files that do not match the find pattern" --no-files
+
 complete --command f2 --long-option json --description "Enable json output" --no-files
 
+set -l link_args "
+  sym\t'Create symbolic links'
+  hard\t'Create hard links'
+"
+
+complete --command f2 --long-option link --description "Create links to files at their new names" --exclusive --keep-order --arguments $link_args
+
+complete --command f2 --long-option long-paths --description "Allow paths over the Windows length limit" --no-files
+
+complete --command f2 --long-option match-path --description "Match against the relative path" --no-files
+
 complete --command f2 --long-option max-depth --short-option m --description "Specify max depth for recursive search" --no-files
 
+complete --command f2 --long-option max-size --description "Match files that are not larger than a size" --no-files
+
+complete --command f2 --long-option min-size --description "Match files that are at least a size" --no-files
+
+complete --command f2 --long-option newer-than --description "Match files newer than a date or duration" --no-files
+
 complete --command f2 --long-option no-color --description "Disable coloured output" --no-files
 
+complete --command f2 --long-option no-lock --description "Allow concurrent operations in the same directory" --no-files
+
+complete --command f2 --long-option no-preserve --description "Do not keep the timestamps of copied files" --no-files
+
+set -l normalize_args "
+  nfc\t'Canonical composition'
+  nfd\t'Canonical decomposition'
+  nfkc\t'Compatibility composition'
+  nfkd\t'Compatibility decomposition'
+"
+
+complete --command f2 --long-option normalize --description "Convert targets to a Unicode normalization form" --exclusive --keep-order --arguments $normalize_args
+
+complete --command f2 --long-option number-skip --description "Skip numbers when indexing" --no-files
+
+complete --command f2 --long-option older-than --description "Match files older than a date or duration" --no-files
+
+set -l on_error_args "
+  continue\t'Rename the remaining files'
+  abort\t'Stop at the first failure'
+  rollback\t'Stop and restore the renamed files'
+"
+
+complete --command f2 --long-option on-error --description "Continue, abort, or roll back when a file cannot be renamed" --exclusive --keep-order --arguments $on_error_args
+
+complete --command f2 --long-option one-file-system --description "Stay on the filesystem of the searched directories" --no-files
+
 complete --command f2 --long-option only-dir --short-option D --description "Rename only directories" --no-files
 
+complete --command f2 --long-option owned-by --description "Match only files owned by the specified user" --no-files
+
+complete --command f2 --long-option pad-num --description "Pad numbers in file names with zeros" --no-files
+
 complete --command f2 --long-option pair --short-option p --description "Enable pair renaming" --no-files
 
 complete --command f2 --long-option pair-order --description "Order the paired files" --no-files
 
+complete --command f2 --long-option pcre --description "Use a Perl-compatible regex engine" --no-files
+
+complete --command f2 --long-option preserve-owner --description "Keep the owner of copied files" --no-files
+
+complete --command f2 --long-option print0 --description "Print NUL-delimited paths" --no-files
+
+complete --command f2 --long-option prune-history --description "Prune the history of renaming operations" --no-files
+
 complete --command f2 --long-option quiet --short-option q --description "Disable all output except errors" --no-files
 
 complete --command f2 --long-option recursive --short-option R --description "Search for matches in subdirectories" --no-files
+complete --command f2 --long-option redo --description "Redo the last undone renaming operation" --no-files
 
 complete --command f2 --long-option replace-limit --short-option l --description "Limit the matches to be replaced" --no-files
 
+complete --command f2 --long-option replace-nth --description "Replace only the nth match" --no-files
+
+complete --command f2 --long-option renumber --description "Renumber existing numbers contiguously" --no-files
+
+complete --command f2 --long-option report-conflicts --description "List only the changes with conflicts" --no-files
+
+complete --command f2 --long-option report-duplicates --description "List files in different directories that share a name" --no-files
+
 complete --command f2 --long-option reset-index-per-dir --description "Reset indexes in each directory" --no-files
 
+complete --command f2 --long-option respect-gitignore --description "Skip paths ignored by git" --no-files
+
+complete --command f2 --long-option resume-index --description "Continue from the highest existing index" --no-files
+
+complete --command f2 --long-option retries --description "Retry renames that fail due to transient errors" --no-files
+
 set -l sort_args "
   default\t'Lexicographical order'
   size\t'Sort by file size'
@@ -74,10 +197,62 @@ complete --command f2 --long-option sort-per-dir --description "Apply sort per d
 
 complete --command f2 --long-option sort-var --description "Provide a variable for sorting" --no-files
 
+complete --command f2 --long-option step --description "Increment indexes by the specified step" --no-files
+
 complete --command f2 --long-option string-mode --short-option s --description "Treat the search pattern as a non-regex string" --no-files
 
+complete --command f2 --long-option summary --description "Print a summary of the outcome of the operation" --no-files
+
+set -l symlinks_args "
+  rename\t'Rename the links themselves'
+  skip\t'Skip the links'
+  follow\t'Rename the link targets'
+"
+
+complete --command f2 --long-option symlinks --description "Set how symbolic links are handled" --exclusive --keep-order --arguments $symlinks_args
+
 complete --command f2 --long-option target-dir --short-option t --description "Specify a target directory"
 
+set -l target_os_args "
+  windows\t'Windows naming rules'
+  darwin\t'macOS naming rules'
+  linux\t'Linux naming rules'
+"
+
+complete --command f2 --long-option target-os --description "Validate names against the rules of another OS" --exclusive --keep-order --arguments $target_os_args
+
+complete --command f2 --long-option throttle --description "Limit how fast files are renamed" --no-files
+
+complete --command f2 --long-option trash --description "Move overwritten files to the trash" --no-files
+
+set -l traversal_args "
+  breadth\t'Process directories level by level'
+  depth\t'Process directory contents after the directory'
+  depth-post\t'Process directory contents before the directory'
+"
+
+complete --command f2 --long-option traversal --description "Set the order in which nested matches are processed" --exclusive --keep-order --arguments $traversal_args
+
+complete --command f2 --long-option tui --description "Review the changes in a full-screen view" --no-files
+
+complete --command f2 --long-option two-phase --description "Move all files to temporary names before renaming them" --no-files
+
+complete --command f2 --long-option undo-id --description "Undo an operation in the history by its number or ID" --exclusive
+
+complete --command f2 --long-option undo-last --description "Undo several operations in the current directory" --exclusive
+
 complete --command f2 --long-option verbose --short-option V --description "Enable verbose output" --no-files
 
+complete --command f2 --long-option verify --description "Check the renamed files afterwards" --no-files
+
+complete --command f2 --long-option verify-checksum --description "Also compare the contents of copied files" --no-files
+
+complete --command f2 --long-option watch --description "Rename new files as they appear" --no-files
+
+complete --command f2 --long-option where --description "Filter files by their metadata" --no-files
+
+complete --command f2 --long-option workers --description "Set the number of files renamed at the same time" --no-files
+
+complete --command f2 --long-option writable-only --description "Match only files the current user can write to" --no-files
+
 complete --command f2 --long-option version --short-option v --description "Display version and exit" --no-files
@@ -0,0 +1,412 @@
+// Package tui provides a full-screen view in which the planned changes are
+// reviewed before they are committed. Changes can be deselected and their
+// targets edited, and only the approved changes are renamed
+package tui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"atomicgo.dev/keyboard"
+	"atomicgo.dev/keyboard/keys"
+	"github.com/mattn/go-runewidth"
+	"github.com/pterm/pterm"
+	"golang.org/x/term"
+
+	"github.com/ayoisaiah/f2/v2/internal/apperr"
+	"github.com/ayoisaiah/f2/v2/internal/config"
+	"github.com/ayoisaiah/f2/v2/internal/file"
+	"github.com/ayoisaiah/f2/v2/internal/status"
+	"github.com/ayoisaiah/f2/v2/validate"
+)
+
+var errNotTerminal = &apperr.Error{
+	Message: "--tui requires a terminal",
+}
+
+// The size of the screen when it cannot be determined.
+const (
+	defaultWidth  = 80
+	defaultHeight = 24
+)
+
+// chromeLines is the number of lines around the list of changes, which are
+// the header, the message, and the help line.
+const chromeLines = 3
+
+// model is the state of the review screen.
+type model struct {
+	conf     *config.Config
+	changes  file.Changes
+	selected []bool
+	// input is the target that is being edited
+	input []rune
+	// message is shown above the help line until the next key is pressed
+	message string
+	cursor  int
+	offset  int
+	// inputPos is the position of the cursor in the input
+	inputPos  int
+	height    int
+	width     int
+	editing   bool
+	conflicts bool
+	done      bool
+	execute   bool
+}
+
+// newModel returns the review screen for the changes with all the changes
+// selected except the ones that would be skipped anyway.
+func newModel(conf *config.Config, changes file.Changes) *model {
+	m := &model{
+		conf:     conf,
+		changes:  changes,
+		selected: make([]bool, len(changes)),
+		width:    defaultWidth,
+		height:   defaultHeight,
+	}
+
+	for i, ch := range changes {
+		m.selected[i] = ch.Status != status.Ignored
+	}
+
+	m.validate()
+
+	return m
+}
+
+// approved returns the selected changes.
+func (m *model) approved() file.Changes {
+	var approved file.Changes
+
+	for i, ch := range m.changes {
+		if m.selected[i] {
+			approved = append(approved, ch)
+		}
+	}
+
+	return approved
+}
+
+// validate checks the selected changes for conflicts again after they were
+// toggled or edited. The changes that are not selected stay in place, so a
+// selected change cannot take their paths.
+func (m *model) validate() {
+	approved := m.approved()
+
+	for _, ch := range approved {
+		ch.Status = status.OK
+		ch.WillOverwrite = false
+	}
+
+	m.conflicts = validate.Validate(
+		approved,
+		m.conf.AutoFixConflicts,
+		m.conf.AllowOverwrites,
+	)
+}
+
+// rows returns the number of changes that fit on the screen.
+func (m *model) rows() int {
+	return max(1, m.height-chromeLines)
+}
+
+// move moves the cursor by the number of changes and scrolls the list so
+// that the cursor stays on the screen.
+func (m *model) move(n int) {
+	m.cursor = max(0, min(len(m.changes)-1, m.cursor+n))
+
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+
+	if m.cursor >= m.offset+m.rows() {
+		m.offset = m.cursor - m.rows() + 1
+	}
+}
+
+// toggleAll selects all the changes, or deselects them if they are all
+// selected.
+func (m *model) toggleAll() {
+	all := true
+
+	for _, ok := range m.selected {
+		all = all && ok
+	}
+
+	for i := range m.selected {
+		m.selected[i] = !all
+	}
+
+	m.validate()
+}
+
+// update changes the state according to the key that was pressed.
+func (m *model) update(key keys.Key) {
+	m.message = ""
+
+	if m.editing {
+		m.edit(key)
+		return
+	}
+
+	switch key.Code {
+	case keys.Up:
+		m.move(-1)
+	case keys.Down:
+		m.move(1)
+	case keys.PgUp:
+		m.move(-m.rows())
+	case keys.PgDown:
+		m.move(m.rows())
+	case keys.Home:
+		m.move(-len(m.changes))
+	case keys.End:
+		m.move(len(m.changes))
+	case keys.Space:
+		m.selected[m.cursor] = !m.selected[m.cursor]
+		m.validate()
+	case keys.Enter:
+		m.startEditing()
+	case keys.Escape, keys.CtrlC:
+		m.done = true
+	case keys.RuneKey:
+		m.command(key.String())
+	default:
+	}
+}
+
+// command handles the keys that are letters.
+func (m *model) command(key string) {
+	switch key {
+	case "k":
+		m.move(-1)
+	case "j":
+		m.move(1)
+	case "g":
+		m.move(-len(m.changes))
+	case "G":
+		m.move(len(m.changes))
+	case "a":
+		m.toggleAll()
+	case "e":
+		m.startEditing()
+	case "q":
+		m.done = true
+	case "x":
+		switch {
+		case m.conflicts:
+			m.message = "resolve the conflicts before renaming"
+		case len(m.approved()) == 0:
+			m.message = "no changes are selected"
+		default:
+			m.done, m.execute = true, true
+		}
+	}
+}
+
+// startEditing starts editing the target of the change under the cursor.
+func (m *model) startEditing() {
+	if len(m.changes) == 0 {
+		return
+	}
+
+	m.editing = true
+	m.input = []rune(m.changes[m.cursor].Target)
+	m.inputPos = len(m.input)
+}
+
+// edit handles a key while the target is being edited. The new target is
+// applied with enter and discarded with escape.
+func (m *model) edit(key keys.Key) {
+	switch key.Code {
+	case keys.Enter:
+		m.editing = false
+		m.changes[m.cursor].AutoFixTarget(string(m.input))
+		m.selected[m.cursor] = true
+		m.validate()
+	case keys.Escape, keys.CtrlC:
+		m.editing = false
+	case keys.Left:
+		m.inputPos = max(0, m.inputPos-1)
+	case keys.Right:
+		m.inputPos = min(len(m.input), m.inputPos+1)
+	case keys.Home, keys.CtrlA:
+		m.inputPos = 0
+	case keys.End, keys.CtrlE:
+		m.inputPos = len(m.input)
+	case keys.Backspace, keys.CtrlH:
+		if m.inputPos > 0 {
+			m.input = append(m.input[:m.inputPos-1], m.input[m.inputPos:]...)
+			m.inputPos--
+		}
+	case keys.Delete:
+		if m.inputPos < len(m.input) {
+			m.input = append(m.input[:m.inputPos], m.input[m.inputPos+1:]...)
+		}
+	case keys.RuneKey, keys.Space:
+		r := key.Runes
+		if key.Code == keys.Space {
+			r = []rune{' '}
+		}
+
+		tail := append(slices.Clone(r), m.input[m.inputPos:]...)
+		m.input = append(m.input[:m.inputPos], tail...)
+		m.inputPos += len(r)
+	default:
+	}
+}
+
+// statusText returns the status of the change as it is shown in the list.
+func statusText(ch *file.Change, selected bool) string {
+	if !selected {
+		return pterm.Gray("skipped")
+	}
+
+	//nolint:exhaustive // default case covers other statuses
+	switch ch.Status {
+	case status.OK:
+		return pterm.Green(ch.Status)
+	case status.Unchanged, status.Overwriting, status.Ignored:
+		return pterm.Yellow(ch.Status)
+	default:
+		return pterm.Red(ch.Status)
+	}
+}
+
+// view returns the contents of the screen.
+func (m *model) view() string {
+	lines := make([]string, 0, m.height)
+
+	lines = append(lines, pterm.Sprintf(
+		"%s %d of %d changes selected",
+		pterm.Green("review:"),
+		len(m.approved()),
+		len(m.changes),
+	))
+
+	end := min(len(m.changes), m.offset+m.rows())
+
+	for i := m.offset; i < end; i++ {
+		ch := m.changes[i]
+
+		marker := " "
+		if i == m.cursor {
+			marker = ">"
+		}
+
+		check := "[ ]"
+		if m.selected[i] {
+			check = "[x]"
+		}
+
+		target := ch.TargetPath
+		if m.editing && i == m.cursor {
+			target = string(m.input[:m.inputPos]) + "_" +
+				string(m.input[m.inputPos:])
+
+			if ch.TargetDir != "" && ch.TargetDir != "." {
+				target = ch.TargetDir + string(filepath.Separator) + target
+			}
+		}
+
+		statusCol := statusText(ch, m.selected[i])
+
+		line := fmt.Sprintf("%s %s %s -> %s", marker, check, ch.SourcePath, target)
+		line = runewidth.Truncate(
+			line,
+			max(0, m.width-runewidth.StringWidth(pterm.RemoveColorFromString(statusCol))-2),
+			"…",
+		)
+
+		lines = append(lines, line+"  "+statusCol)
+	}
+
+	for len(lines) < m.height-chromeLines+1 {
+		lines = append(lines, "")
+	}
+
+	message := m.message
+	if message == "" && m.conflicts {
+		message = "some changes conflict: edit or deselect them"
+	}
+
+	lines = append(lines, pterm.Red(message))
+
+	help := "↑/↓ move  space select  a select all  e edit  x rename  q quit"
+	if m.editing {
+		help = "enter apply  esc cancel  ←/→ move"
+	}
+
+	lines = append(lines, pterm.Gray(help))
+
+	return strings.Join(lines, "\r\n")
+}
+
+// resize updates the size of the screen.
+func (m *model) resize(fd int) {
+	width, height, err := term.GetSize(fd)
+	if err != nil || width <= 0 || height <= 0 {
+		return
+	}
+
+	m.width, m.height = width, height
+
+	m.move(0)
+}
+
+// render draws the screen over the previous one.
+func render(w io.Writer, m *model) {
+	_, _ = fmt.Fprint(w, "\033[H\033[2J"+m.view())
+}
+
+// Review shows the changes in a full-screen view where they can be selected
+// and their targets edited (--tui). It returns the approved changes, and
+// whether they should be renamed.
+func Review(
+	conf *config.Config,
+	changes file.Changes,
+) (file.Changes, bool, error) {
+	out := os.Stdout
+	fd := int(out.Fd())
+
+	if !config.IsATTY(out.Fd()) {
+		return nil, false, errNotTerminal
+	}
+
+	m := newModel(conf, changes)
+	m.resize(fd)
+
+	// Use the alternate screen so that the terminal is left as it was
+	_, _ = fmt.Fprint(out, "\033[?1049h\033[?25l")
+
+	defer fmt.Fprint(out, "\033[?25h\033[?1049l")
+
+	render(out, m)
+
+	err := keyboard.Listen(func(key keys.Key) (bool, error) {
+		m.update(key)
+		m.resize(fd)
+
+		if m.done {
+			return true, nil
+		}
+
+		render(out, m)
+
+		return false, nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	if !m.execute {
+		return nil, false, nil
+	}
+
+	return m.approved(), true, nil
+}
@@ -0,0 +1,114 @@
+package tui
+
+import (
+	"os"
+	"testing"
+
+	"atomicgo.dev/keyboard/keys"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ayoisaiah/f2/v2/internal/file"
+	"github.com/ayoisaiah/f2/v2/internal/status"
+	"github.com/ayoisaiah/f2/v2/internal/testutil"
+)
+
+func runeKey(s string) keys.Key {
+	return keys.Key{Code: keys.RuneKey, Runes: []rune(s)}
+}
+
+func press(m *model, input ...keys.Key) {
+	for _, key := range input {
+		m.update(key)
+	}
+}
+
+func TestReview(t *testing.T) {
+	workingDir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testDir := t.TempDir()
+
+	err = os.Chdir(testDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		_ = os.Chdir(workingDir)
+	})
+
+	for _, name := range []string{"a.txt", "b.txt"} {
+		err = os.WriteFile(name, nil, 0o600)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	conf := testutil.GetConfig(t, &testutil.TestCase{}, testDir)
+
+	changes := file.Changes{
+		{Source: "a.txt", Target: "c.txt", SourcePath: "a.txt", TargetPath: "c.txt"},
+		{Source: "b.txt", Target: "c.txt", SourcePath: "b.txt", TargetPath: "c.txt"},
+	}
+
+	m := newModel(conf, changes)
+
+	assert.True(t, m.conflicts)
+
+	press(m, runeKey("x"))
+
+	assert.False(t, m.done, "changes with conflicts must not be renamed")
+
+	// Deselecting the second change resolves the conflict
+	press(m, keys.Key{Code: keys.Down}, keys.Key{Code: keys.Space})
+
+	assert.False(t, m.conflicts)
+	assert.Len(t, m.approved(), 1)
+
+	// Editing the first change lets the second one be selected again
+	press(m, keys.Key{Code: keys.Up}, runeKey("e"))
+
+	for range "c.txt" {
+		press(m, keys.Key{Code: keys.Backspace})
+	}
+
+	press(m, runeKey("d.txt"), keys.Key{Code: keys.Enter})
+
+	assert.Equal(t, "d.txt", changes[0].TargetPath)
+
+	press(m, keys.Key{Code: keys.Down}, keys.Key{Code: keys.Space})
+
+	assert.False(t, m.conflicts)
+	assert.Equal(t, status.OK, changes[1].Status)
+
+	// Deselecting everything leaves nothing to rename
+	press(m, runeKey("a"), runeKey("x"))
+
+	assert.Empty(t, m.approved())
+	assert.False(t, m.done)
+
+	press(m, runeKey("a"), runeKey("x"))
+
+	assert.True(t, m.done)
+	assert.True(t, m.execute)
+	assert.Len(t, m.approved(), 2)
+}
+
+func TestReviewQuit(t *testing.T) {
+	conf := testutil.GetConfig(t, &testutil.TestCase{}, t.TempDir())
+
+	m := newModel(conf, file.Changes{
+		{Source: "a.txt", Target: "b.txt", SourcePath: "a.txt", TargetPath: "b.txt"},
+	})
+
+	press(m, runeKey("e"), keys.Key{Code: keys.Escape})
+
+	assert.False(t, m.done, "escape must only cancel editing")
+
+	press(m, runeKey("q"))
+
+	assert.True(t, m.done)
+	assert.False(t, m.execute)
+}
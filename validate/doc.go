@@ -7,8 +7,27 @@
 // 3. Target destination already exists on the file system (except if
 // --allow-overwrite is specified)
 // 4. Target name exceeds the maximum allowed length (255 characters in windows, and 255 bytes on Linux and macOS).
-// 5. Target destination contains trailing periods in any of the sub paths (Windows only).
+// 5. Target destination contains trailing periods or spaces in any of the sub paths (Windows only).
 // 6. Target destination is empty.
+// 7. Target destination uses a reserved device name such as CON or NUL in any of
+// the sub paths (Windows only).
+// 8. Full target path exceeds the maximum allowed length (260 characters in
+// Windows unless --long-paths is used, 1024 bytes on macOS, and 4096 bytes on
+// Linux).
+// 9. The source cannot be renamed by the current user because its directory is
+// not writable (or because it is read-only or locked in Windows).
+//
+// A target that exists is not reported as a conflict if it is renamed by
+// another change (such as 1.txt to 2.txt and 2.txt to 3.txt) since the changes
+// are committed in an order that frees each target first. Changes that form a
+// rename cycle (such as a.txt to b.txt and b.txt to a.txt) are renamed through
+// a temporary path.
+//
+// Once the individual changes are free of conflicts, the whole plan is applied
+// to a virtual copy of the file system in the order in which it is committed.
+// This reports targets that only collide because of that order, such as a
+// target inside a directory that is moved earlier, or a target whose parent
+// directory is occupied by a file that another change creates.
 //
 // It detects each conflicts and reports them, but it can also automatically fix
 // them according to predefined rules (if -F/--fix-conflicts is specified).
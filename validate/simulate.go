@@ -0,0 +1,241 @@
+package validate
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ayoisaiah/f2/v2/internal/file"
+	"github.com/ayoisaiah/f2/v2/internal/osutil"
+	"github.com/ayoisaiah/f2/v2/internal/status"
+)
+
+// entry is the state of a path in the simulation.
+type entry struct {
+	exists bool
+	isDir  bool
+}
+
+// move is a directory that was renamed in the simulation.
+type move struct {
+	from, to string
+}
+
+// collision is a change whose target cannot be created at the time it is
+// renamed.
+type collision struct {
+	index int
+	// blocked reports whether a parent directory of the target is occupied
+	// by a file
+	blocked bool
+}
+
+// simulation is a virtual snapshot of the filesystem that the changes are
+// applied to in the order in which they are committed. Only the paths that
+// are affected by the changes are recorded, while the state of every other
+// path is read from the filesystem.
+type simulation struct {
+	entries map[string]entry
+	// paths holds the original form of each recorded path so that the
+	// entries under a moved directory can be found
+	paths map[string]string
+	moves []move
+}
+
+// isUnder reports whether the path is inside the directory and returns the
+// path relative to it.
+func isUnder(path, dir string) (string, bool) {
+	rel, found := strings.CutPrefix(seenPathKey(path), seenPathKey(dir)+"/")
+	if !found {
+		return "", false
+	}
+
+	// preserve the case of the relative path
+	return path[len(path)-len(rel):], true
+}
+
+// lookup returns the state of the path after the changes simulated so far.
+func (s *simulation) lookup(path string) entry {
+	path = filepath.ToSlash(path)
+
+	if e, ok := s.entries[seenPathKey(path)]; ok {
+		return e
+	}
+
+	// Resolve the directories that were moved, starting with the latest
+	for i := len(s.moves) - 1; i >= 0; i-- {
+		m := s.moves[i]
+
+		if rel, ok := isUnder(path, m.to); ok {
+			path = m.from + "/" + rel
+			continue
+		}
+
+		if _, ok := isUnder(path, m.from); ok {
+			return entry{}
+		}
+	}
+
+	info, err := os.Lstat(filepath.FromSlash(path))
+	if err != nil {
+		return entry{}
+	}
+
+	return entry{exists: true, isDir: info.IsDir()}
+}
+
+// set records the state of the path.
+func (s *simulation) set(path string, e entry) {
+	path = filepath.ToSlash(path)
+	key := seenPathKey(path)
+
+	s.entries[key] = e
+	s.paths[key] = path
+}
+
+// mkdirAll records the missing parent directories of the path as created. It
+// reports false if one of them is occupied by a file.
+func (s *simulation) mkdirAll(path string) bool {
+	dir := filepath.Dir(path)
+	if dir == path || dir == "." || dir == string(filepath.Separator) {
+		return true
+	}
+
+	e := s.lookup(dir)
+	if e.exists {
+		return e.isDir
+	}
+
+	if !s.mkdirAll(dir) {
+		return false
+	}
+
+	s.set(dir, entry{exists: true, isDir: true})
+
+	return true
+}
+
+// rename moves the source to the target. The recorded paths under a source
+// directory are moved along with it.
+func (s *simulation) rename(source, target string) {
+	e := s.lookup(source)
+
+	s.set(source, entry{})
+
+	if e.isDir {
+		from, to := filepath.ToSlash(source), filepath.ToSlash(target)
+
+		for key, path := range s.paths {
+			rel, ok := isUnder(path, from)
+			if !ok {
+				continue
+			}
+
+			moved := s.entries[key]
+
+			delete(s.entries, key)
+			delete(s.paths, key)
+
+			s.set(to+"/"+rel, moved)
+		}
+
+		s.moves = append(s.moves, move{from: from, to: to})
+	}
+
+	s.set(target, e)
+}
+
+// simulate applies the changes to a virtual snapshot of the filesystem in the
+// order in which they are committed, including the temporary paths used for
+// rename cycles and the directories that are created for the targets. It
+// returns the changes whose target is occupied at the time it is renamed to.
+// This catches collisions that depend on the order of the changes, which the
+// checks on individual changes cannot detect.
+func simulate(changes file.Changes) []collision {
+	s := &simulation{
+		entries: make(map[string]entry),
+		paths:   make(map[string]string),
+	}
+
+	order := changes.CommitOrder()
+
+	// Copies and links are made in order since their sources stay in place
+	if keepsSources() {
+		order = make([]int, len(changes))
+		for i := range order {
+			order[i] = i
+		}
+	}
+
+	// The first change in a rename cycle appears twice in the order
+	repeated := make(map[int]bool)
+	seen := make(map[int]bool, len(order))
+
+	for _, i := range order {
+		repeated[i] = seen[i]
+		seen[i] = true
+	}
+
+	tempPaths := make(map[int]string)
+
+	var collisions []collision
+
+	for _, i := range order {
+		ch := changes[i]
+
+		if ch.Status == status.Ignored ||
+			ch.Status == status.SourceNotFound ||
+			ch.SourcePath == ch.TargetPath {
+			continue
+		}
+
+		if ch.Remove {
+			s.set(ch.SourcePath, entry{})
+			continue
+		}
+
+		source := ch.SourcePath
+
+		if tmp, ok := tempPaths[i]; ok {
+			source = tmp
+		} else if repeated[i] {
+			// It is moved to a temporary path that cannot clash with any
+			// real path before the rest of the cycle is renamed
+			tmp := source + "\x00"
+			s.rename(source, tmp)
+			tempPaths[i] = tmp
+
+			continue
+		}
+
+		if !s.lookup(source).exists {
+			continue
+		}
+
+		// A target that differs only in case may be the source itself on a
+		// case-insensitive filesystem that is not known to be one
+		occupied := s.lookup(ch.TargetPath).exists &&
+			seenPathKey(source) != seenPathKey(ch.TargetPath) &&
+			!osutil.IsCaseChange(source, ch.TargetPath) &&
+			!ch.WillOverwrite
+
+		if occupied {
+			collisions = append(collisions, collision{index: i})
+			continue
+		}
+
+		if !s.mkdirAll(ch.TargetPath) {
+			collisions = append(collisions, collision{index: i, blocked: true})
+			continue
+		}
+
+		if keepsSources() {
+			s.set(ch.TargetPath, s.lookup(source))
+			continue
+		}
+
+		s.rename(source, ch.TargetPath)
+	}
+
+	return collisions
+}
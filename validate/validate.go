@@ -9,40 +9,112 @@ import (
 	"runtime"
 	"strconv"
 	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
 
 	"github.com/ayoisaiah/f2/v2/internal/config"
 	"github.com/ayoisaiah/f2/v2/internal/file"
 	"github.com/ayoisaiah/f2/v2/internal/osutil"
 	"github.com/ayoisaiah/f2/v2/internal/pathutil"
 	"github.com/ayoisaiah/f2/v2/internal/status"
+	"github.com/ayoisaiah/f2/v2/report"
 )
 
 type validationCtx struct {
 	change          *file.Change
 	seenPaths       map[string]int
+	emptyCount      *int
 	changeIndex     int
 	autoFix         bool
 	allowOverwrites bool
 }
 
 func (ctx validationCtx) updateSeenPaths() {
-	if _, ok := ctx.seenPaths[ctx.change.TargetPath]; !ok {
-		ctx.seenPaths[ctx.change.TargetPath] = ctx.changeIndex
+	key := seenPathKey(ctx.change.TargetPath)
+
+	if _, ok := ctx.seenPaths[key]; !ok {
+		ctx.seenPaths[key] = ctx.changeIndex
 	}
 }
 
+// isCaseInsensitiveFS reports whether paths that differ only in case refer to
+// the same file on the target filesystem.
+func isCaseInsensitiveFS() bool {
+	goos := targetOS()
+
+	return config.Get().CaseInsensitiveFS ||
+		goos == osutil.Windows ||
+		goos == osutil.Darwin
+}
+
+// keepsSources reports whether the matched files are copied or linked to
+// their targets instead of renamed, in which case the sources stay in place.
+func keepsSources() bool {
+	return config.KeepsSources(config.Get().Operation)
+}
+
+// seenPathKey returns the key under which a path is recorded in the seen
+// paths so that paths which refer to the same file share a key. Paths that
+// are identical after Unicode normalization (such as an NFD name from macOS
+// and the same name typed in NFC) share a key on every OS.
+func seenPathKey(path string) string {
+	path = norm.NFC.String(path)
+
+	if isCaseInsensitiveFS() {
+		return strings.ToLower(path)
+	}
+
+	return path
+}
+
 var changes file.Changes
 
+// windowsReservedNameRegex matches the device names that cannot be used as
+// file names in Windows regardless of the extension.
+var windowsReservedNameRegex = regexp.MustCompile(
+	`(?i)^(CON|PRN|AUX|NUL|COM[1-9]|LPT[1-9])(\.|$)`,
+)
+
 const (
 	// max filename length of 255 characters in Windows.
 	windowsMaxFileCharLength = 255
 	// max filename length of 255 bytes on Linux and other unix-based OSes.
 	unixMaxBytes = 255
+	// max path length of 260 characters (MAX_PATH) in Windows including the
+	// terminating null character.
+	windowsMaxPathCharLength = 259
+	// max path length of 32,767 characters in Windows when long paths are
+	// allowed through the \\?\ prefix.
+	windowsMaxLongPathCharLength = 32767
+	// max path length of 1024 bytes (PATH_MAX) on macOS including the
+	// terminating null character.
+	darwinMaxPathBytes = 1023
+	// max path length of 4096 bytes (PATH_MAX) on Linux including the
+	// terminating null character.
+	linuxMaxPathBytes = 4095
 )
 
+// conflictPatternRegex returns the regular expression that matches the number
+// appended to a file name by newTarget according to the conflict pattern.
+func conflictPatternRegex() *regexp.Regexp {
+	conf := config.Get()
+
+	if conf.FixConflictsPatternRegex != nil {
+		return conf.FixConflictsPatternRegex
+	}
+
+	r := regexp.MustCompile(`%(\d+)?d`)
+
+	return regexp.MustCompile(
+		r.ReplaceAllString(conf.FixConflictsPattern, `(\d+)`),
+	)
+}
+
 // newTarget appends a number to the target file name so that it
 // does not conflict with an existing path on the filesystem or
 // another renamed file. For example: image.png becomes image(1).png.
+// The numbers skipped by --number-skip are not used.
 func newTarget(change *file.Change) string {
 	conf := config.Get()
 
@@ -53,14 +125,7 @@ func newTarget(change *file.Change) string {
 		baseName = pathutil.StripExtension(baseName)
 	}
 
-	regex := conf.FixConflictsPatternRegex
-
-	if regex == nil {
-		r := regexp.MustCompile(`%(\d+)?d`)
-		regex = regexp.MustCompile(
-			r.ReplaceAllString(conf.FixConflictsPattern, `(\d+)`),
-		)
-	}
+	regex := conflictPatternRegex()
 
 	// Extract the numbered index at the end of the filename (if any)
 	match := regex.FindStringSubmatch(baseName)
@@ -69,11 +134,19 @@ func newTarget(change *file.Change) string {
 		num, _ := strconv.Atoi(match[1])
 		num += counter
 
+		for conf.SkipsNumber(num) {
+			num++
+		}
+
 		baseName = regex.ReplaceAllString(
 			baseName,
 			fmt.Sprintf(conf.FixConflictsPattern, num),
 		)
 	} else {
+		for conf.SkipsNumber(counter) {
+			counter++
+		}
+
 		baseName += fmt.Sprintf(conf.FixConflictsPattern, counter)
 	}
 
@@ -100,22 +173,48 @@ func checkSourceNotFoundConflict(
 }
 
 // checkEmptyFilenameConflict reports if the file renaming has resulted
-// in an empty string. This conflict is automatically fixed by leaving
-// the filename unchanged.
+// in an empty string. This conflict is automatically fixed according to
+// --fix-empty: by leaving the filename unchanged (the default), by skipping
+// the file with a warning, or by naming the file after a template.
 func checkEmptyFilenameConflict(
 	ctx validationCtx,
 ) (conflictDetected bool) {
-	if ctx.change.Target == "." || ctx.change.Target == "" {
-		conflictDetected = true
+	if ctx.change.Target != "." && ctx.change.Target != "" {
+		return
+	}
 
-		ctx.change.AutoFixTarget("")
-		ctx.change.Status = status.EmptyFilename
+	conflictDetected = true
 
-		if ctx.autoFix {
-			// The file is left unchanged
-			ctx.change.AutoFixTarget(ctx.change.Source)
-			ctx.change.Status = status.Unchanged
+	ctx.change.AutoFixTarget("")
+	ctx.change.Status = status.EmptyFilename
+
+	if !ctx.autoFix {
+		return
+	}
+
+	conf := config.Get()
+
+	switch conf.FixEmpty {
+	case config.FixEmptySkip:
+		ctx.change.AutoFixTarget(ctx.change.Source)
+		ctx.change.Status = status.Ignored
+
+		if !conf.Quiet {
+			report.EmptyFilenameSkipped(ctx.change.SourcePath)
+		}
+	case config.FixEmptyKeep, "":
+		// The file is left unchanged
+		ctx.change.AutoFixTarget(ctx.change.Source)
+		ctx.change.Status = status.Unchanged
+	default:
+		*ctx.emptyCount++
+
+		target := fmt.Sprintf(conf.FixEmpty, *ctx.emptyCount)
+		if !ctx.change.IsDir {
+			target += filepath.Ext(ctx.change.Source)
 		}
+
+		ctx.change.AutoFixTarget(target)
 	}
 
 	return
@@ -136,14 +235,29 @@ func checkPathExistsConflict(
 		}
 
 		// Case-insensitive filesystems should not report conflicts
-		// if only the case of the filename is being changed.
-		if strings.EqualFold(
+		// if only the case of the filename is being changed. A copy or link
+		// cannot be made there since the target is the source itself. The
+		// target is a different file on case-sensitive filesystems.
+		if osutil.IsCaseChange(
 			ctx.change.SourcePath,
 			ctx.change.TargetPath,
-		) {
+		) && !keepsSources() {
 			return
 		}
 
+		// Don't report a conflict if the target is renamed by another change
+		// since the changes are committed in an order that frees each target
+		// before it is renamed to (see file.Changes.CommitOrder). Copied or
+		// linked sources stay in place so they do not free their paths.
+		for _, ch := range changes {
+			if ctx.change.TargetPath == ch.SourcePath &&
+				ch.Status != status.Ignored &&
+				(ch.Remove || !keepsSources()) &&
+				!strings.EqualFold(ch.SourcePath, ch.TargetPath) {
+				return
+			}
+		}
+
 		// Don't report a conflict if overwriting files are allowed
 		if ctx.allowOverwrites {
 			ctx.change.WillOverwrite = true
@@ -152,18 +266,6 @@ func checkPathExistsConflict(
 			return
 		}
 
-		// Don't report a conflict if target path is changing before
-		// the source path is renamed
-		for i := 0; i < len(changes); i++ {
-			ch := changes[i]
-
-			if ctx.change.TargetPath == ch.SourcePath &&
-				!strings.EqualFold(ch.SourcePath, ch.TargetPath) &&
-				ctx.changeIndex > i {
-				return
-			}
-		}
-
 		conflictDetected = true
 		ctx.change.Status = status.PathExists
 
@@ -175,14 +277,21 @@ func checkPathExistsConflict(
 	return conflictDetected
 }
 
-// checkTargetFileChangingConflict ensures that renaming a file to a target that
-// is changing later is detected to prevent data loss. It is automatically fixed
-// by swapping the items around so that any renaming targets do not change later.
+// checkTargetFileChangingConflict ensures that renaming a file to the path of
+// a file that is not renamed is detected to prevent data loss. A path that is
+// renamed by another change is not a conflict since the changes are committed
+// in an order that frees each target before it is renamed to. It is
+// automatically fixed by swapping the items around so that the path of the
+// file that is not renamed is seen first.
 func checkTargetFileChangingConflict(
 	ctx validationCtx,
 ) (conflictDetected bool) {
-	seenIndex, ok := ctx.seenPaths[ctx.change.SourcePath]
-	if !ok {
+	if !strings.EqualFold(ctx.change.SourcePath, ctx.change.TargetPath) {
+		return
+	}
+
+	seenIndex, ok := ctx.seenPaths[seenPathKey(ctx.change.SourcePath)]
+	if !ok || seenIndex == ctx.changeIndex {
 		return
 	}
 
@@ -203,7 +312,13 @@ func checkTargetFileChangingConflict(
 func checkOverwritingPathConflict(
 	ctx validationCtx,
 ) (conflictDetected bool) {
-	if _, ok := ctx.seenPaths[ctx.change.TargetPath]; ok {
+	// A file that keeps its path conflicts with the change that renames
+	// another file to that path (see checkTargetFileChangingConflict)
+	if ctx.change.SourcePath == ctx.change.TargetPath {
+		return
+	}
+
+	if _, ok := ctx.seenPaths[seenPathKey(ctx.change.TargetPath)]; ok {
 		conflictDetected = true
 		ctx.change.Status = status.OverwritingNewPath
 	}
@@ -219,67 +334,177 @@ func checkOverwritingPathConflict(
 	return
 }
 
+// targetOS returns the operating system whose naming rules apply to the
+// renamed paths. This is the current OS unless --target-os is set.
+func targetOS() string {
+	if goos := config.Get().TargetOS; goos != "" {
+		return goos
+	}
+
+	return runtime.GOOS
+}
+
+// forbiddenCharRegex returns the regular expression that matches the
+// characters that are forbidden in file names on the provided OS. Forward and
+// backward slashes are excluded as they are used for auto creating
+// directories.
+func forbiddenCharRegex(goos string) *regexp.Regexp {
+	switch goos {
+	case osutil.Windows:
+		return osutil.PartialWindowsForbiddenCharRegex
+	case osutil.Darwin:
+		return osutil.MacForbiddenCharRegex
+	default:
+		return osutil.UnixForbiddenCharRegex
+	}
+}
+
 // checkForbiddenCharacters is responsible for ensuring that target file names
-// do not contain forbidden characters for the current OS.
+// do not contain forbidden characters for the target OS.
 func checkForbiddenCharacters(path string) string {
-	if runtime.GOOS == osutil.Windows {
-		// partialWindowsForbiddenCharRegex is used here as forward and backward
-		// slashes are used for auto creating directories
-		if osutil.PartialWindowsForbiddenCharRegex.MatchString(path) {
-			return strings.Join(
-				osutil.PartialWindowsForbiddenCharRegex.FindAllString(
-					path,
-					-1,
-				),
-				",",
-			)
-		}
+	return strings.Join(
+		forbiddenCharRegex(targetOS()).FindAllString(path, -1),
+		",",
+	)
+}
+
+// maxNameLength returns the maximum length of the file name at the target
+// path along with a function that measures names in the same unit. The limit
+// is 255 characters on Windows, and 255 bytes on Linux and other unix-based
+// OSes unless the filesystem reports a different limit.
+func maxNameLength(targetPath string) (maxLength int, length func(string) int) {
+	if targetOS() == osutil.Windows {
+		return windowsMaxFileCharLength, utf8.RuneCountInString
 	}
 
-	if runtime.GOOS == osutil.Darwin {
-		if strings.Contains(path, ":") {
-			return ":"
-		}
+	maxLength = unixMaxBytes
+
+	if fsMax, ok := fsMaxNameBytes(targetPath); ok {
+		maxLength = fsMax
 	}
 
-	return ""
+	return maxLength, func(s string) int {
+		return len(s)
+	}
 }
 
 // isTargetLengthExceeded is responsible for ensuring that the target name length
 // does not exceed the maximum value on each supported rating system.
-func isTargetLengthExceeded(target string) bool {
-	// Get the standalone filename
-	filename := filepath.Base(target)
+func isTargetLengthExceeded(change *file.Change) bool {
+	maxLength, length := maxNameLength(change.TargetPath)
 
-	// max length of 255 characters in windows
-	if runtime.GOOS == osutil.Windows &&
-		len([]rune(filename)) > windowsMaxFileCharLength {
-		return true
+	return length(filepath.Base(change.Target)) > maxLength
+}
+
+// maxPathLength returns the maximum length of a full path on the target OS
+// along with a function that measures paths in the same unit.
+func maxPathLength() (maxLength int, length func(string) int) {
+	switch targetOS() {
+	case osutil.Windows:
+		if config.Get().LongPaths {
+			return windowsMaxLongPathCharLength, utf8.RuneCountInString
+		}
+
+		return windowsMaxPathCharLength, utf8.RuneCountInString
+	case osutil.Darwin:
+		maxLength = darwinMaxPathBytes
+	default:
+		maxLength = linuxMaxPathBytes
+	}
+
+	return maxLength, func(s string) int {
+		return len(s)
+	}
+}
+
+// pathLengthExcess returns how much the full target path is longer than the
+// limit of the target OS. It is zero or less if the path is within the limit.
+func pathLengthExcess(change *file.Change) int {
+	path, err := filepath.Abs(change.TargetPath)
+	if err != nil {
+		path = change.TargetPath
+	}
+
+	maxLength, length := maxPathLength()
+
+	return length(path) - maxLength
+}
+
+// counterSuffix returns the number appended to the end of a file name by
+// newTarget, or an empty string if there is none.
+func counterSuffix(name string) string {
+	matches := conflictPatternRegex().FindAllStringIndex(name, -1)
+	if len(matches) == 0 {
+		return ""
+	}
+
+	last := matches[len(matches)-1]
+	if last[1] != len(name) {
+		return ""
+	}
+
+	return name[last[0]:]
+}
+
+// truncateName shortens a file name so that it does not exceed the maximum
+// length while preserving its extension and counter suffix (if any).
+func truncateName(
+	name string,
+	isDir bool,
+	maxLength int,
+	length func(string) int,
+) string {
+	stem, ext := name, ""
+	if !isDir {
+		stem = pathutil.StripExtension(name)
+		ext = filepath.Ext(name)
+	}
+
+	suffix := counterSuffix(stem)
+	stem = strings.TrimSuffix(stem, suffix)
+
+	// Nothing is preserved if there's no room left for the rest of the name
+	if length(suffix+ext) >= maxLength {
+		stem, suffix, ext = name, "", ""
 	}
 
-	if runtime.GOOS != osutil.Windows &&
-		len([]byte(filename)) > unixMaxBytes {
-		// max length of 255 bytes on Linux and other unix-based OSes
-		return true
+	for length(stem) > maxLength-length(suffix+ext) {
+		_, size := utf8.DecodeLastRuneInString(stem)
+		stem = stem[:len(stem)-size]
 	}
 
-	return false
+	return stem + suffix + ext
+}
+
+// trailingChars are the characters that file names and directories cannot end
+// with in Windows.
+const trailingChars = ". "
+
+// hasTrailingChars reports whether a path component ends in a period or space.
+// The relative path components "." and ".." are exempt.
+func hasTrailingChars(component string) bool {
+	if component == "." || component == ".." {
+		return false
+	}
+
+	return component != strings.TrimRight(component, trailingChars)
 }
 
 // checkTrailingPeriodConflictInWindows reports if the file renaming has
-// resulted in files or sub directories that end in trailing dots.
-// This conflict is automatically resolved by removing the trailing periods.
+// resulted in files or sub directories that end in trailing dots or spaces.
+// This conflict is automatically resolved by removing the trailing periods
+// and spaces.
 func checkTrailingPeriodConflictInWindows(
 	ctx validationCtx,
 ) (conflictDetected bool) {
-	if runtime.GOOS == osutil.Windows {
+	if targetOS() == osutil.Windows {
 		pathComponents := strings.Split(
-			ctx.change.TargetPath,
-			string(os.PathSeparator),
+			filepath.ToSlash(ctx.change.Target),
+			"/",
 		)
 
 		for _, v := range pathComponents {
-			if v != strings.TrimRight(v, ".") {
+			if hasTrailingChars(v) {
 				conflictDetected = true
 
 				break
@@ -292,14 +517,12 @@ func checkTrailingPeriodConflictInWindows(
 
 		if ctx.autoFix && conflictDetected {
 			for j, v := range pathComponents {
-				s := strings.TrimRight(v, ".")
-				pathComponents[j] = s
+				if hasTrailingChars(v) {
+					pathComponents[j] = strings.TrimRight(v, trailingChars)
+				}
 			}
 
-			ctx.change.AutoFixTarget(strings.Join(
-				pathComponents,
-				string(os.PathSeparator),
-			))
+			ctx.change.AutoFixTarget(strings.Join(pathComponents, "/"))
 
 			return
 		}
@@ -310,12 +533,13 @@ func checkTrailingPeriodConflictInWindows(
 
 // checkFileNameLengthConflict reports if the file renaming has resulted in a
 // name that is longer than the acceptable limit (255 characters in Windows and
-// 255 bytes on Unix). This conflict is automatically fixed by removing the
-// excess characters/bytes until the name is under the limit.
+// 255 bytes or the filesystem's limit on Unix). This conflict is automatically
+// fixed by removing the excess characters/bytes until the name is under the
+// limit.
 func checkFileNameLengthConflict(
 	ctx validationCtx,
 ) (conflictDetected bool) {
-	exceeded := isTargetLengthExceeded(ctx.change.Target)
+	exceeded := isTargetLengthExceeded(ctx.change)
 	if exceeded {
 		conflictDetected = true
 		ctx.change.Status = status.FilenameLengthExceeded
@@ -324,38 +548,139 @@ func checkFileNameLengthConflict(
 			return
 		}
 
-		if runtime.GOOS == osutil.Windows {
-			// trim filename so that it's less than 255 characters
-			filename := []rune(filepath.Base(ctx.change.Target))
-			ext := []rune(filepath.Ext(string(filename)))
-			f := []rune(
-				pathutil.StripExtension(string(filename)),
-			)
-			index := windowsMaxFileCharLength - len(ext)
-			f = f[:index]
-			ctx.change.AutoFixTarget(string(f) + string(ext))
+		maxLength, length := maxNameLength(ctx.change.TargetPath)
 
-			return
-		}
+		filename := truncateName(
+			filepath.Base(ctx.change.Target),
+			ctx.change.IsDir,
+			maxLength,
+			length,
+		)
 
-		// trim filename so that it's no more than 255 bytes
-		filename := filepath.Base(ctx.change.Target)
-		ext := filepath.Ext(filename)
-		fileNoExt := pathutil.StripExtension(filename)
-		index := unixMaxBytes - len([]byte(ext))
+		ctx.change.AutoFixTarget(
+			filepath.Join(filepath.Dir(ctx.change.Target), filename),
+		)
+	}
 
-		for {
-			if len([]byte(fileNoExt)) > index {
-				frune := []rune(fileNoExt)
-				fileNoExt = string(frune[:len(frune)-1])
+	return
+}
 
-				continue
-			}
+// checkPathLengthConflict reports if the file renaming has resulted in a full
+// path that is longer than the limit of the target OS (260 characters in
+// Windows unless --long-paths is used, 1024 bytes on macOS, and 4096 bytes on
+// Linux). This conflict is automatically fixed by truncating the file name,
+// or by skipping the file if the name cannot be shortened enough.
+func checkPathLengthConflict(
+	ctx validationCtx,
+) (conflictDetected bool) {
+	excess := pathLengthExcess(ctx.change)
+	if excess <= 0 {
+		return
+	}
+
+	conflictDetected = true
+	ctx.change.Status = status.PathLengthExceeded
 
-			break
+	if !ctx.autoFix {
+		return
+	}
+
+	_, length := maxPathLength()
+
+	name := filepath.Base(ctx.change.Target)
+
+	maxLength := length(name) - excess
+	if maxLength < 1 {
+		ctx.change.Status = status.Ignored
+		return
+	}
+
+	filename := truncateName(name, ctx.change.IsDir, maxLength, length)
+
+	ctx.change.AutoFixTarget(
+		filepath.Join(filepath.Dir(ctx.change.Target), filename),
+	)
+
+	return
+}
+
+// isReservedName reports whether a path component uses a name that is reserved
+// for a device in Windows. Trailing spaces before the extension are ignored
+// as they are in Windows.
+func isReservedName(name string) bool {
+	stem, ext, _ := strings.Cut(name, ".")
+	if ext != "" {
+		ext = "." + ext
+	}
+
+	return windowsReservedNameRegex.MatchString(
+		strings.TrimRight(stem, " ") + ext,
+	)
+}
+
+// checkReservedNameConflict reports if the file renaming has resulted in files
+// or sub directories that use a reserved device name in Windows (such as CON
+// or NUL). This conflict is automatically fixed by appending a number to the
+// reserved name according to the conflict pattern.
+func checkReservedNameConflict(
+	ctx validationCtx,
+) (conflictDetected bool) {
+	if targetOS() != osutil.Windows {
+		return
+	}
+
+	pathComponents := strings.Split(filepath.ToSlash(ctx.change.Target), "/")
+
+	for j, v := range pathComponents {
+		if !isReservedName(v) {
+			continue
 		}
 
-		ctx.change.AutoFixTarget(fileNoExt + ext)
+		conflictDetected = true
+
+		stem, ext, found := strings.Cut(v, ".")
+		if found {
+			ext = "." + ext
+		}
+
+		pathComponents[j] = strings.TrimRight(stem, " ") +
+			fmt.Sprintf(config.Get().FixConflictsPattern, 1) + ext
+	}
+
+	if !conflictDetected {
+		return
+	}
+
+	ctx.change.Status = status.ReservedName
+
+	if ctx.autoFix {
+		ctx.change.AutoFixTarget(strings.Join(pathComponents, "/"))
+	}
+
+	return
+}
+
+// checkPermissionConflict reports if the current user is unlikely to be able
+// to rename the file, such as when its directory is not writable or it is
+// read-only or locked in Windows. This conflict is automatically fixed by
+// skipping the file.
+func checkPermissionConflict(
+	ctx validationCtx,
+) (conflictDetected bool) {
+	if ctx.change.SourcePath == ctx.change.TargetPath ||
+		ctx.change.Status == status.SourceNotFound {
+		return
+	}
+
+	if canRename(ctx.change) {
+		return
+	}
+
+	conflictDetected = true
+	ctx.change.Status = status.PermissionDenied
+
+	if ctx.autoFix {
+		ctx.change.Status = status.Ignored
 	}
 
 	return
@@ -379,22 +704,10 @@ func checkForbiddenCharactersConflict(
 			return
 		}
 
-		newTarget := ctx.change.Target
-
-		if runtime.GOOS == osutil.Windows {
-			newTarget = osutil.PartialWindowsForbiddenCharRegex.ReplaceAllString(
-				ctx.change.Target,
-				"",
-			)
-		}
-
-		if runtime.GOOS == osutil.Darwin {
-			newTarget = strings.ReplaceAll(
-				ctx.change.Target,
-				":",
-				"",
-			)
-		}
+		newTarget := forbiddenCharRegex(targetOS()).ReplaceAllString(
+			ctx.change.Target,
+			"",
+		)
 
 		ctx.change.AutoFixTarget(newTarget)
 	}
@@ -408,13 +721,26 @@ func checkAndHandleConflict(ctx validationCtx, loopIndex *int) (detected bool) {
 		checkEmptyFilenameConflict,
 		checkTrailingPeriodConflictInWindows,
 		checkFileNameLengthConflict,
+		checkPathLengthConflict,
 		checkForbiddenCharactersConflict,
+		checkReservedNameConflict,
+		checkPermissionConflict,
 		checkPathExistsConflict,
 		checkOverwritingPathConflict,
 		checkSourceNotFoundConflict,
 		checkTargetFileChangingConflict, // INFO: Needs to be the last check
 	}
 
+	// Skipped changes are not renamed so they cannot conflict
+	if ctx.change.Status == status.Ignored {
+		return false
+	}
+
+	// Copies that are deleted when undoing a copy only need to exist
+	if ctx.change.Remove {
+		checks = []func(ctx validationCtx) bool{checkSourceNotFoundConflict}
+	}
+
 	for i, check := range checks {
 		detected = check(ctx)
 		if !detected {
@@ -449,6 +775,7 @@ func detectConflicts(autoFix, allowOverwrites bool) bool {
 		autoFix:         autoFix,
 		allowOverwrites: allowOverwrites,
 		seenPaths:       make(map[string]int),
+		emptyCount:      new(int),
 	}
 
 	conflicts := make(map[int]string)
@@ -462,6 +789,7 @@ func detectConflicts(autoFix, allowOverwrites bool) bool {
 		detected := checkAndHandleConflict(ctx, &i)
 		if detected {
 			conflicts[ctx.changeIndex] = change.SourcePath
+
 			continue
 		}
 
@@ -481,5 +809,37 @@ func Validate(
 ) bool {
 	changes = matches
 
-	return detectConflicts(autoFix, allowOverwrites)
+	for {
+		if detectConflicts(autoFix, allowOverwrites) {
+			return true
+		}
+
+		collisions := simulate(changes)
+		if len(collisions) == 0 {
+			return false
+		}
+
+		for _, c := range collisions {
+			change := changes[c.index]
+			change.Status = status.PathExists
+
+			if !autoFix {
+				continue
+			}
+
+			// A target under a file cannot be fixed by renaming it
+			if c.blocked {
+				change.AutoFixTarget(change.Source)
+				change.Status = status.Ignored
+
+				continue
+			}
+
+			change.AutoFixTarget(newTarget(change))
+		}
+
+		if !autoFix {
+			return true
+		}
+	}
 }
@@ -0,0 +1,35 @@
+//go:build linux
+// +build linux
+
+package validate
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// fsMaxNameBytes returns the maximum length of a file name in bytes on the
+// filesystem that holds the provided path. Since the path may not exist yet,
+// the limit is taken from the nearest existing parent directory. It reports
+// false if the limit cannot be determined.
+func fsMaxNameBytes(path string) (int, bool) {
+	dir := filepath.Dir(path)
+
+	for {
+		var stat syscall.Statfs_t
+
+		err := syscall.Statfs(dir, &stat)
+		if err == nil {
+			return int(stat.Namelen), stat.Namelen > 0
+		}
+
+		parent := filepath.Dir(dir)
+		if !errors.Is(err, os.ErrNotExist) || parent == dir {
+			return 0, false
+		}
+
+		dir = parent
+	}
+}
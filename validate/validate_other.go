@@ -0,0 +1,10 @@
+//go:build !linux
+// +build !linux
+
+package validate
+
+// fsMaxNameBytes reports false on this platform since the file name limit of
+// the filesystem is not readily available. The default limits apply instead.
+func fsMaxNameBytes(_ string) (int, bool) {
+	return 0, false
+}
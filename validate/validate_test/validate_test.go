@@ -1,6 +1,9 @@
 package validate_test
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/jinzhu/copier"
@@ -101,22 +104,23 @@ func TestValidate(t *testing.T) {
 			ConflictDetected: true,
 		},
 		{
-			Name: "report conflict when target path exists but changes AFTER the overwriting file is renamed",
+			Name: "don't report conflict if target file exists but changes AFTER the overwriting file is renamed",
 			Changes: file.Changes{
 				{
 					Source:  "dsc-001.arw",
 					Target:  "dsc-002.arw",
-					Status:  status.PathExists,
 					BaseDir: "testdata/images",
 				},
 				{
 					Source:  "dsc-002.arw",
 					Target:  "dsc-003.arw",
-					Status:  status.TargetFileChanging,
 					BaseDir: "testdata/images",
 				},
 			},
-			ConflictDetected: true,
+			Want: []string{
+				"testdata/images/dsc-002.arw",
+				"testdata/images/dsc-003.arw",
+			},
 		},
 		{
 			Name: "don't report conflict if target file exists but changes BEFORE the overwriting file is renamed",
@@ -150,6 +154,45 @@ func TestValidate(t *testing.T) {
 			Want: []string{"ebooks/1984.pdf"},
 			Args: autoFixArgs,
 		},
+		{
+			Name: "auto fix empty filename conflict by skipping the file",
+			Changes: file.Changes{
+				{
+					Source:  "1984.pdf",
+					Target:  "",
+					BaseDir: "ebooks",
+				},
+			},
+			Want: []string{"ebooks/1984.pdf"},
+			Args: []string{"-r", "", "-F", "--fix-empty", "skip"},
+		},
+		{
+			Name: "auto fix empty filename conflict with a template",
+			Changes: file.Changes{
+				{
+					Source:  "1984.pdf",
+					Target:  "",
+					BaseDir: "ebooks",
+				},
+				{
+					Source:  "animal-farm.epub",
+					Target:  "",
+					BaseDir: "ebooks",
+				},
+				{
+					Source:  "classics",
+					Target:  "",
+					BaseDir: "ebooks",
+					IsDir:   true,
+				},
+			},
+			Want: []string{
+				"ebooks/unnamed_001.pdf",
+				"ebooks/unnamed_002.epub",
+				"ebooks/unnamed_003",
+			},
+			Args: []string{"-r", "", "-F", "--fix-empty", "unnamed_%03d"},
+		},
 		{
 			Name: "auto fix path exists conflict",
 			Changes: file.Changes{
@@ -164,6 +207,32 @@ func TestValidate(t *testing.T) {
 			},
 			Args: autoFixArgs,
 		},
+		{
+			Name: "auto fix path exists conflict without the numbers skipped by --number-skip",
+			Changes: file.Changes{
+				{
+					Source:  "dsc-001.arw",
+					Target:  "dsc-002.arw",
+					BaseDir: "testdata/images",
+				},
+				{
+					Source:  "dsc-003.arw",
+					Target:  "dsc-004(3).arw",
+					BaseDir: "testdata/images",
+				},
+				{
+					Source:  "dsc-004.arw",
+					Target:  "dsc-004(3).arw",
+					BaseDir: "testdata/images",
+				},
+			},
+			Want: []string{
+				"testdata/images/dsc-002(3).arw",
+				"testdata/images/dsc-004(3).arw",
+				"testdata/images/dsc-004(5).arw",
+			},
+			Args: append(autoFixArgs, "--number-skip", "1-2;4"),
+		},
 		{
 			Name: "auto fix overwriting several files conflict",
 			Changes: file.Changes{
@@ -233,15 +302,15 @@ func TestValidate(t *testing.T) {
 			},
 			Want: []string{
 				"ebooks/myFile.pdf",
-				"ebooks/myFile_02.pdf",
+				"ebooks/myFile_01.pdf",
 				"ebooks/hisFile.pdf",
 				"ebooks/hisFile_01.pdf",
-				"ebooks/myFile_01.pdf",
+				"ebooks/myFile_02.pdf",
 			},
 			Args: append(autoFixArgs, "--fix-conflicts-pattern", "_%02d"),
 		},
 		{
-			Name: "detect if target file is changing later",
+			Name: "allow a shift rename where each target changes later",
 			Changes: file.Changes{
 				{
 					Source: "03.txt",
@@ -250,18 +319,31 @@ func TestValidate(t *testing.T) {
 				{
 					Source: "02.txt",
 					Target: "01.txt",
-					Status: status.TargetFileChanging,
 				},
 				{
 					Source: "01.txt",
 					Target: "00.txt",
+				},
+			},
+			Want: []string{"02.txt", "01.txt", "00.txt"},
+		},
+		{
+			Name: "detect a target that is the path of a file that is not renamed",
+			Changes: file.Changes{
+				{
+					Source: "03.txt",
+					Target: "02.txt",
+				},
+				{
+					Source: "02.txt",
+					Target: "02.txt",
 					Status: status.TargetFileChanging,
 				},
 			},
 			ConflictDetected: true,
 		},
 		{
-			Name: "auto fix target file changing later",
+			Name: "auto fix a target that is the path of a file that is not renamed",
 			Changes: file.Changes{
 				{
 					Source: "03.txt",
@@ -269,15 +351,577 @@ func TestValidate(t *testing.T) {
 				},
 				{
 					Source: "02.txt",
-					Target: "01.txt",
+					Target: "02.txt",
 				},
+			},
+			Want: []string{"02.txt", "02(1).txt"},
+			Args: autoFixArgs,
+		},
+		{
+			Name: "detect forbidden characters for the target OS",
+			Changes: file.Changes{
 				{
-					Source: "01.txt",
-					Target: "00.txt",
+					Source:  "atomic-habits.pdf",
+					Target:  "<>:?etc.pdf",
+					BaseDir: "ebooks",
+					Status:  status.ForbiddenCharacters,
 				},
 			},
-			Want: []string{"00.txt", "01.txt", "02.txt"},
-			Args: autoFixArgs,
+			ConflictDetected: true,
+			Args:             []string{"-r", "", "--target-os", "windows"},
+		},
+		{
+			Name: "auto fix forbidden characters for the target OS",
+			Changes: file.Changes{
+				{
+					Source:  "atomic-habits.pdf",
+					Target:  "<>:?etc.pdf",
+					BaseDir: "ebooks",
+				},
+			},
+			Want: []string{"ebooks/etc.pdf"},
+			Args: []string{"-r", "", "-F", "--target-os", "windows"},
+		},
+		{
+			Name: "auto fix trailing periods for the target OS",
+			Changes: file.Changes{
+				{
+					Source:  "index.js",
+					Target:  "main.js..",
+					BaseDir: "dev",
+				},
+			},
+			Want: []string{"dev/main.js"},
+			Args: []string{"-r", "", "-F", "--target-os", "windows"},
+		},
+		{
+			Name: "allow colons when targeting Linux",
+			Changes: file.Changes{
+				{
+					Source:  "meeting.txt",
+					Target:  "12:30.txt",
+					BaseDir: "notes",
+				},
+			},
+			Want: []string{"notes/12:30.txt"},
+			Args: []string{"-r", "", "--target-os", "linux"},
+		},
+		{
+			Name: "detect NUL characters in filename",
+			Changes: file.Changes{
+				{
+					Source:  "meeting.txt",
+					Target:  "12\x0030.txt",
+					BaseDir: "notes",
+					Status:  status.ForbiddenCharacters,
+				},
+			},
+			ConflictDetected: true,
+			Args:             []string{"-r", "", "--target-os", "linux"},
+		},
+		{
+			Name: "auto fix NUL characters in filename",
+			Changes: file.Changes{
+				{
+					Source:  "meeting.txt",
+					Target:  "12\x0030.txt",
+					BaseDir: "notes",
+				},
+			},
+			Want: []string{"notes/1230.txt"},
+			Args: []string{"-r", "", "-F", "--target-os", "linux"},
+		},
+		{
+			Name: "auto fix filename length while preserving the counter suffix",
+			Changes: file.Changes{
+				{
+					Source:  "1984.pdf",
+					Target:  strings.Repeat("a", 300) + "(2).pdf",
+					BaseDir: "ebooks",
+				},
+			},
+			Want: []string{
+				"ebooks/" + strings.Repeat("a", 248) + "(2).pdf",
+			},
+			Args: []string{"-r", "", "-F", "--target-os", "linux"},
+		},
+		{
+			Name: "auto fix filename length in a new directory",
+			Changes: file.Changes{
+				{
+					Source:  "1984.pdf",
+					Target:  "orwell/" + strings.Repeat("b", 300) + ".pdf",
+					BaseDir: "ebooks",
+				},
+			},
+			Want: []string{
+				"ebooks/orwell/" + strings.Repeat("b", 251) + ".pdf",
+			},
+			Args: []string{"-r", "", "-F", "--target-os", "linux"},
+		},
+		{
+			Name: "auto fix filename length with a custom conflict pattern",
+			Changes: file.Changes{
+				{
+					Source:  "1984.pdf",
+					Target:  strings.Repeat("c", 300) + "_03.pdf",
+					BaseDir: "ebooks",
+				},
+			},
+			Want: []string{
+				"ebooks/" + strings.Repeat("c", 248) + "_03.pdf",
+			},
+			Args: []string{
+				"-r",
+				"",
+				"-F",
+				"--fix-conflicts-pattern",
+				"_%02d",
+				"--target-os",
+				"windows",
+				"--long-paths",
+			},
+		},
+		{
+			Name: "detect reserved names for the target OS",
+			Changes: file.Changes{
+				{
+					Source:  "console.txt",
+					Target:  "CON.txt",
+					BaseDir: "logs",
+					Status:  status.ReservedName,
+				},
+				{
+					Source:  "notes.txt",
+					Target:  "nul/notes.txt",
+					BaseDir: "logs",
+					Status:  status.ReservedName,
+				},
+			},
+			ConflictDetected: true,
+			Args:             []string{"-r", "", "--target-os", "windows"},
+		},
+		{
+			Name: "auto fix reserved names for the target OS",
+			Changes: file.Changes{
+				{
+					Source:  "console.txt",
+					Target:  "CON.txt",
+					BaseDir: "logs",
+				},
+				{
+					Source:  "notes.txt",
+					Target:  "nul/notes.txt",
+					BaseDir: "logs",
+				},
+				{
+					Source:  "printer.tar.gz",
+					Target:  "prn.tar.gz",
+					BaseDir: "logs",
+				},
+			},
+			Want: []string{
+				"logs/CON(1).txt",
+				"logs/nul(1)/notes.txt",
+				"logs/prn(1).tar.gz",
+			},
+			Args: []string{"-r", "", "-F", "--target-os", "windows"},
+		},
+		{
+			Name: "allow names that only start with a reserved name",
+			Changes: file.Changes{
+				{
+					Source:  "console.txt",
+					Target:  "CONSOLE.txt",
+					BaseDir: "logs",
+				},
+				{
+					Source:  "com.txt",
+					Target:  "COM10.txt",
+					BaseDir: "logs",
+				},
+			},
+			Want: []string{"logs/CONSOLE.txt", "logs/COM10.txt"},
+			Args: []string{"-r", "", "--target-os", "windows"},
+		},
+		{
+			Name: "allow reserved names when targeting Linux",
+			Changes: file.Changes{
+				{
+					Source:  "console.txt",
+					Target:  "CON.txt",
+					BaseDir: "logs",
+				},
+			},
+			Want: []string{"logs/CON.txt"},
+			Args: []string{"-r", "", "--target-os", "linux"},
+		},
+		{
+			Name: "detect trailing spaces for the target OS",
+			Changes: file.Changes{
+				{
+					Source:  "index.js",
+					Target:  "main.js ",
+					BaseDir: "dev",
+					Status:  status.TrailingPeriod,
+				},
+				{
+					Source:  "app.js",
+					Target:  "src /app.js",
+					BaseDir: "dev",
+					Status:  status.TrailingPeriod,
+				},
+			},
+			ConflictDetected: true,
+			Args:             []string{"-r", "", "--target-os", "windows"},
+		},
+		{
+			Name: "auto fix trailing periods and spaces for the target OS",
+			Changes: file.Changes{
+				{
+					Source:  "index.js",
+					Target:  "main.js. .",
+					BaseDir: "dev",
+				},
+				{
+					Source:  "app.js",
+					Target:  "src. /app.js",
+					BaseDir: "dev",
+				},
+				{
+					Source:  "util.js",
+					Target:  "../util.js",
+					BaseDir: "dev",
+				},
+			},
+			Want: []string{"dev/main.js", "dev/src/app.js", "util.js"},
+			Args: []string{"-r", "", "-F", "--target-os", "windows"},
+		},
+		{
+			Name: "detect targets that differ only in case",
+			Changes: file.Changes{
+				{
+					Source:  "IMG_001.jpg",
+					Target:  "Photo.jpg",
+					BaseDir: "photos",
+				},
+				{
+					Source:  "IMG_002.jpg",
+					Target:  "photo.jpg",
+					BaseDir: "photos",
+					Status:  status.OverwritingNewPath,
+				},
+			},
+			ConflictDetected: true,
+			Args: []string{
+				"-r",
+				"",
+				"--target-os",
+				"linux",
+				"--ci-fs",
+			},
+		},
+		{
+			Name: "auto fix targets that differ only in case",
+			Changes: file.Changes{
+				{
+					Source:  "IMG_001.jpg",
+					Target:  "Photo.jpg",
+					BaseDir: "photos",
+				},
+				{
+					Source:  "IMG_002.jpg",
+					Target:  "photo.jpg",
+					BaseDir: "photos",
+				},
+			},
+			Want: []string{"photos/Photo.jpg", "photos/photo(1).jpg"},
+			Args: []string{"-r", "", "-F", "--target-os", "windows"},
+		},
+		{
+			Name: "allow targets that differ only in case on Linux",
+			Changes: file.Changes{
+				{
+					Source:  "IMG_001.jpg",
+					Target:  "Photo.jpg",
+					BaseDir: "photos",
+				},
+				{
+					Source:  "IMG_002.jpg",
+					Target:  "photo.jpg",
+					BaseDir: "photos",
+				},
+			},
+			Want: []string{"photos/Photo.jpg", "photos/photo.jpg"},
+			Args: []string{"-r", "", "--target-os", "linux"},
+		},
+		{
+			Name: "don't report conflict when swapping file names",
+			Changes: file.Changes{
+				{
+					Source:  "dsc-001.arw",
+					Target:  "dsc-002.arw",
+					BaseDir: "testdata/images",
+				},
+				{
+					Source:  "dsc-002.arw",
+					Target:  "dsc-001.arw",
+					BaseDir: "testdata/images",
+				},
+			},
+			Want: []string{
+				"testdata/images/dsc-002.arw",
+				"testdata/images/dsc-001.arw",
+			},
+		},
+		{
+			Name: "report conflict when a renamed path overwrites a rename cycle",
+			Changes: file.Changes{
+				{
+					Source:  "dsc-001.arw",
+					Target:  "dsc-002.arw",
+					BaseDir: "testdata/images",
+				},
+				{
+					Source:  "dsc-002.arw",
+					Target:  "dsc-001.arw",
+					BaseDir: "testdata/images",
+				},
+				{
+					Source:  "dsc-003.arw",
+					Target:  "dsc-001.arw",
+					Status:  status.OverwritingNewPath,
+					BaseDir: "testdata/images",
+				},
+			},
+			ConflictDetected: true,
+		},
+		{
+			Name: "auto fix overwriting files conflict with a conflict suffix",
+			Changes: file.Changes{
+				{
+					Source:  "1984.pdf",
+					Target:  "report.pdf",
+					BaseDir: "ebooks",
+				},
+				{
+					Source:  "animal-farm.pdf",
+					Target:  "report.pdf",
+					BaseDir: "ebooks",
+				},
+				{
+					Source:  "fear-of-life.pdf",
+					Target:  "report.pdf",
+					BaseDir: "ebooks",
+				},
+			},
+			Want: []string{
+				"ebooks/report.pdf",
+				"ebooks/report (copy 1).pdf",
+				"ebooks/report (copy 2).pdf",
+			},
+			Args: []string{"-r", "", "-F", "--conflict-suffix", " (copy %d)"},
+		},
+		{
+			Name: "detect targets that are identical after normalization",
+			Changes: file.Changes{
+				{
+					Source:  "menu1.pdf",
+					Target:  "caf\u00e9.pdf",
+					BaseDir: "docs",
+				},
+				{
+					Source:  "menu2.pdf",
+					Target:  "cafe\u0301.pdf",
+					BaseDir: "docs",
+					Status:  status.OverwritingNewPath,
+				},
+			},
+			ConflictDetected: true,
+			Args:             []string{"-r", "", "--target-os", "linux"},
+		},
+	}
+
+	validateTest(t, testCases)
+}
+
+// createRenamePlanFiles creates the files and directories that are renamed in
+// the rename plan tests.
+func createRenamePlanFiles(t *testing.T, _ string) func() {
+	t.Helper()
+
+	err := os.MkdirAll(filepath.Join("plan", "photos"), 0o755)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"a.txt", "b.txt", "photos/img.jpg"} {
+		err = os.WriteFile(filepath.Join("plan", name), nil, 0o600)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return func() {
+		_ = os.RemoveAll("plan")
+	}
+}
+
+func TestValidateRenamePlan(t *testing.T) {
+	testCases := []testutil.TestCase{
+		{
+			Name: "detect a target under a path that is renamed to a file",
+			Changes: file.Changes{
+				{
+					Source:  "a.txt",
+					Target:  "out",
+					BaseDir: "plan",
+				},
+				{
+					Source:  "b.txt",
+					Target:  "out/b.txt",
+					BaseDir: "plan",
+					Status:  status.PathExists,
+				},
+			},
+			ConflictDetected: true,
+			SetupFunc:        createRenamePlanFiles,
+		},
+		{
+			Name: "auto fix a target under a file by skipping it",
+			Changes: file.Changes{
+				{
+					Source:  "a.txt",
+					Target:  "out",
+					BaseDir: "plan",
+				},
+				{
+					Source:  "b.txt",
+					Target:  "out/b.txt",
+					BaseDir: "plan",
+				},
+			},
+			Want:      []string{"plan/out", "plan/b.txt"},
+			Args:      autoFixArgs,
+			SetupFunc: createRenamePlanFiles,
+		},
+		{
+			Name: "detect a target in a directory that is moved earlier",
+			Changes: file.Changes{
+				{
+					Source:  "photos",
+					Target:  "pictures",
+					BaseDir: "plan",
+					IsDir:   true,
+				},
+				{
+					Source:  "a.txt",
+					Target:  "pictures/img.jpg",
+					BaseDir: "plan",
+					Status:  status.PathExists,
+				},
+			},
+			ConflictDetected: true,
+			SetupFunc:        createRenamePlanFiles,
+		},
+		{
+			Name: "auto fix a target in a directory that is moved earlier",
+			Changes: file.Changes{
+				{
+					Source:  "photos",
+					Target:  "pictures",
+					BaseDir: "plan",
+					IsDir:   true,
+				},
+				{
+					Source:  "a.txt",
+					Target:  "pictures/img.jpg",
+					BaseDir: "plan",
+				},
+			},
+			Want:      []string{"plan/pictures", "plan/pictures/img(1).jpg"},
+			Args:      autoFixArgs,
+			SetupFunc: createRenamePlanFiles,
+		},
+		{
+			Name: "allow a target that is freed earlier in the plan",
+			Changes: file.Changes{
+				{
+					Source:  "b.txt",
+					Target:  "c.txt",
+					BaseDir: "plan",
+				},
+				{
+					Source:  "a.txt",
+					Target:  "b.txt",
+					BaseDir: "plan",
+				},
+			},
+			Want:      []string{"plan/c.txt", "plan/b.txt"},
+			SetupFunc: createRenamePlanFiles,
+		},
+	}
+
+	validateTest(t, testCases)
+}
+
+func TestValidateCopy(t *testing.T) {
+	testCases := []testutil.TestCase{
+		{
+			Name: "detect a target whose file is copied instead of renamed",
+			Changes: file.Changes{
+				{
+					Source:  "b.txt",
+					Target:  "c.txt",
+					BaseDir: "plan",
+				},
+				{
+					Source:  "a.txt",
+					Target:  "b.txt",
+					BaseDir: "plan",
+					Status:  status.PathExists,
+				},
+			},
+			ConflictDetected: true,
+			Args:             []string{"-r", "", "--copy"},
+			SetupFunc:        createRenamePlanFiles,
+		},
+		{
+			Name: "detect copies to the same target",
+			Changes: file.Changes{
+				{
+					Source:  "a.txt",
+					Target:  "c.txt",
+					BaseDir: "plan",
+				},
+				{
+					Source:  "b.txt",
+					Target:  "c.txt",
+					BaseDir: "plan",
+					Status:  status.OverwritingNewPath,
+				},
+			},
+			ConflictDetected: true,
+			Args:             []string{"-r", "", "--copy"},
+			SetupFunc:        createRenamePlanFiles,
+		},
+		{
+			Name: "auto fix a target whose file is copied instead of renamed",
+			Changes: file.Changes{
+				{
+					Source:  "b.txt",
+					Target:  "c.txt",
+					BaseDir: "plan",
+				},
+				{
+					Source:  "a.txt",
+					Target:  "b.txt",
+					BaseDir: "plan",
+				},
+			},
+			Want:      []string{"plan/c.txt", "plan/b(1).txt"},
+			Args:      []string{"-r", "", "--copy", "-F"},
+			SetupFunc: createRenamePlanFiles,
 		},
 	}
 
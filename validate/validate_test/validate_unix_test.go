@@ -4,6 +4,9 @@
 package validate_test
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/ayoisaiah/f2/v2/internal/file"
@@ -11,6 +14,10 @@ import (
 	"github.com/ayoisaiah/f2/v2/internal/testutil"
 )
 
+// longDir is a directory whose path leaves less than 260 characters for the
+// file name when it is placed under /tmp/ebooks.
+var longDir = strings.Repeat("a", 150)
+
 func TestValidateUnix(t *testing.T) {
 	t.Helper()
 
@@ -41,6 +48,183 @@ func TestValidateUnix(t *testing.T) {
 			},
 			Args: []string{"-r", "", "-F"},
 		},
+		{
+			Name: "detect path longer than 260 characters in Windows",
+			Changes: file.Changes{
+				{
+					Source:  "1984.pdf",
+					Target:  longDir + "/" + strings.Repeat("b", 150) + ".pdf",
+					BaseDir: "/tmp/ebooks",
+					Status:  status.PathLengthExceeded,
+				},
+			},
+			ConflictDetected: true,
+			Args:             []string{"-r", "", "--target-os", "windows"},
+		},
+		{
+			Name: "auto fix path length conflict in Windows",
+			Changes: file.Changes{
+				{
+					Source:  "1984.pdf",
+					Target:  longDir + "/" + strings.Repeat("b", 150) + ".pdf",
+					BaseDir: "/tmp/ebooks",
+				},
+			},
+			Want: []string{
+				"/tmp/ebooks/" + longDir + "/" + strings.Repeat("b", 92) + ".pdf",
+			},
+			Args: []string{"-r", "", "-F", "--target-os", "windows"},
+		},
+		{
+			Name: "allow long paths in Windows with --long-paths",
+			Changes: file.Changes{
+				{
+					Source:  "1984.pdf",
+					Target:  longDir + "/" + strings.Repeat("b", 150) + ".pdf",
+					BaseDir: "/tmp/ebooks",
+					Status:  status.OK,
+				},
+			},
+			Want: []string{
+				"/tmp/ebooks/" + longDir + "/" + strings.Repeat("b", 150) + ".pdf",
+			},
+			Args: []string{
+				"-r",
+				"",
+				"--target-os",
+				"windows",
+				"--long-paths",
+			},
+		},
+	}
+
+	validateTest(t, testCases)
+}
+
+// createReadOnlyDir creates a directory containing a file that the current
+// user cannot rename.
+func createReadOnlyDir(t *testing.T, _ string) func() {
+	t.Helper()
+
+	err := os.MkdirAll("readonly", 0o755)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = os.WriteFile(filepath.Join("readonly", "report.pdf"), nil, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = os.Chmod("readonly", 0o555)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return func() {
+		_ = os.Chmod("readonly", 0o755)
+		_ = os.RemoveAll("readonly")
+	}
+}
+
+func TestValidatePermissions(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("permissions are not enforced for the root user")
+	}
+
+	testCases := []testutil.TestCase{
+		{
+			Name: "detect a source in a directory that is not writable",
+			Changes: file.Changes{
+				{
+					Source:  "report.pdf",
+					Target:  "summary.pdf",
+					BaseDir: "readonly",
+					Status:  status.PermissionDenied,
+				},
+			},
+			ConflictDetected: true,
+			SetupFunc:        createReadOnlyDir,
+		},
+		{
+			Name: "auto fix permission conflict by skipping the file",
+			Changes: file.Changes{
+				{
+					Source:  "report.pdf",
+					Target:  "summary.pdf",
+					BaseDir: "readonly",
+				},
+			},
+			Want:      []string{"readonly/summary.pdf"},
+			Args:      []string{"-r", "", "-F"},
+			SetupFunc: createReadOnlyDir,
+		},
+	}
+
+	validateTest(t, testCases)
+}
+
+// createCaseFiles creates two files whose names differ only in case. The test
+// is skipped on case-insensitive filesystems where they are the same file.
+func createCaseFiles(t *testing.T, _ string) func() {
+	t.Helper()
+
+	err := os.MkdirAll("case", 0o755)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"notes.txt", "Notes.txt"} {
+		err = os.WriteFile(filepath.Join("case", name), []byte(name), 0o600)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := os.ReadFile(filepath.Join("case", "notes.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(got) != "notes.txt" {
+		_ = os.RemoveAll("case")
+		t.Skip("the filesystem is case-insensitive")
+	}
+
+	return func() {
+		_ = os.RemoveAll("case")
+	}
+}
+
+func TestValidateCaseChange(t *testing.T) {
+	testCases := []testutil.TestCase{
+		{
+			Name: "detect a case-only target that is another file",
+			Changes: file.Changes{
+				{
+					Source:  "notes.txt",
+					Target:  "Notes.txt",
+					BaseDir: "case",
+					Status:  status.PathExists,
+				},
+			},
+			ConflictDetected: true,
+			Args:             []string{"-r", "", "--target-os", "linux"},
+			SetupFunc:        createCaseFiles,
+		},
+		{
+			Name: "auto fix a case-only target that is another file",
+			Changes: file.Changes{
+				{
+					Source:  "notes.txt",
+					Target:  "Notes.txt",
+					BaseDir: "case",
+				},
+			},
+			Want:      []string{"case/Notes(1).txt"},
+			Args:      []string{"-r", "", "-F", "--target-os", "linux"},
+			SetupFunc: createCaseFiles,
+		},
 	}
 
 	validateTest(t, testCases)
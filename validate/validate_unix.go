@@ -0,0 +1,78 @@
+//go:build !windows
+// +build !windows
+
+package validate
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	"github.com/ayoisaiah/f2/v2/internal/file"
+)
+
+const (
+	// writeOK is the mode used to check for write permission with access(2).
+	writeOK = 0x2
+	// searchOK is the mode used to check for search permission on a directory
+	// with access(2).
+	searchOK = 0x1
+)
+
+// isPermissionErr reports whether the error is caused by missing permissions
+// or a read-only filesystem.
+func isPermissionErr(err error) bool {
+	return errors.Is(err, os.ErrPermission) || errors.Is(err, syscall.EROFS)
+}
+
+// isDirWritable reports whether the current user can create and remove
+// entries in the directory. Since the directory may not exist yet, the
+// permission is taken from the nearest existing parent directory. It reports
+// true if the permission cannot be determined.
+func isDirWritable(dir string) bool {
+	for {
+		err := syscall.Access(dir, writeOK|searchOK)
+		if err == nil {
+			return true
+		}
+
+		if isPermissionErr(err) {
+			return false
+		}
+
+		parent := filepath.Dir(dir)
+		if !errors.Is(err, os.ErrNotExist) || parent == dir {
+			return true
+		}
+
+		dir = parent
+	}
+}
+
+// canRename reports whether the current user has permission to rename the
+// source of the change to its target. This requires write permission on the
+// source and target directories, and on the source itself when a directory
+// is moved to another parent since its '..' entry is updated.
+func canRename(change *file.Change) bool {
+	sourceDir := filepath.Dir(change.SourcePath)
+	targetDir := filepath.Dir(change.TargetPath)
+
+	if !isDirWritable(sourceDir) {
+		return false
+	}
+
+	if targetDir == sourceDir {
+		return true
+	}
+
+	if !isDirWritable(targetDir) {
+		return false
+	}
+
+	if change.IsDir {
+		return !isPermissionErr(syscall.Access(change.SourcePath, writeOK))
+	}
+
+	return true
+}
@@ -0,0 +1,59 @@
+//go:build windows
+// +build windows
+
+package validate
+
+import (
+	"errors"
+	"os"
+	"syscall"
+
+	"github.com/ayoisaiah/f2/v2/internal/file"
+)
+
+const (
+	// deleteAccess is the access right that is required to rename a file.
+	deleteAccess = 0x00010000
+	// errSharingViolation is returned when a file is opened by another process
+	// that does not allow it to be renamed.
+	errSharingViolation syscall.Errno = 32
+)
+
+// canRename reports whether the source of the change can be renamed. Files
+// with the read-only attribute cannot be renamed, and neither can files that
+// are locked by another process or that the current user has no permission to
+// delete.
+func canRename(change *file.Change) bool {
+	info, err := os.Lstat(change.SourcePath)
+	if err != nil {
+		return true // missing sources are reported separately
+	}
+
+	// The read-only attribute is ignored for directories
+	if !info.IsDir() && info.Mode().Perm()&0o200 == 0 {
+		return false
+	}
+
+	path, err := syscall.UTF16PtrFromString(change.SourcePath)
+	if err != nil {
+		return true
+	}
+
+	handle, err := syscall.CreateFile(
+		path,
+		deleteAccess,
+		syscall.FILE_SHARE_READ|syscall.FILE_SHARE_WRITE|syscall.FILE_SHARE_DELETE,
+		nil,
+		syscall.OPEN_EXISTING,
+		syscall.FILE_FLAG_BACKUP_SEMANTICS|syscall.FILE_FLAG_OPEN_REPARSE_POINT,
+		0,
+	)
+	if err != nil {
+		return !errors.Is(err, errSharingViolation) &&
+			!errors.Is(err, os.ErrPermission)
+	}
+
+	_ = syscall.CloseHandle(handle)
+
+	return true
+}
@@ -0,0 +1,250 @@
+package f2
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/ayoisaiah/f2/v2/find"
+	"github.com/ayoisaiah/f2/v2/internal/config"
+	"github.com/ayoisaiah/f2/v2/internal/status"
+	"github.com/ayoisaiah/f2/v2/rename"
+	"github.com/ayoisaiah/f2/v2/replace"
+	"github.com/ayoisaiah/f2/v2/report"
+	"github.com/ayoisaiah/f2/v2/validate"
+)
+
+// watchDelay is how long the watched directories must be quiet before the
+// new files are renamed, which gives the programs that create them time to
+// finish writing.
+const watchDelay = 500 * time.Millisecond
+
+// watchDirs returns the directories to watch, which are the searched
+// directories along with the subdirectories that the search walks in
+// recursive mode.
+func watchDirs(conf *config.Config, roots []string) ([]string, error) {
+	for _, root := range roots {
+		info, err := os.Stat(root)
+		if err != nil {
+			return nil, err
+		}
+
+		if !info.IsDir() {
+			return nil, errWatchFile.Fmt(root)
+		}
+	}
+
+	return find.WatchDirs(conf, roots)
+}
+
+// renameNew renames the new files in the watched directories and returns the
+// paths that they were renamed to. Errors are reported so that watching can
+// continue.
+func renameNew(conf *config.Config, roots, paths []string) []string {
+	paths = slices.DeleteFunc(paths, func(path string) bool {
+		// The files may have been removed while waiting, such as the
+		// temporary files of a download
+		if _, err := os.Lstat(path); err != nil {
+			return true
+		}
+
+		// The files are passed to the search directly, so the ones that its
+		// walk of the watched directories would skip are left out here
+		walked, err := find.Walked(conf, roots, path)
+		if err != nil {
+			report.WatchFailed(err)
+			return true
+		}
+
+		return !walked
+	})
+
+	if len(paths) == 0 {
+		return nil
+	}
+
+	// The lock is only held while each batch is renamed so that other
+	// operations in the watched directories can run between them
+	if conf.Exec && !conf.NoLock {
+		unlock, err := config.Lock(roots)
+		if err != nil {
+			report.WatchFailed(err)
+			return nil
+		}
+
+		defer unlock()
+	}
+
+	conf.Renew(paths)
+
+	changes, err := find.Find(conf)
+	if err != nil {
+		report.WatchFailed(err)
+		return nil
+	}
+
+	if len(changes) == 0 {
+		return nil
+	}
+
+	changes, err = replace.Replace(conf, changes)
+	if err != nil {
+		report.WatchFailed(err)
+		return nil
+	}
+
+	hasConflicts := validate.Validate(
+		changes,
+		conf.AutoFixConflicts,
+		conf.AllowOverwrites,
+	)
+
+	if hasConflicts || !conf.Exec {
+		report.Report(conf, changes, hasConflicts)
+		report.Summary(conf, changes, time.Since(conf.Date))
+
+		return nil
+	}
+
+	err = rename.Rename(conf, changes)
+
+	rename.PostRename(conf, changes, err)
+
+	if conf.Verify {
+		_ = rename.Verify(conf, changes)
+	}
+
+	report.Summary(conf, changes, time.Since(conf.Date))
+
+	var targets []string
+
+	for _, ch := range changes {
+		if ch.Error == nil && ch.Status != status.Ignored {
+			targets = append(targets, filepath.Clean(ch.TargetPath))
+		}
+	}
+
+	return targets
+}
+
+// watch renames the files that appear in the searched directories until the
+// context is cancelled or the program is interrupted (--watch). The files are
+// renamed in groups once no new files have appeared for a short while.
+func watch(ctx context.Context, conf *config.Config) error {
+	// The searched paths are replaced by the new files in each batch
+	roots := slices.Clone(conf.FilesAndDirPaths)
+
+	dirs, err := watchDirs(conf, roots)
+	if err != nil {
+		return err
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+
+	defer watcher.Close()
+
+	for _, dir := range dirs {
+		err = watcher.Add(dir)
+		if err != nil {
+			return err
+		}
+	}
+
+	report.Watching(conf, dirs)
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	timer := time.NewTimer(watchDelay)
+	timer.Stop()
+
+	pending := make(map[string]bool)
+
+	// the paths that files were renamed to, which are not renamed again when
+	// they appear
+	renamed := make(map[string]bool)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+
+			report.WatchFailed(err)
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+
+			path := filepath.Clean(event.Name)
+
+			// Wait for files that are still being written to
+			if event.Has(fsnotify.Write) && pending[path] {
+				timer.Reset(watchDelay)
+				continue
+			}
+
+			if !event.Has(fsnotify.Create) {
+				continue
+			}
+
+			if renamed[path] {
+				delete(renamed, path)
+				continue
+			}
+
+			info, err := os.Lstat(path)
+			if err != nil {
+				continue
+			}
+
+			if info.IsDir() {
+				if !conf.Recursive {
+					continue
+				}
+
+				walked, err := find.Walked(conf, roots, path)
+				if err != nil {
+					report.WatchFailed(err)
+					continue
+				}
+
+				if walked {
+					err = watcher.Add(path)
+					if err != nil {
+						report.WatchFailed(err)
+					}
+				}
+
+				continue
+			}
+
+			pending[path] = true
+
+			timer.Reset(watchDelay)
+		case <-timer.C:
+			paths := make([]string, 0, len(pending))
+			for path := range pending {
+				paths = append(paths, path)
+			}
+
+			slices.Sort(paths)
+			clear(pending)
+
+			for _, target := range renameNew(conf, roots, paths) {
+				renamed[target] = true
+			}
+		}
+	}
+}